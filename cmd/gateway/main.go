@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/agarmirus/ds-lab02/internal/controllers"
 	"github.com/agarmirus/ds-lab02/internal/serverrors"
@@ -22,6 +24,14 @@ type gatewayConfigDataStruct struct {
 	ReservPort        int    `json:"reservPort"`
 	MaxRequestFails   int    `json:"maxRequestFails"`
 	MaxResetQueueSize int    `json:"maxResetQueueSize"`
+	QuoteSecret       string `json:"quoteSecret"`
+	QuoteTtlSeconds   int    `json:"quoteTtlSeconds"`
+	QuoteMaxDrift     int    `json:"quoteMaxDriftPercent"`
+	FeedSecret        string `json:"feedSecret"`
+
+	ReconcileIntervalMinutes int    `json:"reconcileIntervalMinutes"`
+	ReconcileAdminToken      string `json:"reconcileAdminToken"`
+	ReconcileApplyFixes      bool   `json:"reconcileApplyFixes"`
 }
 
 func readConfig(path string, configData *gatewayConfigDataStruct) (err error) {
@@ -42,7 +52,47 @@ func readConfig(path string, configData *gatewayConfigDataStruct) (err error) {
 	return json.Unmarshal(configJSON, configData)
 }
 
+// Secrets may be supplied through the environment so that the committed config
+// never holds a usable value; placeholders are rejected at startup.
+func readSecret(configValue string, envName string) (secret string, err error) {
+	secret = configValue
+
+	if envValue, ok := os.LookupEnv(envName); ok {
+		secret = envValue
+	}
+
+	if secret == `` || strings.HasPrefix(secret, `change-me`) {
+		log.Println("[ERROR] Main. Insecure value for", envName)
+		return ``, serverrors.ErrInsecureSecret
+	}
+
+	return secret, nil
+}
+
 func buildService(configData *gatewayConfigDataStruct) (controller controllers.IController, err error) {
+	quoteSecret, err := readSecret(configData.QuoteSecret, `GATEWAY_QUOTE_SECRET`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	feedSecret, err := readSecret(configData.FeedSecret, `GATEWAY_FEED_SECRET`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is only sent by the background reconciliation.
+	reconcileAdminToken := ``
+
+	if configData.ReconcileIntervalMinutes > 0 {
+		reconcileAdminToken, err = readSecret(configData.ReconcileAdminToken, `ADMIN_TOKEN`)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	service := services.NewGatewayService(
 		configData.ReservHost,
 		configData.ReservPort,
@@ -52,6 +102,13 @@ func buildService(configData *gatewayConfigDataStruct) (controller controllers.I
 		configData.LoyaltyPort,
 		configData.MaxRequestFails,
 		configData.MaxResetQueueSize,
+		quoteSecret,
+		time.Duration(configData.QuoteTtlSeconds)*time.Second,
+		configData.QuoteMaxDrift,
+		feedSecret,
+		time.Duration(configData.ReconcileIntervalMinutes)*time.Minute,
+		`Bearer `+reconcileAdminToken,
+		configData.ReconcileApplyFixes,
 	)
 
 	controller = controllers.NewGatewayController(
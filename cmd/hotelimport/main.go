@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/agarmirus/ds-lab02/internal/database"
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	formatCSV   = `csv`
+	formatJSONL = `jsonl`
+)
+
+var csvHeader = []string{`hotelUid`, `name`, `country`, `city`, `address`, `stars`, `price`, `currency`, `roomsCount`, `retired`}
+
+type importConfigDataStruct struct {
+	ConnStr string `json:"connDb"`
+}
+
+type hotelRecord struct {
+	line  int
+	hotel models.Hotel
+	err   error
+}
+
+func readConfig(path string, configData *importConfigDataStruct) (err error) {
+	configFile, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer configFile.Close()
+
+	configJSON, err := io.ReadAll(configFile)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(configJSON, configData)
+}
+
+func parseCSVHotel(header map[string]int, row []string) (hotel models.Hotel, err error) {
+	field := func(name string) string {
+		index, found := header[name]
+
+		if !found || index >= len(row) {
+			return ``
+		}
+
+		return strings.TrimSpace(row[index])
+	}
+
+	intField := func(name string) (value int) {
+		valueStr := field(name)
+
+		if valueStr == `` || err != nil {
+			return 0
+		}
+
+		value, err = strconv.Atoi(valueStr)
+
+		if err != nil {
+			err = fmt.Errorf(`invalid %s value %q`, name, valueStr)
+		}
+
+		return value
+	}
+
+	hotel.Uid = field(`hotelUid`)
+	hotel.Name = field(`name`)
+	hotel.Country = field(`country`)
+	hotel.City = field(`city`)
+	hotel.Address = field(`address`)
+	hotel.Stars = intField(`stars`)
+	hotel.Price = intField(`price`)
+	hotel.Currency = field(`currency`)
+	hotel.RoomsCount = intField(`roomsCount`)
+
+	if retiredStr := field(`retired`); retiredStr != `` && err == nil {
+		hotel.Retired, err = strconv.ParseBool(retiredStr)
+
+		if err != nil {
+			err = fmt.Errorf(`invalid retired value %q`, retiredStr)
+		}
+	}
+
+	return hotel, err
+}
+
+func readCSVHotels(reader io.Reader) (records []hotelRecord, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	headerRow, err := csvReader.Read()
+
+	if err != nil {
+		return records, fmt.Errorf(`cannot read CSV header: %w`, err)
+	}
+
+	header := make(map[string]int)
+
+	for i, name := range headerRow {
+		header[strings.TrimSpace(name)] = i
+	}
+
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			records = append(records, hotelRecord{line: line, err: err})
+			continue
+		}
+
+		hotel, err := parseCSVHotel(header, row)
+		records = append(records, hotelRecord{line, hotel, err})
+	}
+
+	return records, nil
+}
+
+func readJSONLHotels(reader io.Reader) (records []hotelRecord, err error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		lineStr := strings.TrimSpace(scanner.Text())
+
+		if lineStr == `` {
+			continue
+		}
+
+		var hotel models.Hotel
+		err = json.Unmarshal([]byte(lineStr), &hotel)
+		records = append(records, hotelRecord{line, hotel, err})
+	}
+
+	return records, scanner.Err()
+}
+
+func validateRecord(record *hotelRecord) {
+	if record.err != nil {
+		return
+	}
+
+	// A generated uid would create the hotel again on every re-import.
+	if record.hotel.Uid == `` {
+		record.err = errors.New(`hotelUid is required`)
+		return
+	} else if uuid.Validate(record.hotel.Uid) != nil {
+		record.err = fmt.Errorf(`invalid hotelUid %q`, record.hotel.Uid)
+		return
+	}
+
+	validErrRes, err := models.ValidateHotel(&record.hotel)
+
+	if err != nil {
+		descriptions := make([]string, 0)
+
+		for _, errDescription := range validErrRes.Errors {
+			descriptions = append(descriptions, errDescription.Field+`: `+errDescription.Error)
+		}
+
+		record.err = fmt.Errorf(`%w (%s)`, err, strings.Join(descriptions, `; `))
+	}
+}
+
+func importHotels(
+	hotelDAO database.IHotelDAO,
+	reader io.Reader,
+	format string,
+	batchSize int,
+	skipInvalid bool,
+) error {
+	var records []hotelRecord
+	var err error
+
+	if format == formatCSV {
+		records, err = readCSVHotels(reader)
+	} else {
+		records, err = readJSONLHotels(reader)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	hotels := make([]models.Hotel, 0, len(records))
+	invalidCount := 0
+
+	for i := range records {
+		validateRecord(&records[i])
+
+		if records[i].err != nil {
+			log.Printf("[ERROR] Line %d: %v\n", records[i].line, records[i].err)
+			invalidCount++
+		} else {
+			hotels = append(hotels, records[i].hotel)
+		}
+	}
+
+	if invalidCount != 0 && !skipInvalid {
+		return fmt.Errorf(`%d invalid records found, nothing was imported`, invalidCount)
+	}
+
+	importedCount := 0
+
+	for start := 0; start < len(hotels); start += batchSize {
+		end := min(start+batchSize, len(hotels))
+		upsertedCount, err := hotelDAO.UpsertBatch(hotels[start:end])
+
+		if err != nil {
+			return fmt.Errorf(`batch starting at record %d failed after %d imported hotels: %w`, start+1, importedCount, err)
+		}
+
+		importedCount += upsertedCount
+	}
+
+	log.Printf("[INFO] Imported %d hotels, skipped %d invalid records\n", importedCount, invalidCount)
+
+	return nil
+}
+
+func exportHotels(
+	hotelDAO database.IHotelDAO,
+	writer io.Writer,
+	format string,
+) error {
+	hotelsLst, err := hotelDAO.Get()
+
+	if err != nil {
+		return err
+	}
+
+	var csvWriter *csv.Writer
+	jsonEncoder := json.NewEncoder(writer)
+
+	if format == formatCSV {
+		csvWriter = csv.NewWriter(writer)
+		csvWriter.Write(csvHeader)
+	}
+
+	for hotelsLstEl := hotelsLst.Front(); hotelsLstEl != nil; hotelsLstEl = hotelsLstEl.Next() {
+		hotel := hotelsLstEl.Value.(models.Hotel)
+
+		if csvWriter != nil {
+			err = csvWriter.Write([]string{
+				hotel.Uid, hotel.Name, hotel.Country, hotel.City, hotel.Address,
+				strconv.Itoa(hotel.Stars), strconv.Itoa(hotel.Price), hotel.Currency,
+				strconv.Itoa(hotel.RoomsCount), strconv.FormatBool(hotel.Retired),
+			})
+		} else {
+			err = jsonEncoder.Encode(hotel)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		err = csvWriter.Error()
+	}
+
+	if err == nil {
+		log.Printf("[INFO] Exported %d hotels\n", hotelsLst.Len())
+	}
+
+	return err
+}
+
+func main() {
+	mode := flag.String(`mode`, `import`, `import or export`)
+	format := flag.String(`format`, formatCSV, `csv or jsonl`)
+	filePath := flag.String(`file`, ``, `input or output file (stdin or stdout when empty)`)
+	configPath := flag.String(`config`, `/configs/config.json`, `reservation service config file with connDb`)
+	connStr := flag.String(`db`, ``, `database connection string (overrides config)`)
+	batchSize := flag.Int(`batch`, 100, `hotels per upsert batch`)
+	skipInvalid := flag.Bool(`skip-invalid`, false, `import valid records even if some records are invalid`)
+	flag.Parse()
+
+	if *format != formatCSV && *format != formatJSONL {
+		log.Fatalln("[FATAL] Main. Unknown format:", *format)
+	}
+
+	if *batchSize <= 0 {
+		log.Fatalln("[FATAL] Main. Invalid batch size:", *batchSize)
+	}
+
+	configData := importConfigDataStruct{ConnStr: *connStr}
+
+	if configData.ConnStr == `` {
+		err := readConfig(*configPath, &configData)
+
+		if err != nil {
+			log.Fatalln("[FATAL] Main. Failed to read config file: ", err)
+		}
+	}
+
+	hotelDAO := database.NewPostgresHotelDAO(configData.ConnStr)
+
+	var err error
+
+	switch *mode {
+	case `import`:
+		reader := io.Reader(os.Stdin)
+
+		if *filePath != `` {
+			file, err := os.Open(*filePath)
+
+			if err != nil {
+				log.Fatalln("[FATAL] Main. Failed to open input file: ", err)
+			}
+
+			defer file.Close()
+
+			reader = file
+		}
+
+		err = importHotels(hotelDAO, reader, *format, *batchSize, *skipInvalid)
+	case `export`:
+		writer := io.Writer(os.Stdout)
+
+		if *filePath != `` {
+			file, err := os.Create(*filePath)
+
+			if err != nil {
+				log.Fatalln("[FATAL] Main. Failed to create output file: ", err)
+			}
+
+			defer file.Close()
+
+			writer = file
+		}
+
+		err = exportHotels(hotelDAO, writer, *format)
+	default:
+		log.Fatalln("[FATAL] Main. Unknown mode:", *mode)
+	}
+
+	if err != nil {
+		log.Fatalf("[FATAL] Main. Hotels %s failed: %v\n", *mode, err)
+	}
+}
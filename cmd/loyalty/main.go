@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/agarmirus/ds-lab02/internal/controllers"
 	"github.com/agarmirus/ds-lab02/internal/database"
@@ -13,9 +15,13 @@ import (
 )
 
 type loyaltyConfigDataStruct struct {
-	Host    string `json:"host"`
-	Port    int    `json:"port"`
-	ConnStr string `json:"connDb"`
+	Host                   string `json:"host"`
+	Port                   int    `json:"port"`
+	ConnStr                string `json:"connDb"`
+	TierWindowDays         int    `json:"tierWindowDays"`
+	TierJobIntervalMinutes int    `json:"tierJobIntervalMinutes"`
+	TierJobDryRun          bool   `json:"tierJobDryRun"`
+	AdminToken             string `json:"adminToken"`
 }
 
 func readConfig(path string, configData *loyaltyConfigDataStruct) (err error) {
@@ -36,10 +42,40 @@ func readConfig(path string, configData *loyaltyConfigDataStruct) (err error) {
 	return json.Unmarshal(configJSON, configData)
 }
 
+// Secrets may be supplied through the environment so that the committed config
+// never holds a usable value; placeholders are rejected at startup.
+func readSecret(configValue string, envName string) (secret string, err error) {
+	secret = configValue
+
+	if envValue, ok := os.LookupEnv(envName); ok {
+		secret = envValue
+	}
+
+	if secret == `` || strings.HasPrefix(secret, `change-me`) {
+		log.Println("[ERROR] Main. Insecure value for", envName)
+		return ``, serverrors.ErrInsecureSecret
+	}
+
+	return secret, nil
+}
+
 func buildService(configData *loyaltyConfigDataStruct) (controller controllers.IController, err error) {
+	adminToken, err := readSecret(configData.AdminToken, `ADMIN_TOKEN`)
+
+	if err != nil {
+		return nil, err
+	}
+
 	loyaltyDAO := database.NewPostgresLoyaltyDAO(configData.ConnStr)
-	service := services.NewLoyaltyService(loyaltyDAO)
-	controller = controllers.NewLoyaltyController(configData.Host, configData.Port, service)
+	eventsDAO := database.NewPostgresLoyaltyEventDAO(configData.ConnStr)
+	service := services.NewLoyaltyService(
+		loyaltyDAO,
+		eventsDAO,
+		configData.TierWindowDays,
+		time.Duration(configData.TierJobIntervalMinutes)*time.Minute,
+		configData.TierJobDryRun,
+	)
+	controller = controllers.NewLoyaltyController(configData.Host, configData.Port, service, adminToken)
 
 	return controller, nil
 }
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/agarmirus/ds-lab02/internal/controllers"
 	"github.com/agarmirus/ds-lab02/internal/database"
@@ -16,6 +17,13 @@ type paymentConfigDataStruct struct {
 	Host    string `json:"host"`
 	Port    int    `json:"port"`
 	ConnStr string `json:"connDb"`
+
+	AdminToken string `json:"adminToken"`
+
+	PlatformFeePercent int `json:"platformFeePercent"`
+
+	Provider     string                             `json:"provider"`
+	FakeProvider services.FakePaymentProviderConfig `json:"fakeProvider"`
 }
 
 func readConfig(path string, configData *paymentConfigDataStruct) (err error) {
@@ -36,10 +44,50 @@ func readConfig(path string, configData *paymentConfigDataStruct) (err error) {
 	return json.Unmarshal(configJSON, configData)
 }
 
+// Secrets may be supplied through the environment so that the committed config
+// never holds a usable value; placeholders are rejected at startup.
+func readSecret(configValue string, envName string) (secret string, err error) {
+	secret = configValue
+
+	if envValue, ok := os.LookupEnv(envName); ok {
+		secret = envValue
+	}
+
+	if secret == `` || strings.HasPrefix(secret, `change-me`) {
+		log.Println("[ERROR] Main. Insecure value for", envName)
+		return ``, serverrors.ErrInsecureSecret
+	}
+
+	return secret, nil
+}
+
 func buildService(configData *paymentConfigDataStruct) (controller controllers.IController, err error) {
+	adminToken, err := readSecret(configData.AdminToken, `ADMIN_TOKEN`)
+
+	if err != nil {
+		return nil, err
+	}
+
 	paymentDAO := database.NewPostgresPaymentDAO(configData.ConnStr)
-	service := services.NewPaymentService(paymentDAO)
-	controller = controllers.NewPaymentController(configData.Host, configData.Port, service)
+	ledgerDAO := database.NewPostgresLedgerDAO(configData.ConnStr)
+
+	var provider services.IPaymentProvider
+
+	switch configData.Provider {
+	case ``, `fake`:
+		provider = services.NewFakePaymentProvider(configData.FakeProvider, paymentDAO)
+	default:
+		log.Println("[ERROR] buildService. Unknown payment provider:", configData.Provider)
+		return nil, serverrors.ErrServiceBuild
+	}
+
+	if configData.PlatformFeePercent < 0 || configData.PlatformFeePercent > 100 {
+		log.Println("[ERROR] buildService. Invalid platform fee percent:", configData.PlatformFeePercent)
+		return nil, serverrors.ErrServiceBuild
+	}
+
+	service := services.NewPaymentService(paymentDAO, ledgerDAO, provider, configData.PlatformFeePercent)
+	controller = controllers.NewPaymentController(configData.Host, configData.Port, service, adminToken)
 
 	return controller, nil
 }
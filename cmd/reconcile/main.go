@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/agarmirus/ds-lab02/internal/services"
+)
+
+type reconcileConfigDataStruct struct {
+	LoayltyHost       string `json:"loayltyHost"`
+	PaymentHost       string `json:"paymentHost"`
+	ReservHost        string `json:"reservHost"`
+	LoyaltyPort       int    `json:"loyaltyPort"`
+	PaymentPort       int    `json:"paymentPort"`
+	ReservPort        int    `json:"reservPort"`
+	MaxRequestFails   int    `json:"maxRequestFails"`
+	MaxResetQueueSize int    `json:"maxResetQueueSize"`
+
+	ReconcileAdminToken string `json:"reconcileAdminToken"`
+}
+
+func readConfig(path string, configData *reconcileConfigDataStruct) (err error) {
+	configFile, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer configFile.Close()
+
+	configJSON, err := io.ReadAll(configFile)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(configJSON, configData)
+}
+
+func main() {
+	configPath := flag.String(`config`, `/configs/config.json`, `gateway config file with service hosts and ports`)
+	adminToken := flag.String(`token`, ``, `admin token of the services (overrides ADMIN_TOKEN and config)`)
+	applyFixes := flag.Bool(`fix`, false, `apply fixes for the discrepancies found`)
+	filePath := flag.String(`out`, ``, `report file (stdout when empty)`)
+	flag.Parse()
+
+	var configData reconcileConfigDataStruct
+	err := readConfig(*configPath, &configData)
+
+	if err != nil {
+		log.Fatalln("[FATAL] Main. Failed to read config file: ", err)
+	}
+
+	if envValue, ok := os.LookupEnv(`ADMIN_TOKEN`); ok {
+		configData.ReconcileAdminToken = envValue
+	}
+
+	if *adminToken != `` {
+		configData.ReconcileAdminToken = *adminToken
+	}
+
+	if configData.ReconcileAdminToken == `` || strings.HasPrefix(configData.ReconcileAdminToken, `change-me`) {
+		log.Fatalln("[FATAL] Main. Admin token is empty or a placeholder, set -token or ADMIN_TOKEN")
+	}
+
+	service := services.NewGatewayService(
+		configData.ReservHost,
+		configData.ReservPort,
+		configData.PaymentHost,
+		configData.PaymentPort,
+		configData.LoayltyHost,
+		configData.LoyaltyPort,
+		configData.MaxRequestFails,
+		configData.MaxResetQueueSize,
+		``,
+		0,
+		0,
+		``,
+		0,
+		``,
+		false,
+	)
+
+	report, err := service.Reconcile(`Bearer `+configData.ReconcileAdminToken, *applyFixes)
+
+	if err != nil {
+		log.Fatalln("[FATAL] Main. Reconciliation failed: ", err)
+	}
+
+	writer := io.Writer(os.Stdout)
+
+	if *filePath != `` {
+		file, err := os.Create(*filePath)
+
+		if err != nil {
+			log.Fatalln("[FATAL] Main. Failed to create report file: ", err)
+		}
+
+		defer file.Close()
+
+		writer = file
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent(``, `    `)
+	err = encoder.Encode(report)
+
+	if err != nil {
+		log.Fatalln("[FATAL] Main. Failed to write report: ", err)
+	}
+
+	log.Printf("[INFO] Found %d issues in %d reservations, %d payments and %d loyalties\n",
+		len(report.Issues), report.ReservationsChecked, report.PaymentsChecked, report.LoyaltiesChecked)
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/agarmirus/ds-lab02/internal/controllers"
 	"github.com/agarmirus/ds-lab02/internal/database"
@@ -16,6 +18,11 @@ type reservConfigDataStruct struct {
 	Host    string `json:"host"`
 	Port    int    `json:"port"`
 	ConnStr string `json:"connDb"`
+
+	AdminToken string `json:"adminToken"`
+
+	HoldTtlMinutes           int `json:"holdTtlMinutes"`
+	HoldSweepIntervalSeconds int `json:"holdSweepIntervalSeconds"`
 }
 
 func readConfig(path string, configData *reservConfigDataStruct) (err error) {
@@ -36,11 +43,58 @@ func readConfig(path string, configData *reservConfigDataStruct) (err error) {
 	return json.Unmarshal(configJSON, configData)
 }
 
+// Secrets may be supplied through the environment so that the committed config
+// never holds a usable value; placeholders are rejected at startup.
+func readSecret(configValue string, envName string) (secret string, err error) {
+	secret = configValue
+
+	if envValue, ok := os.LookupEnv(envName); ok {
+		secret = envValue
+	}
+
+	if secret == `` || strings.HasPrefix(secret, `change-me`) {
+		log.Println("[ERROR] Main. Insecure value for", envName)
+		return ``, serverrors.ErrInsecureSecret
+	}
+
+	return secret, nil
+}
+
 func buildService(configData *reservConfigDataStruct) (controller controllers.IController, err error) {
+	adminToken, err := readSecret(configData.AdminToken, `ADMIN_TOKEN`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Holds which never expire would keep rooms and promo code usages forever.
+	if configData.HoldTtlMinutes <= 0 {
+		return nil, serverrors.ErrInvalidHoldTtl
+	}
+
 	hotelDAO := database.NewPostgresHotelDAO(configData.ConnStr)
 	reservDAO := database.NewPostgresReservationDAO(configData.ConnStr)
-	service := services.NewReservationService(reservDAO, hotelDAO)
-	controller = controllers.NewReservationController(configData.Host, configData.Port, service)
+	promoCodeDAO := database.NewPostgresPromoCodeDAO(configData.ConnStr)
+	promoUsageDAO := database.NewPostgresPromoCodeUsageDAO(configData.ConnStr)
+	policyDAO := database.NewPostgresCancelPolicyDAO(configData.ConnStr)
+	rateRuleDAO := database.NewPostgresRateRuleDAO(configData.ConnStr)
+	exchangeRateDAO := database.NewPostgresExchangeRateDAO(configData.ConnStr)
+	taxRuleDAO := database.NewPostgresTaxRuleDAO(configData.ConnStr)
+	feedDAO := database.NewPostgresCalendarFeedDAO(configData.ConnStr)
+	service := services.NewReservationService(
+		reservDAO,
+		hotelDAO,
+		promoCodeDAO,
+		promoUsageDAO,
+		policyDAO,
+		rateRuleDAO,
+		exchangeRateDAO,
+		taxRuleDAO,
+		feedDAO,
+		time.Duration(configData.HoldTtlMinutes)*time.Minute,
+		time.Duration(configData.HoldSweepIntervalSeconds)*time.Second,
+	)
+	controller = controllers.NewReservationController(configData.Host, configData.Port, service, adminToken)
 
 	return controller, nil
 }
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Admin endpoints of every service accept the same bearer token. They stay
+// closed while no token is configured.
+func hasAdminToken(req *http.Request, adminToken string) bool {
+	token, found := strings.CutPrefix(req.Header.Get(`Authorization`), `Bearer `)
+
+	if !found || adminToken == `` {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -31,23 +33,134 @@ func NewGatewayController(
 	return &GatewayController{host, port, service}
 }
 
+func writeDisplayCurrencyError(res http.ResponseWriter, err error) {
+	validErrRes := models.ValidationErrorResponse{
+		Message: `invalid display currency`,
+		Errors:  []models.ErrorDiscription{{Field: `currency`, Error: err.Error()}},
+	}
+
+	validErrResJSON, _ := json.Marshal(validErrRes)
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusBadRequest)
+	res.Write(validErrResJSON)
+}
+
+// The currency query parameter takes precedence over the Accept-Currency header.
+// An empty result means prices are shown in the currency they are settled in.
+func readDisplayCurrency(res http.ResponseWriter, req *http.Request) (currency string, ok bool) {
+	currency = req.URL.Query().Get(`currency`)
+
+	if currency == `` {
+		currency = req.Header.Get(`Accept-Currency`)
+	}
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	if currency != `` && !models.IsValidCurrency(currency) {
+		log.Println("[ERROR] GatewayController.readDisplayCurrency. Invalid currency:", currency)
+		writeDisplayCurrencyError(res, serverrors.ErrInvalidCurrency)
+		return currency, false
+	}
+
+	return currency, true
+}
+
+func (controller *GatewayController) convertForDisplay(
+	res http.ResponseWriter,
+	currency string,
+	convert func([]models.ExchangeRate) error,
+) bool {
+	if currency == `` {
+		return true
+	}
+
+	rates, err := controller.service.ReadExchangeRates()
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.convertForDisplay. service.ReadExchangeRates returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrRequestSend) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return false
+		}
+
+		res.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+
+	err = convert(rates)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.convertForDisplay. Cannot convert prices:", err)
+
+		if errors.Is(err, serverrors.ErrExchangeRateNotFound) {
+			writeDisplayCurrencyError(res, err)
+			return false
+		}
+
+		res.WriteHeader(http.StatusInternalServerError)
+		return false
+	}
+
+	return true
+}
+
 func (controller *GatewayController) handleAllHotelsGet(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] GatewayController.handleAllHotelsGet. Handling hotels GET request")
 
+	cursor := req.FormValue(`cursor`)
+	_, cursorMode := req.URL.Query()[`cursor`]
+
 	page, pageParseErr := strconv.Atoi(req.FormValue(`page`))
 	pageSize, pageSizeParseErr := strconv.Atoi(req.FormValue(`size`))
 
+	if cursorMode {
+		page, pageParseErr = 1, nil
+	}
+
 	if pageParseErr != nil || pageSizeParseErr != nil || page <= 0 || pageSize <= 0 {
 		log.Println("[ERROR] GatewayController.handleAllHotelsGet. Invalid URL parameters")
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	pagRes, err := controller.service.ReadAllHotels(page, pageSize)
+	currency, ok := readDisplayCurrency(res, req)
+
+	if !ok {
+		return
+	}
+
+	filter, validErrRes, err := models.ParseHotelFilter(req.URL.Query())
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAllHotelsGet. Invalid hotels filter:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	if cursorMode {
+		page = 0
+	}
+
+	pagRes, validErrRes, err := controller.service.ReadAllHotels(&filter, cursor, page, pageSize)
 
 	if err != nil {
 		log.Println("[ERROR] GatewayController.handleAllHotelsGet. service.ReadAllHotels returned error: ", err)
 
+		if errors.Is(err, serverrors.ErrInvalidCursor) || errors.Is(err, serverrors.ErrInvalidHotelFilter) {
+			validErrResJSON, _ := json.Marshal(validErrRes)
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusBadRequest)
+			res.Write(validErrResJSON)
+			return
+		}
+
 		if errors.Is(err, serverrors.ErrRequestSend) {
 			res.WriteHeader(http.StatusServiceUnavailable)
 			return
@@ -57,8 +170,11 @@ func (controller *GatewayController) handleAllHotelsGet(res http.ResponseWriter,
 		return
 	}
 
-	if len(pagRes.Items) == 0 {
-		res.WriteHeader(http.StatusNoContent)
+	convertItems := func(rates []models.ExchangeRate) error {
+		return models.ConvertHotelResponses(pagRes.Items, currency, rates)
+	}
+
+	if !controller.convertForDisplay(res, currency, convertItems) {
 		return
 	}
 
@@ -86,6 +202,12 @@ func (controller *GatewayController) handleUserInfoGet(res http.ResponseWriter,
 		return
 	}
 
+	currency, ok := readDisplayCurrency(res, req)
+
+	if !ok {
+		return
+	}
+
 	userInfoRes, err := controller.service.ReadUserInfo(username)
 
 	if err != nil {
@@ -97,6 +219,22 @@ func (controller *GatewayController) handleUserInfoGet(res http.ResponseWriter,
 		}
 	}
 
+	convertReservations := func(rates []models.ExchangeRate) error {
+		for i := range userInfoRes.Reservations {
+			err := models.ConvertReservationResponse(&userInfoRes.Reservations[i], currency, rates)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !controller.convertForDisplay(res, currency, convertReservations) {
+		return
+	}
+
 	var userInfoResJSON []byte
 	userInfoResJSON, err = json.Marshal(userInfoRes)
 
@@ -110,6 +248,125 @@ func (controller *GatewayController) handleUserInfoGet(res http.ResponseWriter,
 	}
 }
 
+func writeCalendarTokenError(res http.ResponseWriter, err error) {
+	if errors.Is(err, serverrors.ErrRequestSend) {
+		res.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		res.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (controller *GatewayController) writeCalendarFeedResponse(
+	res http.ResponseWriter,
+	req *http.Request,
+	token string,
+) {
+	// Calendar apps need an absolute link; proxies report the original scheme.
+	scheme := req.Header.Get(`X-Forwarded-Proto`)
+
+	if scheme == `` {
+		scheme = `http`
+	}
+
+	feedUrl := url.URL{
+		Scheme:   scheme,
+		Host:     req.Host,
+		Path:     `/api/v1/me/reservations.ics`,
+		RawQuery: url.Values{`token`: {token}}.Encode(),
+	}
+
+	feedResJSON, err := json.Marshal(models.CalendarFeedResponse{FeedToken: token, FeedUrl: feedUrl.String()})
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.writeCalendarFeedResponse. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(feedResJSON)
+}
+
+func (controller *GatewayController) handleCalendarTokenGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleCalendarTokenGet. Handling calendar token GET request")
+
+	username := req.Header.Get(`X-User-Name`)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayController.handleCalendarTokenGet. Invalid username: " + username)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := controller.service.ReadCalendarFeedToken(username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleCalendarTokenGet. service.ReadCalendarFeedToken returned error: ", err)
+		writeCalendarTokenError(res, err)
+		return
+	}
+
+	controller.writeCalendarFeedResponse(res, req, token)
+}
+
+// Resetting revokes every feed link issued to the user before and returns a new one.
+func (controller *GatewayController) handleCalendarTokenPost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleCalendarTokenPost. Handling calendar token POST request")
+
+	username := req.Header.Get(`X-User-Name`)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayController.handleCalendarTokenPost. Invalid username: " + username)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := controller.service.ResetCalendarFeedToken(username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleCalendarTokenPost. service.ResetCalendarFeedToken returned error: ", err)
+		writeCalendarTokenError(res, err)
+		return
+	}
+
+	controller.writeCalendarFeedResponse(res, req, token)
+}
+
+func (controller *GatewayController) handleUserCalendarGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleUserCalendarGet. Handling user calendar GET request")
+
+	username := req.Header.Get(`X-User-Name`)
+	feedToken := strings.Trim(req.URL.Query().Get(`token`), ` `)
+
+	if strings.Trim(username, ` `) == `` && feedToken == `` {
+		log.Println("[ERROR] GatewayController.handleUserCalendarGet. Neither username nor feed token is given")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	calendar, err := controller.service.ReadUserCalendar(username, feedToken)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleUserCalendarGet. service.ReadUserCalendar returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrInvalidToken) {
+			res.WriteHeader(http.StatusUnauthorized)
+		} else if errors.Is(err, serverrors.ErrRequestSend) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `text/calendar; charset=utf-8`)
+	res.Header().Add(`Content-Disposition`, `inline; filename="reservations.ics"`)
+	res.WriteHeader(http.StatusOK)
+	res.Write([]byte(calendar))
+}
+
 func (controller *GatewayController) handleUserReservationsGet(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] GatewayController.handleUserReservationsGet. Handling user reservations GET request")
 
@@ -121,6 +378,12 @@ func (controller *GatewayController) handleUserReservationsGet(res http.Response
 		return
 	}
 
+	currency, ok := readDisplayCurrency(res, req)
+
+	if !ok {
+		return
+	}
+
 	reservsResSlice, err := controller.service.ReadUserReservations(username)
 
 	if err != nil {
@@ -135,6 +398,22 @@ func (controller *GatewayController) handleUserReservationsGet(res http.Response
 		return
 	}
 
+	convertReservations := func(rates []models.ExchangeRate) error {
+		for i := range reservsResSlice {
+			err := models.ConvertReservationResponse(&reservsResSlice[i], currency, rates)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if !controller.convertForDisplay(res, currency, convertReservations) {
+		return
+	}
+
 	var reservsResSliceJSON []byte
 	reservsResSliceJSON, err = json.Marshal(reservsResSlice)
 
@@ -182,7 +461,7 @@ func (controller *GatewayController) handleNewReservationPost(res http.ResponseW
 
 	if err == nil {
 		var crReservRes models.CreateReservationResponse
-		crReservRes, err = controller.service.CreateReservation(username, &crReservReq)
+		crReservRes, err = controller.service.CreateReservation(username, req.Header.Get(`Idempotency-Key`), &crReservReq)
 
 		if err != nil {
 			log.Println("[ERROR] GatewayController.handleNewReservationPost. service.CreateReservation returned error: ", err)
@@ -198,6 +477,59 @@ func (controller *GatewayController) handleNewReservationPost(res http.ResponseW
 				return
 			}
 
+			if errors.Is(err, serverrors.ErrNoRoomsAvailable) {
+				errRes := models.ErrorResponse{Message: `No rooms available for the requested dates`}
+
+				errResJSON, _ := json.Marshal(errRes)
+
+				res.Header().Add(`Content-Type`, `application/json`)
+				res.WriteHeader(http.StatusConflict)
+				res.Write(errResJSON)
+				return
+			}
+
+			if errors.Is(err, serverrors.ErrIdempotencyKeyReused) {
+				errRes := models.ErrorResponse{Message: `Idempotency key was already used for another request`}
+
+				errResJSON, _ := json.Marshal(errRes)
+
+				res.Header().Add(`Content-Type`, `application/json`)
+				res.WriteHeader(http.StatusConflict)
+				res.Write(errResJSON)
+				return
+			}
+
+			if errors.Is(err, serverrors.ErrHoldExpired) {
+				errRes := models.ErrorResponse{Message: `Reservation hold expired before payment was confirmed`}
+
+				errResJSON, _ := json.Marshal(errRes)
+
+				res.Header().Add(`Content-Type`, `application/json`)
+				res.WriteHeader(http.StatusConflict)
+				res.Write(errResJSON)
+				return
+			}
+
+			if errors.Is(err, serverrors.ErrPaymentDeclined) {
+				errRes := models.ErrorResponse{Message: `Payment was declined`}
+
+				errResJSON, _ := json.Marshal(errRes)
+
+				res.Header().Add(`Content-Type`, `application/json`)
+				res.WriteHeader(http.StatusPaymentRequired)
+				res.Write(errResJSON)
+				return
+			}
+
+			if errors.Is(err, serverrors.ErrProviderTimeout) {
+				res.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+
+			if writePricingError(res, err) {
+				return
+			}
+
 			res.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -229,110 +561,969 @@ func (controller *GatewayController) handleNewReservationPost(res http.ResponseW
 	}
 }
 
-func (controller *GatewayController) handleSingleReservationGet(res http.ResponseWriter, req *http.Request) {
-	log.Println("[INFO] GatewayController.handleSingleReservationGet. Handling single reservation GET request")
-
-	reservationUid := req.PathValue("reservationUid")
-	username := req.Header.Get(`X-User-Name`)
-
-	if strings.Trim(username, ` `) == `` || uuid.Validate(reservationUid) != nil {
-		log.Println("[ERROR] GatewayController.handleSingleReservationGet. Invalid parameters or headers")
-		res.WriteHeader(http.StatusBadRequest)
-		return
+func writeValidationError(res http.ResponseWriter, field string, err error) {
+	validErrRes := models.ValidationErrorResponse{
+		Message: `invalid reservation request data`,
+		Errors:  []models.ErrorDiscription{{Field: field, Error: err.Error()}},
 	}
 
-	reservRes, err := controller.service.ReadReservation(reservationUid, username)
+	validErrResJSON, _ := json.Marshal(validErrRes)
 
-	if err != nil {
-		log.Println("[ERROR] GatewayController.handleSingleReservationGet. service.ReadReservation returned error: ", err)
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusBadRequest)
+	res.Write(validErrResJSON)
+}
 
-		if errors.Is(err, serverrors.ErrRequestSend) {
-			res.WriteHeader(http.StatusServiceUnavailable)
-			return
-		}
+func writePricingError(res http.ResponseWriter, err error) bool {
+	if errors.Is(err, serverrors.ErrPromoCodeNotFound) || errors.Is(err, serverrors.ErrPromoCodeNotApplicable) {
+		writeValidationError(res, `promoCode`, err)
+		return true
+	}
 
-		res.WriteHeader(http.StatusInternalServerError)
-		return
+	if errors.Is(err, serverrors.ErrHotelNotFound) {
+		writeValidationError(res, `hotelUid`, err)
+		return true
 	}
 
-	reservResJSON, err := json.Marshal(reservRes)
+	if errors.Is(err, serverrors.ErrMinStayNotMet) || errors.Is(err, serverrors.ErrInvalidReservDates) {
+		writeValidationError(res, `endDate`, err)
+		return true
+	}
 
-	if err != nil {
-		log.Println("[ERROR] GatewayController.handleSingleReservationGet. Cannot convert result into JSON format: ", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
+	if errors.Is(err, serverrors.ErrInvalidToken) ||
+		errors.Is(err, serverrors.ErrQuoteExpired) ||
+		errors.Is(err, serverrors.ErrQuoteMismatch) ||
+		errors.Is(err, serverrors.ErrQuotePriceChanged) {
+		writeValidationError(res, `quoteToken`, err)
+		return true
 	}
 
-	res.Header().Add(`Content-Type`, `application/json`)
-	res.WriteHeader(http.StatusOK)
-	res.Write(reservResJSON)
+	return false
 }
 
-func (controller *GatewayController) handleSingleReservationDelete(res http.ResponseWriter, req *http.Request) {
-	log.Println("[INFO] GatewayController.handleSingleReservationDelete. Handling reservation DELETE request")
+func (controller *GatewayController) handleReservationQuotePost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleReservationQuotePost. Handling reservation quote POST request")
 
-	reservationUid := req.PathValue("reservationUid")
 	username := req.Header.Get(`X-User-Name`)
 
-	if strings.Trim(username, ` `) == `` || uuid.Validate(reservationUid) != nil {
-		log.Println("[ERROR] GatewayController.handleSingleReservationDelete. Invalid parameters or headers")
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayController.handleReservationQuotePost. Invalid username: " + username)
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	err := controller.service.DeleteReservation(reservationUid, username)
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayController.handleSingleReservationDelete. service.DeleteReservation returned error: ", err)
-		res.WriteHeader(http.StatusInternalServerError)
+		log.Println("[ERROR] GatewayController.handleReservationQuotePost. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	res.WriteHeader(http.StatusNoContent)
-}
-
-func (controller *GatewayController) handleLoyaltyGet(res http.ResponseWriter, req *http.Request) {
-	log.Println("[INFO] GatewayController.handleLoyaltyGet. Handling loyalty GET request")
-
-	username := req.Header.Get(`X-User-Name`)
+	var crReservReq models.CreateReservationRequest
+	err = json.Unmarshal(reqBody, &crReservReq)
 
-	if strings.Trim(username, ` `) == `` {
-		log.Println("[ERROR] GatewayController.handleLoyaltyGet. Invalid username: " + username)
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleReservationQuotePost. Error while parsing JSON request body: ", err)
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	loyaltyInfoRes, err := controller.service.ReadUserLoyalty(username)
+	validErrRes, err := models.ValidateCrReservReq(&crReservReq)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayController.handleLoyaltyGet. service.ReadUserLoyalty returned error: ", err)
+		log.Println("[ERROR] GatewayController.handleReservationQuotePost. Invalid quote request:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
 
-		if errors.Is(err, serverrors.ErrLoyaltyServiceUnavailable) {
-			errRes := models.ErrorResponse{Message: `Loyalty Service unavailable`}
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	quote, err := controller.service.ReadPriceQuote(username, &crReservReq)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleReservationQuotePost. service.ReadPriceQuote returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrLoyaltyServiceUnavailable) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: `Loyalty Service unavailable`})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusServiceUnavailable)
+			res.Write(errResJSON)
+			return
+		}
+
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if writePricingError(res, err) {
+			return
+		}
+
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	quoteJSON, err := json.Marshal(quote)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleReservationQuotePost. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(quoteJSON)
+}
+
+func (controller *GatewayController) handleSingleReservationGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleSingleReservationGet. Handling single reservation GET request")
+
+	reservationUid := req.PathValue("reservationUid")
+	username := req.Header.Get(`X-User-Name`)
+
+	if strings.Trim(username, ` `) == `` || uuid.Validate(reservationUid) != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationGet. Invalid parameters or headers")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	currency, ok := readDisplayCurrency(res, req)
+
+	if !ok {
+		return
+	}
+
+	reservRes, err := controller.service.ReadReservation(reservationUid, username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationGet. service.ReadReservation returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrRequestSend) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	convertReservation := func(rates []models.ExchangeRate) error {
+		return models.ConvertReservationResponse(&reservRes, currency, rates)
+	}
+
+	if !controller.convertForDisplay(res, currency, convertReservation) {
+		return
+	}
+
+	reservResJSON, err := json.Marshal(reservRes)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(reservResJSON)
+}
+
+// The format is taken from the format query parameter first and from the
+// Accept header otherwise; plain text is the default.
+func readReceiptFormat(req *http.Request) (format string, ok bool) {
+	format = strings.ToLower(strings.Trim(req.URL.Query().Get(`format`), ` `))
+
+	switch format {
+	case `html`, `text`:
+		return format, true
+	case ``:
+	default:
+		return format, false
+	}
+
+	if strings.Contains(req.Header.Get(`Accept`), `text/html`) {
+		return `html`, true
+	}
+
+	return `text`, true
+}
+
+func (controller *GatewayController) handleReservationReceiptGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleReservationReceiptGet. Handling reservation receipt GET request")
+
+	reservationUid := req.PathValue("reservationUid")
+	username := req.Header.Get(`X-User-Name`)
+	format, ok := readReceiptFormat(req)
+
+	if strings.Trim(username, ` `) == `` || uuid.Validate(reservationUid) != nil || !ok {
+		log.Println("[ERROR] GatewayController.handleReservationReceiptGet. Invalid parameters or headers")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := controller.service.ReadReservationReceipt(reservationUid, username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleReservationReceiptGet. service.ReadReservationReceipt returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrRequestSend) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	var receiptBuf bytes.Buffer
+	contentType := `text/plain; charset=utf-8`
+
+	if format == `html` {
+		contentType = `text/html; charset=utf-8`
+		err = models.RenderReceiptHTML(&receiptBuf, &receipt)
+	} else {
+		err = models.RenderReceiptText(&receiptBuf, &receipt)
+	}
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleReservationReceiptGet. Cannot render receipt: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, contentType)
+	res.WriteHeader(http.StatusOK)
+	res.Write(receiptBuf.Bytes())
+}
+
+func (controller *GatewayController) handleSingleReservationPatch(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleSingleReservationPatch. Handling reservation PATCH request")
+
+	reservationUid := req.PathValue("reservationUid")
+	username := req.Header.Get(`X-User-Name`)
+
+	if strings.Trim(username, ` `) == `` || uuid.Validate(reservationUid) != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationPatch. Invalid parameters or headers")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationPatch. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var changeReservReq models.ChangeReservationRequest
+	err = json.Unmarshal(reqBody, &changeReservReq)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationPatch. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateChangeReservReq(&changeReservReq)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationPatch. Invalid change reservation request:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	changeReservRes, err := controller.service.ChangeReservationDates(reservationUid, username, &changeReservReq)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationPatch. service.ChangeReservationDates returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrMinStayNotMet) || errors.Is(err, serverrors.ErrInvalidReservDates) {
+			writeValidationError(res, `endDate`, err)
+		} else if errors.Is(err, serverrors.ErrEntityNotFound) || errors.Is(err, serverrors.ErrReservNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrNoRoomsAvailable) || errors.Is(err, serverrors.ErrInvalidStatusTransition) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusConflict)
+			res.Write(errResJSON)
+		} else if errors.Is(err, serverrors.ErrPaymentDeclined) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: `Payment was declined`})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusPaymentRequired)
+			res.Write(errResJSON)
+		} else if errors.Is(err, serverrors.ErrProviderTimeout) {
+			res.WriteHeader(http.StatusGatewayTimeout)
+		} else if errors.Is(err, serverrors.ErrRequestSend) || errors.Is(err, serverrors.ErrLoyaltyServiceUnavailable) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	changeReservResJSON, err := json.Marshal(changeReservRes)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationPatch. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(changeReservResJSON)
+}
+
+func (controller *GatewayController) handleSingleReservationDelete(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleSingleReservationDelete. Handling reservation DELETE request")
+
+	reservationUid := req.PathValue("reservationUid")
+	username := req.Header.Get(`X-User-Name`)
+
+	if strings.Trim(username, ` `) == `` || uuid.Validate(reservationUid) != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationDelete. Invalid parameters or headers")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err := controller.service.DeleteReservation(reservationUid, username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleSingleReservationDelete. service.DeleteReservation returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrEntityNotFound) || errors.Is(err, serverrors.ErrReservNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrInvalidStatusTransition) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusConflict)
+			res.Write(errResJSON)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (controller *GatewayController) handleLoyaltyGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleLoyaltyGet. Handling loyalty GET request")
+
+	username := req.Header.Get(`X-User-Name`)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayController.handleLoyaltyGet. Invalid username: " + username)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	loyaltyInfoRes, err := controller.service.ReadUserLoyalty(username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleLoyaltyGet. service.ReadUserLoyalty returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrLoyaltyServiceUnavailable) {
+			errRes := models.ErrorResponse{Message: `Loyalty Service unavailable`}
+
+			errResJSON, _ := json.Marshal(errRes)
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusServiceUnavailable)
+			res.Write(errResJSON)
+			return
+		}
+
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	loyaltyInfoResJSON, err := json.Marshal(loyaltyInfoRes)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleLoyaltyGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(loyaltyInfoResJSON)
+}
+
+func (controller *GatewayController) handleHotelAvailabilityGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleHotelAvailabilityGet. Handling hotel availability GET request")
+
+	hotelUid := req.PathValue(`hotelUid`)
+	from := req.FormValue(`from`)
+	to := req.FormValue(`to`)
+
+	validErrRes, err := models.ValidateAvailabilityRange(from, to)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleHotelAvailabilityGet. Invalid availability range:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	availabilityRes, err := controller.service.ReadHotelAvailability(hotelUid, from, to)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleHotelAvailabilityGet. service.ReadHotelAvailability returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrInvalidHoteUid) {
+			res.WriteHeader(http.StatusBadRequest)
+		} else if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrRequestSend) {
+			res.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	availabilityResJSON, err := json.Marshal(availabilityRes)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleHotelAvailabilityGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(availabilityResJSON)
+}
+
+func readAdminHotelRequest(res http.ResponseWriter, req *http.Request, hotel *models.Hotel) bool {
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.readAdminHotelRequest. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	err = json.Unmarshal(reqBody, hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.readAdminHotelRequest. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	validErrRes, err := models.ValidateHotel(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.readAdminHotelRequest. Invalid hotel data:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return false
+	}
+
+	return true
+}
+
+func writeAdminHotelError(res http.ResponseWriter, err error) {
+	if errors.Is(err, serverrors.ErrUnauthorized) {
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if errors.Is(err, serverrors.ErrInvalidHotel) ||
+		errors.Is(err, serverrors.ErrInvalidHoteUid) ||
+		errors.Is(err, serverrors.ErrInvalidCancelPolicy) ||
+		errors.Is(err, serverrors.ErrInvalidRateRule) ||
+		errors.Is(err, serverrors.ErrInvalidExchangeRate) ||
+		errors.Is(err, serverrors.ErrInvalidTaxRule) {
+		res.WriteHeader(http.StatusBadRequest)
+	} else if errors.Is(err, serverrors.ErrEntityNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+	} else if errors.Is(err, serverrors.ErrHotelHasActiveReservs) {
+		errResJSON, _ := json.Marshal(models.ErrorResponse{Message: `Hotel has active reservations`})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusConflict)
+		res.Write(errResJSON)
+	} else if errors.Is(err, serverrors.ErrRequestSend) {
+		res.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		res.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (controller *GatewayController) handleAdminHotelPost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminHotelPost. Handling admin hotel POST request")
+
+	var hotel models.Hotel
+
+	if !readAdminHotelRequest(res, req, &hotel) {
+		return
+	}
+
+	newHotel, err := controller.service.CreateHotel(req.Header.Get(`Authorization`), &hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminHotelPost. service.CreateHotel returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	newHotelJSON, err := json.Marshal(newHotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminHotelPost. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusCreated)
+	res.Write(newHotelJSON)
+}
+
+func (controller *GatewayController) handleAdminHotelPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminHotelPut. Handling admin hotel PUT request")
+
+	var hotel models.Hotel
+
+	if !readAdminHotelRequest(res, req, &hotel) {
+		return
+	}
+
+	hotel.Uid = req.PathValue(`hotelUid`)
+	updatedHotel, err := controller.service.UpdateHotel(req.Header.Get(`Authorization`), &hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminHotelPut. service.UpdateHotel returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	updatedHotelJSON, err := json.Marshal(updatedHotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminHotelPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(updatedHotelJSON)
+}
+
+func (controller *GatewayController) handleAdminHotelDelete(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminHotelDelete. Handling admin hotel DELETE request")
+
+	err := controller.service.RetireHotel(req.Header.Get(`Authorization`), req.PathValue(`hotelUid`))
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminHotelDelete. service.RetireHotel returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
 
-			errResJSON, _ := json.Marshal(errRes)
+func (controller *GatewayController) handleAdminCancelPolicyPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminCancelPolicyPut. Handling admin cancellation policy PUT request")
 
-			res.Header().Add(`Content-Type`, `application/json`)
-			res.WriteHeader(http.StatusServiceUnavailable)
-			res.Write(errResJSON)
-			return
-		}
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var policy models.CancellationPolicy
+	err = json.Unmarshal(reqBody, &policy)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateCancellationPolicy(&policy)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyPut. Invalid cancellation policy:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	updatedPolicy, err := controller.service.UpdateCancelPolicy(
+		req.Header.Get(`Authorization`),
+		req.PathValue(`hotelUid`),
+		&policy,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyPut. service.UpdateCancelPolicy returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	updatedPolicyJSON, err := json.Marshal(updatedPolicy)
 
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyPut. Cannot convert result into JSON format: ", err)
 		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	loyaltyInfoResJSON, err := json.Marshal(loyaltyInfoRes)
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(updatedPolicyJSON)
+}
+
+func (controller *GatewayController) handleAdminCancelPolicyDelete(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminCancelPolicyDelete. Handling admin cancellation policy DELETE request")
+
+	err := controller.service.DeleteCancelPolicy(req.Header.Get(`Authorization`), req.PathValue(`hotelUid`))
 
 	if err != nil {
-		log.Println("[ERROR] GatewayController.handleLoyaltyGet. Cannot convert result into JSON format: ", err)
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyDelete. service.DeleteCancelPolicy returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (controller *GatewayController) handleAdminRateRulesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminRateRulesGet. Handling admin rate rules GET request")
+
+	rules, err := controller.service.ReadRateRules(req.Header.Get(`Authorization`), req.PathValue(`hotelUid`))
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesGet. service.ReadRateRules returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesGet. Cannot convert result into JSON format: ", err)
 		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	res.Header().Add(`Content-Type`, `application/json`)
 	res.WriteHeader(http.StatusOK)
-	res.Write(loyaltyInfoResJSON)
+	res.Write(rulesJSON)
+}
+
+func (controller *GatewayController) handleAdminRateRulesPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminRateRulesPut. Handling admin rate rules PUT request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var rules []models.RateRule
+	err = json.Unmarshal(reqBody, &rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateRateRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesPut. Invalid rate rules:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	newRules, err := controller.service.ReplaceRateRules(
+		req.Header.Get(`Authorization`),
+		req.PathValue(`hotelUid`),
+		rules,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesPut. service.ReplaceRateRules returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	newRulesJSON, err := json.Marshal(newRules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(newRulesJSON)
+}
+
+func (controller *GatewayController) handleExchangeRatesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleExchangeRatesGet. Handling exchange rates GET request")
+
+	rates, err := controller.service.ReadExchangeRates()
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleExchangeRatesGet. service.ReadExchangeRates returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	ratesJSON, err := json.Marshal(rates)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleExchangeRatesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(ratesJSON)
+}
+
+func (controller *GatewayController) handleAdminExchangeRatesPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminExchangeRatesPut. Handling admin exchange rates PUT request")
+
+	rates, ok := readExchangeRatesRequest(res, req)
+
+	if !ok {
+		return
+	}
+
+	newRates, err := controller.service.ReplaceExchangeRates(req.Header.Get(`Authorization`), rates)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminExchangeRatesPut. service.ReplaceExchangeRates returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	newRatesJSON, err := json.Marshal(newRates)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminExchangeRatesPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(newRatesJSON)
+}
+
+func (controller *GatewayController) handleAdminTaxRulesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminTaxRulesGet. Handling admin tax rules GET request")
+
+	rules, err := controller.service.ReadTaxRules(req.Header.Get(`Authorization`))
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesGet. service.ReadTaxRules returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(rulesJSON)
+}
+
+func (controller *GatewayController) handleAdminTaxRulesPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] GatewayController.handleAdminTaxRulesPut. Handling admin tax rules PUT request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var rules []models.TaxRule
+	err = json.Unmarshal(reqBody, &rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateTaxRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesPut. Invalid tax rules:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	newRules, err := controller.service.ReplaceTaxRules(req.Header.Get(`Authorization`), rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesPut. service.ReplaceTaxRules returned error: ", err)
+		writeAdminHotelError(res, err)
+		return
+	}
+
+	newRulesJSON, err := json.Marshal(newRules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(newRulesJSON)
+}
+
+func (controller *GatewayController) handleAdminHotelsRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `POST` {
+		log.Println("[INFO] GatewayController.handleAdminHotelsRequest. Got admin hotels POST request")
+		controller.handleAdminHotelPost(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleAdminHotelsRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleAdminHotelWithUidRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `PUT` {
+		log.Println("[INFO] GatewayController.handleAdminHotelWithUidRequest. Got admin hotel PUT request")
+		controller.handleAdminHotelPut(res, req)
+	} else if req.Method == `DELETE` {
+		log.Println("[INFO] GatewayController.handleAdminHotelWithUidRequest. Got admin hotel DELETE request")
+		controller.handleAdminHotelDelete(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleAdminHotelWithUidRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleAdminCancelPolicyRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `PUT` {
+		log.Println("[INFO] GatewayController.handleAdminCancelPolicyRequest. Got admin cancellation policy PUT request")
+		controller.handleAdminCancelPolicyPut(res, req)
+	} else if req.Method == `DELETE` {
+		log.Println("[INFO] GatewayController.handleAdminCancelPolicyRequest. Got admin cancellation policy DELETE request")
+		controller.handleAdminCancelPolicyDelete(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleAdminCancelPolicyRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleAdminRateRulesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleAdminRateRulesRequest. Got admin rate rules GET request")
+		controller.handleAdminRateRulesGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] GatewayController.handleAdminRateRulesRequest. Got admin rate rules PUT request")
+		controller.handleAdminRateRulesPut(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleAdminRateRulesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleExchangeRatesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleExchangeRatesRequest. Got exchange rates GET request")
+		controller.handleExchangeRatesGet(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleExchangeRatesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleAdminExchangeRatesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `PUT` {
+		log.Println("[INFO] GatewayController.handleAdminExchangeRatesRequest. Got admin exchange rates PUT request")
+		controller.handleAdminExchangeRatesPut(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleAdminExchangeRatesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleAdminTaxRulesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleAdminTaxRulesRequest. Got admin tax rules GET request")
+		controller.handleAdminTaxRulesGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] GatewayController.handleAdminTaxRulesRequest. Got admin tax rules PUT request")
+		controller.handleAdminTaxRulesPut(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleAdminTaxRulesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
 }
 
 func (controller *GatewayController) handleHotelsRequest(res http.ResponseWriter, req *http.Request) {
@@ -345,6 +1536,16 @@ func (controller *GatewayController) handleHotelsRequest(res http.ResponseWriter
 	}
 }
 
+func (controller *GatewayController) handleHotelAvailabilityRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleHotelAvailabilityRequest. Got hotel availability GET request")
+		controller.handleHotelAvailabilityGet(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleHotelAvailabilityRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *GatewayController) handleUserRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] GatewayController.handleUserRequest. Got user info GET request")
@@ -355,6 +1556,29 @@ func (controller *GatewayController) handleUserRequest(res http.ResponseWriter,
 	}
 }
 
+func (controller *GatewayController) handleCalendarTokenRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleCalendarTokenRequest. Got calendar token GET request")
+		controller.handleCalendarTokenGet(res, req)
+	} else if req.Method == `POST` {
+		log.Println("[INFO] GatewayController.handleCalendarTokenRequest. Got calendar token POST request")
+		controller.handleCalendarTokenPost(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleCalendarTokenRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *GatewayController) handleUserCalendarRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleUserCalendarRequest. Got user calendar GET request")
+		controller.handleUserCalendarGet(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleUserCalendarRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *GatewayController) handleReservationsRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] GatewayController.handleReservationsRequest. Got user reservations GET request")
@@ -368,10 +1592,23 @@ func (controller *GatewayController) handleReservationsRequest(res http.Response
 	}
 }
 
+func (controller *GatewayController) handleReservationQuoteRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `POST` {
+		log.Println("[INFO] GatewayController.handleReservationQuoteRequest. Got reservation quote POST request")
+		controller.handleReservationQuotePost(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleReservationQuoteRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *GatewayController) handleSingleReservationRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] GatewayController.handleSingleReservationRequest. Got single reservation GET request")
 		controller.handleSingleReservationGet(res, req)
+	} else if req.Method == `PATCH` {
+		log.Println("[INFO] GatewayController.handleSingleReservationRequest. Got reservation PATCH request")
+		controller.handleSingleReservationPatch(res, req)
 	} else if req.Method == `DELETE` {
 		log.Println("[INFO] GatewayController.handleSingleReservationRequest. Got reservation DELETE request")
 		controller.handleSingleReservationDelete(res, req)
@@ -381,6 +1618,16 @@ func (controller *GatewayController) handleSingleReservationRequest(res http.Res
 	}
 }
 
+func (controller *GatewayController) handleReservationReceiptRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] GatewayController.handleReservationReceiptRequest. Got reservation receipt GET request")
+		controller.handleReservationReceiptGet(res, req)
+	} else {
+		log.Println("[ERROR] GatewayController.handleReservationReceiptRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *GatewayController) handleLoyaltyRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] GatewayController.handleLoyaltyRequest. Got loyalty GET request")
@@ -403,9 +1650,21 @@ func (controller *GatewayController) handleHealthRequest(res http.ResponseWriter
 
 func (controller *GatewayController) Prepare() error {
 	http.HandleFunc(`/api/v1/hotels`, controller.handleHotelsRequest)
+	http.HandleFunc(`/api/v1/hotels/{hotelUid}/availability`, controller.handleHotelAvailabilityRequest)
+	http.HandleFunc(`/api/v1/admin/hotels`, controller.handleAdminHotelsRequest)
+	http.HandleFunc(`/api/v1/admin/hotels/{hotelUid}`, controller.handleAdminHotelWithUidRequest)
+	http.HandleFunc(`/api/v1/admin/hotels/{hotelUid}/cancellation-policy`, controller.handleAdminCancelPolicyRequest)
+	http.HandleFunc(`/api/v1/admin/hotels/{hotelUid}/rate-rules`, controller.handleAdminRateRulesRequest)
+	http.HandleFunc(`/api/v1/admin/exchange-rates`, controller.handleAdminExchangeRatesRequest)
+	http.HandleFunc(`/api/v1/admin/tax-rules`, controller.handleAdminTaxRulesRequest)
+	http.HandleFunc(`/api/v1/exchange-rates`, controller.handleExchangeRatesRequest)
 	http.HandleFunc(`/api/v1/me`, controller.handleUserRequest)
+	http.HandleFunc(`/api/v1/me/calendar-token`, controller.handleCalendarTokenRequest)
+	http.HandleFunc(`/api/v1/me/reservations.ics`, controller.handleUserCalendarRequest)
 	http.HandleFunc(`/api/v1/reservations`, controller.handleReservationsRequest)
+	http.HandleFunc(`/api/v1/reservations/quote`, controller.handleReservationQuoteRequest)
 	http.HandleFunc(`/api/v1/reservations/{reservationUid}`, controller.handleSingleReservationRequest)
+	http.HandleFunc(`/api/v1/reservations/{reservationUid}/receipt`, controller.handleReservationReceiptRequest)
 	http.HandleFunc(`/api/v1/loyalty`, controller.handleLoyaltyRequest)
 
 	http.HandleFunc(`/manage/health`, controller.handleHealthRequest)
@@ -20,14 +20,17 @@ type LoyaltyController struct {
 	port int
 
 	service services.ILoyaltyService
+
+	adminToken string
 }
 
 func NewLoyaltyController(
 	host string,
 	port int,
 	service services.ILoyaltyService,
+	adminToken string,
 ) IController {
-	return &LoyaltyController{host, port, service}
+	return &LoyaltyController{host, port, service, adminToken}
 }
 
 func (controller *LoyaltyController) handleLoyaltyByUsernameGet(res http.ResponseWriter, req *http.Request) {
@@ -67,6 +70,30 @@ func (controller *LoyaltyController) handleLoyaltyByUsernameGet(res http.Respons
 	res.Write(loyaltyJSON)
 }
 
+func (controller *LoyaltyController) handleLoyaltiesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] LoyaltyController.handleLoyaltiesGet. Handling loyalties GET request")
+
+	loyalties, err := controller.service.ReadAllLoyalties()
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltiesGet. service.ReadAllLoyalties returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	loyaltiesJSON, err := json.Marshal(loyalties)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltiesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(loyaltiesJSON)
+}
+
 func (controller *LoyaltyController) handleLoyaltyByUsernamePatch(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] LoyaltyController.handleLoyaltyByUsernameGet. Handling loyalty by username GET request")
 
@@ -86,12 +113,14 @@ func (controller *LoyaltyController) handleLoyaltyByUsernamePatch(res http.Respo
 		return
 	}
 
-	err = controller.service.ChangeLoyaltyCountByUsername(username, delta)
+	err = controller.service.ChangeLoyaltyCountByUsername(username, req.Header.Get(`Reservation-Uid`), delta)
 
 	if err != nil {
 		log.Println("[ERROR] LoyaltyController.handleLoyaltyByUsernameGet. service.ReadLoyaltyByUsername returned error: ", err)
 		if errors.Is(err, serverrors.ErrEntityNotFound) {
 			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrInvalidReservUid) || errors.Is(err, serverrors.ErrInvalidLoyaltyCount) {
+			res.WriteHeader(http.StatusBadRequest)
 		} else {
 			res.WriteHeader(http.StatusInternalServerError)
 		}
@@ -149,6 +178,91 @@ func (controller *LoyaltyController) handleLoyaltyByIdPut(res http.ResponseWrite
 	res.WriteHeader(http.StatusNoContent)
 }
 
+func (controller *LoyaltyController) handleDowngrades(res http.ResponseWriter, req *http.Request, dryRun bool) {
+	log.Println("[INFO] LoyaltyController.handleDowngrades. Handling loyalty downgrades request, dryRun =", dryRun)
+
+	changes, err := controller.service.ReviewLoyaltyTiers(dryRun)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleDowngrades. service.ReviewLoyaltyTiers returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	changesJSON, err := json.Marshal(changes)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleDowngrades. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(changesJSON)
+}
+
+func (controller *LoyaltyController) handleLoyaltyCountPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] LoyaltyController.handleLoyaltyCountPut. Handling loyalty reservation count PUT request")
+
+	username := req.PathValue(`username`)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountPut. Invalid username: " + username)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var correction models.LoyaltyCountCorrection
+	err = json.Unmarshal(reqBody, &correction)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	loyalty, err := controller.service.CorrectLoyaltyCount(username, &correction)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountPut. service.CorrectLoyaltyCount returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrInvalidLoyaltyCount) {
+			res.WriteHeader(http.StatusBadRequest)
+		} else if errors.Is(err, serverrors.ErrLoyaltyCountChanged) {
+			res.WriteHeader(http.StatusConflict)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	loyaltyJSON, err := json.Marshal(loyalty)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(loyaltyJSON)
+}
+
 func (controller *LoyaltyController) handleLoyaltyRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] LoyaltyController.handleLoyaltyByIdRequest. Got loyalty by username GET request")
@@ -162,6 +276,22 @@ func (controller *LoyaltyController) handleLoyaltyRequest(res http.ResponseWrite
 	}
 }
 
+func (controller *LoyaltyController) handleLoyaltiesRequest(res http.ResponseWriter, req *http.Request) {
+	if !hasAdminToken(req, controller.adminToken) {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltiesRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if req.Method == `GET` {
+		log.Println("[INFO] LoyaltyController.handleLoyaltiesRequest. Got loyalties GET request")
+		controller.handleLoyaltiesGet(res, req)
+	} else {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltiesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *LoyaltyController) handleLoyaltyByIdRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `PUT` {
 		log.Println("[INFO] LoyaltyController.handleLoyaltyByIdRequest. Got loyalty by id PUT request")
@@ -172,6 +302,41 @@ func (controller *LoyaltyController) handleLoyaltyByIdRequest(res http.ResponseW
 	}
 }
 
+func (controller *LoyaltyController) handleDowngradesRequest(res http.ResponseWriter, req *http.Request) {
+	if !hasAdminToken(req, controller.adminToken) {
+		log.Println("[ERROR] LoyaltyController.handleDowngradesRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if req.Method == `GET` {
+		log.Println("[INFO] LoyaltyController.handleDowngradesRequest. Got loyalty downgrades GET request")
+		controller.handleDowngrades(res, req, true)
+	} else if req.Method == `POST` {
+		log.Println("[INFO] LoyaltyController.handleDowngradesRequest. Got loyalty downgrades POST request")
+		controller.handleDowngrades(res, req, false)
+	} else {
+		log.Println("[ERROR] LoyaltyController.handleDowngradesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *LoyaltyController) handleLoyaltyCountRequest(res http.ResponseWriter, req *http.Request) {
+	if !hasAdminToken(req, controller.adminToken) {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if req.Method == `PUT` {
+		log.Println("[INFO] LoyaltyController.handleLoyaltyCountRequest. Got loyalty reservation count PUT request")
+		controller.handleLoyaltyCountPut(res, req)
+	} else {
+		log.Println("[ERROR] LoyaltyController.handleLoyaltyCountRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *LoyaltyController) handleHealthRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] LoyaltyController.handleHealthRequest. Got health GET request")
@@ -184,7 +349,10 @@ func (controller *LoyaltyController) handleHealthRequest(res http.ResponseWriter
 
 func (controller *LoyaltyController) Prepare() error {
 	http.HandleFunc(`/api/v1/loyalty`, controller.handleLoyaltyRequest)
+	http.HandleFunc(`/api/v1/loyalties`, controller.handleLoyaltiesRequest)
+	http.HandleFunc(`/api/v1/loyalties/{username}/reservation-count`, controller.handleLoyaltyCountRequest)
 	http.HandleFunc(`/api/v1/loyalty/{loyaltyId}`, controller.handleLoyaltyByIdRequest)
+	http.HandleFunc(`/api/v1/loyalty/downgrades`, controller.handleDowngradesRequest)
 
 	http.HandleFunc(`/manage/health`, controller.handleHealthRequest)
 
@@ -21,33 +21,77 @@ type PaymentController struct {
 	port int
 
 	service services.IPaymentService
+
+	adminToken string
 }
 
 func NewPaymentController(
 	host string,
 	port int,
 	service services.IPaymentService,
+	adminToken string,
 ) IController {
-	return &PaymentController{host, port, service}
+	return &PaymentController{host, port, service, adminToken}
 }
 
-func (controller *PaymentController) handlePaymentByPricePost(res http.ResponseWriter, req *http.Request) {
-	log.Println("[INFO] PaymentController.handlePaymentByPricePost. Handling payment by price POST request")
+func (controller *PaymentController) handleNewPaymentPost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handleNewPaymentPost. Handling new payment POST request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleNewPaymentPost. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var crPaymentReq models.CreatePaymentRequest
+	err = json.Unmarshal(reqBody, &crPaymentReq)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleNewPaymentPost. Error while parsing JSON request body: ", err)
+		validErrResJSON, _ := json.Marshal(models.ValidationErrorResponse{
+			Message: `invalid payment request data`,
+			Errors:  []models.ErrorDiscription{{Field: `body`, Error: `invalid JSON`}},
+		})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	validErrRes, err := models.ValidateCrPaymentReq(&crPaymentReq)
 
-	price, err := strconv.Atoi(req.Header.Get(`Price`))
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleNewPaymentPost. Invalid payment request:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
 
-	if err != nil || price <= 0 {
-		log.Println("[ERROR] PaymentController.handlePaymentByPricePost. Ivalid price value:", err)
+		res.Header().Add(`Content-Type`, `application/json`)
 		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
 		return
 	}
 
-	payment := models.Payment{Uid: uuid.New().String(), Status: `PAID`, Price: price}
+	payment := models.Payment{Uid: uuid.New().String()}
+	models.CrPaymentReqToPayment(&payment, &crPaymentReq)
 
 	newPayment, err := controller.service.CreatePayment(&payment)
 
 	if err != nil {
-		log.Println("[ERROR] PaymentController.handlePaymentByPricePost. service.CreatePayment returned error: ", err)
+		log.Println("[ERROR] PaymentController.handleNewPaymentPost. service.CreatePayment returned error: ", err)
+
+		if errors.Is(err, serverrors.ErrIdempotencyKeyReused) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusConflict)
+			res.Write(errResJSON)
+			return
+		}
+
 		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -55,7 +99,7 @@ func (controller *PaymentController) handlePaymentByPricePost(res http.ResponseW
 	newPaymentJSON, err := json.Marshal(newPayment)
 
 	if err != nil {
-		log.Println("[ERROR] PaymentController.handlePaymentByPricePost. Cannot convert result into JSON format: ", err)
+		log.Println("[ERROR] PaymentController.handleNewPaymentPost. Cannot convert result into JSON format: ", err)
 		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -65,6 +109,30 @@ func (controller *PaymentController) handlePaymentByPricePost(res http.ResponseW
 	res.Write(newPaymentJSON)
 }
 
+func (controller *PaymentController) handlePaymentsGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handlePaymentsGet. Handling payments GET request")
+
+	payments, err := controller.service.ReadAllPayments()
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentsGet. service.ReadAllPayments returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	paymentsJSON, err := json.Marshal(payments)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentsGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(paymentsJSON)
+}
+
 func (controller *PaymentController) handlePaymentByUidGet(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] PaymentController.handlePaymentByUidGet. Handling payment by uid GET request")
 
@@ -102,41 +170,128 @@ func (controller *PaymentController) handlePaymentByUidGet(res http.ResponseWrit
 	res.Write(paymentJSON)
 }
 
-func (controller *PaymentController) handlePaymentByUidPut(res http.ResponseWriter, req *http.Request) {
-	log.Println("[INFO] PaymentController.handlePaymentByUidPut. Handling payment by uid PUT request")
+func writePaymentOperationError(res http.ResponseWriter, err error) {
+	if errors.Is(err, serverrors.ErrEntityNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+	} else if errors.Is(err, serverrors.ErrInvalidStatusTransition) || errors.Is(err, serverrors.ErrIdempotencyKeyReused) ||
+		errors.Is(err, serverrors.ErrPaymentOperationPending) {
+		errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusConflict)
+		res.Write(errResJSON)
+	} else if errors.Is(err, serverrors.ErrPaymentDeclined) {
+		errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusPaymentRequired)
+		res.Write(errResJSON)
+	} else if errors.Is(err, serverrors.ErrProviderTimeout) {
+		res.WriteHeader(http.StatusGatewayTimeout)
+	} else if errors.Is(err, serverrors.ErrInvalidPaymentAmount) {
+		res.WriteHeader(http.StatusBadRequest)
+	} else {
+		res.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// The amount is passed in a header, so that failed operations can be replayed without a body.
+// A missing header means the default amount of the operation.
+func readPaymentAmount(req *http.Request) (int, error) {
+	amountStr := strings.Trim(req.Header.Get(`Amount`), ` `)
+
+	if amountStr == `` {
+		return 0, nil
+	}
+
+	amount, err := strconv.Atoi(amountStr)
+
+	if err != nil || amount < 0 {
+		return 0, serverrors.ErrInvalidPaymentAmount
+	}
+
+	return amount, nil
+}
+
+func (controller *PaymentController) handlePaymentOperationPost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handlePaymentOperationPost. Handling payment operation POST request")
 
 	paymentUid := req.PathValue("paymentUid")
+	operation := req.PathValue("operation")
 
 	if strings.Trim(paymentUid, ` `) == `` {
-		log.Println("[ERROR] PaymentController.handlePaymentByUidPut. Invalid payment uid")
+		log.Println("[ERROR] PaymentController.handlePaymentOperationPost. Invalid payment uid")
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	defer req.Body.Close()
-
-	reqBody, err := io.ReadAll(req.Body)
+	amount, err := readPaymentAmount(req)
 
 	if err != nil {
-		log.Println("[ERROR] PaymentController.handlePaymentByUidPut. Error while reading request body: ", err)
+		log.Println("[ERROR] PaymentController.handlePaymentOperationPost. Invalid amount value:", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Failed operations are replayed, so a repeated key returns the result of
+	// the first request instead of charging or refunding again.
+	idempotencyKey := req.Header.Get(`Idempotency-Key`)
+
+	if idempotencyKey != `` && !models.IsValidIdempotencyKey(idempotencyKey) {
+		log.Println("[ERROR] PaymentController.handlePaymentOperationPost. Invalid idempotency key")
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	var payment models.Payment
-	err = json.Unmarshal(reqBody, &payment)
+
+	switch operation {
+	case `capture`:
+		payment, err = controller.service.CapturePayment(paymentUid, amount, idempotencyKey)
+	case `void`:
+		payment, err = controller.service.VoidPayment(paymentUid, idempotencyKey)
+	case `refund`:
+		payment, err = controller.service.RefundPayment(paymentUid, amount, idempotencyKey)
+	default:
+		log.Println("[ERROR] PaymentController.handlePaymentOperationPost. Unknown operation:", operation)
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
 
 	if err != nil {
-		log.Println("[ERROR] PaymentController.handlePaymentByUidPut. Error while parsing JSON request body: ", err)
+		log.Println("[ERROR] PaymentController.handlePaymentOperationPost. service returned error:", err)
+		writePaymentOperationError(res, err)
+		return
+	}
+
+	paymentJSON, err := json.Marshal(payment)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentOperationPost. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(paymentJSON)
+}
+
+func (controller *PaymentController) handlePaymentTransactionsGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handlePaymentTransactionsGet. Handling payment transactions GET request")
+
+	paymentUid := req.PathValue("paymentUid")
+
+	if strings.Trim(paymentUid, ` `) == `` {
+		log.Println("[ERROR] PaymentController.handlePaymentTransactionsGet. Invalid payment uid")
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	payment.Uid = paymentUid
-	_, err = controller.service.UpdatePaymentByUid(&payment)
+	transactions, err := controller.service.ReadPaymentTransactions(paymentUid)
 
 	if err != nil {
-		log.Println("[ERROR] PaymentController.handlePaymentByUidPut. service.UpdatePaymentByUid returned error: ", err)
+		log.Println("[ERROR] PaymentController.handlePaymentTransactionsGet. service.ReadPaymentTransactions returned error: ", err)
 		if errors.Is(err, serverrors.ErrEntityNotFound) {
 			res.WriteHeader(http.StatusNotFound)
 		} else {
@@ -146,18 +301,237 @@ func (controller *PaymentController) handlePaymentByUidPut(res http.ResponseWrit
 		return
 	}
 
-	res.WriteHeader(http.StatusNoContent)
+	transactionsJSON, err := json.Marshal(transactions)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentTransactionsGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(transactionsJSON)
+}
+
+func (controller *PaymentController) writeInvoice(res http.ResponseWriter, invoice *models.Invoice) {
+	invoiceJSON, err := json.Marshal(invoice)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.writeInvoice. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(invoiceJSON)
+}
+
+func (controller *PaymentController) handlePaymentInvoiceGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handlePaymentInvoiceGet. Handling payment invoice GET request")
+
+	paymentUid := req.PathValue("paymentUid")
+
+	if uuid.Validate(paymentUid) != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentInvoiceGet. Invalid payment uid")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := controller.service.ReadInvoice(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentInvoiceGet. service.ReadInvoice returned error: ", err)
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	controller.writeInvoice(res, &invoice)
+}
+
+func (controller *PaymentController) handlePaymentInvoicePut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handlePaymentInvoicePut. Handling payment invoice PUT request")
+
+	paymentUid := req.PathValue("paymentUid")
+
+	if uuid.Validate(paymentUid) != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentInvoicePut. Invalid payment uid")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := controller.service.IssueInvoice(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentInvoicePut. service.IssueInvoice returned error: ", err)
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrPaymentNotCharged) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusConflict)
+			res.Write(errResJSON)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	controller.writeInvoice(res, &invoice)
+}
+
+func (controller *PaymentController) handleProviderStatusGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handleProviderStatusGet. Handling provider status GET request")
+
+	paymentUid := req.PathValue("paymentUid")
+
+	if strings.Trim(paymentUid, ` `) == `` {
+		log.Println("[ERROR] PaymentController.handleProviderStatusGet. Invalid payment uid")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	status, err := controller.service.ReadProviderStatus(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleProviderStatusGet. service.ReadProviderStatus returned error: ", err)
+		writePaymentOperationError(res, err)
+		return
+	}
+
+	statusJSON, err := json.Marshal(models.ProviderStatusResponse{PaymentUid: paymentUid, Status: status})
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleProviderStatusGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(statusJSON)
+}
+
+func (controller *PaymentController) handlePaymentLedgerGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handlePaymentLedgerGet. Handling payment ledger GET request")
+
+	paymentUid := req.PathValue("paymentUid")
+
+	if strings.Trim(paymentUid, ` `) == `` {
+		log.Println("[ERROR] PaymentController.handlePaymentLedgerGet. Invalid payment uid")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entries, err := controller.service.ReadLedgerEntries(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentLedgerGet. service.ReadLedgerEntries returned error: ", err)
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	entriesJSON, err := json.Marshal(entries)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handlePaymentLedgerGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(entriesJSON)
+}
+
+func (controller *PaymentController) handleLedgerBalancesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handleLedgerBalancesGet. Handling ledger balances GET request")
+
+	paymentUid := strings.Trim(req.URL.Query().Get(`paymentUid`), ` `)
+
+	if paymentUid != `` && uuid.Validate(paymentUid) != nil {
+		log.Println("[ERROR] PaymentController.handleLedgerBalancesGet. Invalid payment uid")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	balances, err := controller.service.ReadLedgerBalances(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleLedgerBalancesGet. service.ReadLedgerBalances returned error: ", err)
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	balancesJSON, err := json.Marshal(balances)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleLedgerBalancesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(balancesJSON)
+}
+
+func (controller *PaymentController) handleLedgerCheckGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] PaymentController.handleLedgerCheckGet. Handling ledger check GET request")
+
+	checkRes, err := controller.service.CheckLedger()
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleLedgerCheckGet. service.CheckLedger returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	checkResJSON, err := json.Marshal(checkRes)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentController.handleLedgerCheckGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(checkResJSON)
 }
 
 func (controller *PaymentController) handlePaymentRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `POST` {
-		if strings.Trim(req.Header.Get(`Price`), ` `) != `` {
-			log.Println("[INFO] PaymentController.handlePaymentByUidRequest. Got payment by price POST request")
-			controller.handlePaymentByPricePost(res, req)
-		} else {
-			log.Println("[ERROR] PaymentController.handlePaymentRequest. Invalid request")
-			res.WriteHeader(http.StatusBadRequest)
+		log.Println("[INFO] PaymentController.handlePaymentRequest. Got new payment POST request")
+		controller.handleNewPaymentPost(res, req)
+	} else if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handlePaymentRequest. Got payments GET request")
+
+		if !hasAdminToken(req, controller.adminToken) {
+			log.Println("[ERROR] PaymentController.handlePaymentRequest. Unauthorized")
+			res.WriteHeader(http.StatusUnauthorized)
+			return
 		}
+
+		controller.handlePaymentsGet(res, req)
 	} else {
 		log.Println("[ERROR] PaymentController.handlePaymentRequest. Method not allowed")
 		res.WriteHeader(http.StatusMethodNotAllowed)
@@ -168,15 +542,85 @@ func (controller *PaymentController) handlePaymentByUidRequest(res http.Response
 	if req.Method == `GET` {
 		log.Println("[INFO] PaymentController.handlePaymentByUidRequest. Got payment by uid GET request")
 		controller.handlePaymentByUidGet(res, req)
-	} else if req.Method == `PUT` {
-		log.Println("[INFO] PaymentController.handlePaymentByUidRequest. Got payment by uid PUT request")
-		controller.handlePaymentByUidPut(res, req)
 	} else {
 		log.Println("[ERROR] PaymentController.handlePaymentByUidRequest. Method not allowed")
 		res.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
 
+func (controller *PaymentController) handlePaymentOperationRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `POST` {
+		log.Println("[INFO] PaymentController.handlePaymentOperationRequest. Got payment operation POST request")
+		controller.handlePaymentOperationPost(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handlePaymentOperationRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *PaymentController) handlePaymentTransactionsRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handlePaymentTransactionsRequest. Got payment transactions GET request")
+		controller.handlePaymentTransactionsGet(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handlePaymentTransactionsRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *PaymentController) handlePaymentInvoiceRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handlePaymentInvoiceRequest. Got payment invoice GET request")
+		controller.handlePaymentInvoiceGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] PaymentController.handlePaymentInvoiceRequest. Got payment invoice PUT request")
+		controller.handlePaymentInvoicePut(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handlePaymentInvoiceRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *PaymentController) handleProviderStatusRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handleProviderStatusRequest. Got provider status GET request")
+		controller.handleProviderStatusGet(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handleProviderStatusRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *PaymentController) handlePaymentLedgerRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handlePaymentLedgerRequest. Got payment ledger GET request")
+		controller.handlePaymentLedgerGet(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handlePaymentLedgerRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *PaymentController) handleLedgerBalancesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handleLedgerBalancesRequest. Got ledger balances GET request")
+		controller.handleLedgerBalancesGet(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handleLedgerBalancesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *PaymentController) handleLedgerCheckRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] PaymentController.handleLedgerCheckRequest. Got ledger check GET request")
+		controller.handleLedgerCheckGet(res, req)
+	} else {
+		log.Println("[ERROR] PaymentController.handleLedgerCheckRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (controller *PaymentController) handleHealthRequest(res http.ResponseWriter, req *http.Request) {
 	if req.Method == `GET` {
 		log.Println("[INFO] PaymentController.handleHealthRequest. Got health GET request")
@@ -190,6 +634,14 @@ func (controller *PaymentController) handleHealthRequest(res http.ResponseWriter
 func (controller *PaymentController) Prepare() error {
 	http.HandleFunc(`/api/v1/payment`, controller.handlePaymentRequest)
 	http.HandleFunc(`/api/v1/payment/{paymentUid}`, controller.handlePaymentByUidRequest)
+	http.HandleFunc(`/api/v1/payment/{paymentUid}/transactions`, controller.handlePaymentTransactionsRequest)
+	http.HandleFunc(`/api/v1/payment/{paymentUid}/provider-status`, controller.handleProviderStatusRequest)
+	http.HandleFunc(`/api/v1/payment/{paymentUid}/ledger`, controller.handlePaymentLedgerRequest)
+	http.HandleFunc(`/api/v1/payment/{paymentUid}/invoice`, controller.handlePaymentInvoiceRequest)
+
+	http.HandleFunc(`/api/v1/ledger/balances`, controller.handleLedgerBalancesRequest)
+	http.HandleFunc(`/api/v1/ledger/check`, controller.handleLedgerCheckRequest)
+	http.HandleFunc(`/api/v1/payment/{paymentUid}/{operation}`, controller.handlePaymentOperationRequest)
 
 	http.HandleFunc(`/manage/health`, controller.handleHealthRequest)
 
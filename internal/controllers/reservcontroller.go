@@ -13,6 +13,7 @@ import (
 	"github.com/agarmirus/ds-lab02/internal/models"
 	"github.com/agarmirus/ds-lab02/internal/serverrors"
 	"github.com/agarmirus/ds-lab02/internal/services"
+	"github.com/google/uuid"
 )
 
 type ReservationController struct {
@@ -20,51 +21,80 @@ type ReservationController struct {
 	port int
 
 	service services.IReservationService
+
+	adminToken string
 }
 
 func NewReservationController(
 	host string,
 	port int,
 	service services.IReservationService,
+	adminToken string,
 ) IController {
-	return &ReservationController{host, port, service}
+	return &ReservationController{host, port, service, adminToken}
 }
 
 func (controller *ReservationController) handleAllHotelsGet(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] ReservationController.handleAllHotelsGet. Handling hotels GET request")
 
+	cursorStr := req.FormValue(`cursor`)
+	_, cursorMode := req.URL.Query()[`cursor`]
+
 	page, pageParseErr := strconv.Atoi(req.FormValue(`page`))
 	pageSize, pageSizeParseErr := strconv.Atoi(req.FormValue(`size`))
 
+	if cursorMode {
+		page, pageParseErr = 1, nil
+	}
+
 	if pageParseErr != nil || pageSizeParseErr != nil || page <= 0 || pageSize <= 0 {
 		log.Println("[ERROR] ReservationController.handleAllHotelsGet. Invalid URL parameters")
 		res.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	hotelsLst, err := controller.service.ReadPaginatedHotels(page, pageSize)
+	filter, validErrRes, err := models.ParseHotelFilter(req.URL.Query())
 
 	if err != nil {
-		log.Println("[ERROR] ReservationController.handleAllHotelsGet. service.ReadPaginatedHotels returned error: ", err)
-		res.WriteHeader(http.StatusInternalServerError)
+		log.Println("[ERROR] ReservationController.handleAllHotelsGet. Invalid hotels filter:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
 		return
 	}
 
-	if hotelsLst.Len() == 0 {
-		log.Println("[INFO] ReservationController.handleAllHotelsGet. Entities not found. Sending No Content code")
-		res.WriteHeader(http.StatusNotFound)
-		return
+	var hotelsPage models.HotelsPage
+
+	if cursorMode {
+		hotelsPage, err = controller.service.ReadHotelsAfterCursor(&filter, cursorStr, pageSize)
+	} else {
+		hotelsPage, err = controller.service.ReadPaginatedHotels(&filter, page, pageSize)
 	}
 
-	hotelsSlice := make([]models.Hotel, 0)
-	hotelsLstEl := hotelsLst.Front()
+	if errors.Is(err, serverrors.ErrInvalidCursor) {
+		log.Println("[ERROR] ReservationController.handleAllHotelsGet. Invalid cursor:", err)
+		validErrRes := models.ValidationErrorResponse{
+			Message: `invalid hotels filter`,
+			Errors:  []models.ErrorDiscription{{Field: `cursor`, Error: err.Error()}},
+		}
+
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
 
-	for hotelsLstEl != nil {
-		hotelsSlice = append(hotelsSlice, hotelsLstEl.Value.(models.Hotel))
-		hotelsLstEl = hotelsLstEl.Next()
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAllHotelsGet. Reading hotels page returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
-	hotelsSliceJSON, err := json.Marshal(hotelsSlice)
+	hotelsPageJSON, err := json.Marshal(hotelsPage)
 
 	if err != nil {
 		log.Println("[ERROR] ReservationController.handleAllHotelsGet. Cannot convert result into JSON format: ", err)
@@ -72,11 +102,9 @@ func (controller *ReservationController) handleAllHotelsGet(res http.ResponseWri
 		return
 	}
 
-	log.Println("[INFO] hotelsSliceJSON", string(hotelsSliceJSON), page, pageSize)
-
 	res.Header().Add(`Content-Type`, `application/json`)
 	res.WriteHeader(http.StatusOK)
-	res.Write(hotelsSliceJSON)
+	res.Write(hotelsPageJSON)
 }
 
 func (controller *ReservationController) handleHotelByIdGet(res http.ResponseWriter, req *http.Request) {
@@ -153,6 +181,102 @@ func (controller *ReservationController) handleHotelByUidGet(res http.ResponseWr
 	res.Write(hotelJSON)
 }
 
+func (controller *ReservationController) handleHotelAvailabilityGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleHotelAvailabilityGet. Handling hotel availability GET request")
+
+	hotelUid := req.PathValue("hotelUid")
+	from := req.FormValue(`from`)
+	to := req.FormValue(`to`)
+
+	validErrRes, err := models.ValidateAvailabilityRange(from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelAvailabilityGet. Invalid availability range:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	availabilityRes, err := controller.service.ReadHotelAvailability(hotelUid, from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelAvailabilityGet. service.ReadHotelAvailability returned error: ", err)
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	availabilityResJSON, err := json.Marshal(availabilityRes)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelAvailabilityGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(availabilityResJSON)
+}
+
+func (controller *ReservationController) handleHotelPricesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleHotelPricesGet. Handling hotel nightly prices GET request")
+
+	hotelUid := req.PathValue("hotelUid")
+	from := req.FormValue(`from`)
+	to := req.FormValue(`to`)
+
+	validErrRes, err := models.ValidateAvailabilityRange(from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelPricesGet. Invalid dates range:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	priceRes, err := controller.service.ReadNightlyPrices(hotelUid, from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelPricesGet. service.ReadNightlyPrices returned error: ", err)
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrMinStayNotMet) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusUnprocessableEntity)
+			res.Write(errResJSON)
+		} else {
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	priceResJSON, err := json.Marshal(priceRes)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelPricesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(priceResJSON)
+}
+
 func (controller *ReservationController) handleReservsByUsernameGet(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] ReservationController.handleReservsByUsernameGet. Handling reservations by username GET request")
 
@@ -198,6 +322,36 @@ func (controller *ReservationController) handleReservsByUsernameGet(res http.Res
 	res.Write(reservsSliceJSON)
 }
 
+func (controller *ReservationController) handleAdminReservsGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminReservsGet. Handling admin reservations GET request")
+
+	reservsLst, err := controller.service.ReadAllReservs()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminReservsGet. service.ReadAllReservs returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	reservsSlice := make([]models.Reservation, 0)
+
+	for reservsLstEl := reservsLst.Front(); reservsLstEl != nil; reservsLstEl = reservsLstEl.Next() {
+		reservsSlice = append(reservsSlice, reservsLstEl.Value.(models.Reservation))
+	}
+
+	reservsSliceJSON, err := json.Marshal(reservsSlice)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminReservsGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(reservsSliceJSON)
+}
+
 func (controller *ReservationController) handleReservByUidGet(res http.ResponseWriter, req *http.Request) {
 	log.Println("[INFO] ReservationController.handleReservByUidGet. Handling reservation by uid GET request")
 
@@ -272,6 +426,16 @@ func (controller *ReservationController) handleReservByUidPut(res http.ResponseW
 		log.Println("[ERROR] ReservationController.handleReservByUidPut. service.UpdateReservByUid returned error: ", err)
 		if errors.Is(err, serverrors.ErrEntityNotFound) {
 			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrNoRoomsAvailable) ||
+			errors.Is(err, serverrors.ErrInvalidStatusTransition) ||
+			errors.Is(err, serverrors.ErrHoldExpired) {
+			errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+			res.Header().Add(`Content-Type`, `application/json`)
+			res.WriteHeader(http.StatusConflict)
+			res.Write(errResJSON)
+		} else if errors.Is(err, serverrors.ErrInvalidReservStatus) {
+			res.WriteHeader(http.StatusBadRequest)
 		} else {
 			res.WriteHeader(http.StatusInternalServerError)
 		}
@@ -306,8 +470,12 @@ func (controller *ReservationController) handleReservPost(res http.ResponseWrite
 
 	if err != nil {
 		log.Println("[ERROR] ReservationController.handleReservPost. service.CreateReserv returned error: ", err)
-		if errors.Is(err, serverrors.ErrEntityNotFound) {
+		if errors.Is(err, serverrors.ErrEntityNotFound) || errors.Is(err, serverrors.ErrHotelNotFound) {
 			res.WriteHeader(http.StatusNotFound)
+		} else if errors.Is(err, serverrors.ErrNoRoomsAvailable) {
+			res.WriteHeader(http.StatusConflict)
+		} else if errors.Is(err, serverrors.ErrInvalidReservStatus) {
+			res.WriteHeader(http.StatusBadRequest)
 		} else {
 			res.WriteHeader(http.StatusInternalServerError)
 		}
@@ -328,58 +496,995 @@ func (controller *ReservationController) handleReservPost(res http.ResponseWrite
 	res.Write(newReservationJSON)
 }
 
-func (controller *ReservationController) handleHotelsRequest(res http.ResponseWriter, req *http.Request) {
-	if req.Method == `GET` {
-		if strings.Trim(req.Header.Get(`Hotel-Id`), ` `) == `` {
-			log.Println("[INFO] ReservationController.handleHotelsRequest. Got hotels GET request")
-			controller.handleAllHotelsGet(res, req)
+func (controller *ReservationController) handlePromoCodePost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handlePromoCodePost. Handling promo code POST request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodePost. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var promoCode models.PromoCode
+	err = json.Unmarshal(reqBody, &promoCode)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodePost. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	newPromoCode, err := controller.service.CreatePromoCode(&promoCode)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodePost. service.CreatePromoCode returned error: ", err)
+		if errors.Is(err, serverrors.ErrInvalidPromoCode) {
+			res.WriteHeader(http.StatusBadRequest)
 		} else {
-			log.Println("[INFO] ReservationController.handleHotelsRequest. Got hotel by id GET request")
-			controller.handleHotelByIdGet(res, req)
+			res.WriteHeader(http.StatusInternalServerError)
 		}
-	} else {
-		log.Println("[ERROR] ReservationController.handleHotelsRequest. Method not allowed")
-		res.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	newPromoCodeJSON, err := json.Marshal(&newPromoCode)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodePost. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusCreated)
+	res.Write(newPromoCodeJSON)
 }
 
-func (controller *ReservationController) handleHotelWithUidRequest(res http.ResponseWriter, req *http.Request) {
-	if req.Method == `GET` {
-		log.Println("[INFO] ReservationController.handleHotelWithUidRequest. Got hotel with uid GET request")
-		controller.handleHotelByUidGet(res, req)
-	} else {
-		log.Println("[ERROR] ReservationController.handleHotelWithUidRequest. Method not allowed")
-		res.WriteHeader(http.StatusMethodNotAllowed)
+func (controller *ReservationController) isAdminRequest(req *http.Request) bool {
+	return hasAdminToken(req, controller.adminToken)
+}
+
+func readHotelRequest(res http.ResponseWriter, req *http.Request, hotel *models.Hotel) bool {
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.readHotelRequest. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	err = json.Unmarshal(reqBody, hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.readHotelRequest. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return false
 	}
+
+	validErrRes, err := models.ValidateHotel(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.readHotelRequest. Invalid hotel data:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return false
+	}
+
+	return true
 }
 
-func (controller *ReservationController) handleReservsRequest(res http.ResponseWriter, req *http.Request) {
-	if req.Method == `GET` {
-		if strings.Trim(req.Header.Get(`X-User-Name`), ` `) != `` {
-			log.Println("[INFO] ReservationController.handleReservsRequest. Got reservation GET request")
-			controller.handleReservsByUsernameGet(res, req)
-		} else {
-			log.Println("[ERROR] PaymentController.handleReservsRequest. Invalid request")
-			res.WriteHeader(http.StatusMethodNotAllowed)
-		}
-	} else if req.Method == `POST` {
-		log.Println("[INFO] ReservationController.handleReservsRequest. Got reservation POST request")
-		controller.handleReservPost(res, req)
-	} else {
-		log.Println("[ERROR] ReservationController.handleReservsRequest. Method not allowed")
-		res.WriteHeader(http.StatusMethodNotAllowed)
+func writeHotelAdminError(res http.ResponseWriter, err error) {
+	if errors.Is(err, serverrors.ErrEntityNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		return
 	}
+
+	if errors.Is(err, serverrors.ErrHotelHasActiveReservs) {
+		errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusConflict)
+		res.Write(errResJSON)
+		return
+	}
+
+	if errors.Is(err, serverrors.ErrInvalidHotel) ||
+		errors.Is(err, serverrors.ErrInvalidCancelPolicy) ||
+		errors.Is(err, serverrors.ErrInvalidRateRule) ||
+		errors.Is(err, serverrors.ErrInvalidExchangeRate) ||
+		errors.Is(err, serverrors.ErrInvalidTaxRule) {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	res.WriteHeader(http.StatusInternalServerError)
 }
 
-func (controller *ReservationController) handleReservWithUidRequest(res http.ResponseWriter, req *http.Request) {
-	if req.Method == `GET` {
-		log.Println("[INFO] ReservationController.handleReservWithUidRequest. Got reservation with uid GET request")
-		controller.handleReservByUidGet(res, req)
-	} else if req.Method == `PUT` {
-		log.Println("[INFO] ReservationController.handleReservWithUidRequest. Got reservation with uid PUT request")
-		controller.handleReservByUidPut(res, req)
-	} else {
-		log.Println("[ERROR] ReservationController.handleReservWithUidRequest. Method not allowed")
+func (controller *ReservationController) handleAdminHotelPost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminHotelPost. Handling admin hotel POST request")
+
+	var hotel models.Hotel
+
+	if !readHotelRequest(res, req, &hotel) {
+		return
+	}
+
+	newHotel, err := controller.service.CreateHotel(&hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminHotelPost. service.CreateHotel returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	newHotelJSON, err := json.Marshal(&newHotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminHotelPost. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusCreated)
+	res.Write(newHotelJSON)
+}
+
+func (controller *ReservationController) handleAdminHotelPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminHotelPut. Handling admin hotel PUT request")
+
+	var hotel models.Hotel
+
+	if !readHotelRequest(res, req, &hotel) {
+		return
+	}
+
+	hotel.Uid = req.PathValue(`hotelUid`)
+	updatedHotel, err := controller.service.UpdateHotel(&hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminHotelPut. service.UpdateHotel returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	updatedHotelJSON, err := json.Marshal(&updatedHotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminHotelPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(updatedHotelJSON)
+}
+
+func (controller *ReservationController) handleAdminHotelDelete(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminHotelDelete. Handling admin hotel DELETE request")
+
+	err := controller.service.RetireHotel(req.PathValue(`hotelUid`))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminHotelDelete. service.RetireHotel returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (controller *ReservationController) handleAdminCancelPolicyPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminCancelPolicyPut. Handling admin cancellation policy PUT request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var policy models.CancellationPolicy
+	err = json.Unmarshal(reqBody, &policy)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateCancellationPolicy(&policy)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyPut. Invalid cancellation policy:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	updatedPolicy, err := controller.service.UpdateCancelPolicy(req.PathValue(`hotelUid`), &policy)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyPut. service.UpdateCancelPolicy returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	updatedPolicyJSON, err := json.Marshal(&updatedPolicy)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(updatedPolicyJSON)
+}
+
+func (controller *ReservationController) handleAdminCancelPolicyDelete(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminCancelPolicyDelete. Handling admin cancellation policy DELETE request")
+
+	err := controller.service.DeleteCancelPolicy(req.PathValue(`hotelUid`))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyDelete. service.DeleteCancelPolicy returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (controller *ReservationController) handleAdminRateRulesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminRateRulesGet. Handling admin rate rules GET request")
+
+	rules, err := controller.service.ReadRateRules(req.PathValue(`hotelUid`))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesGet. service.ReadRateRules returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(rulesJSON)
+}
+
+func (controller *ReservationController) handleAdminRateRulesPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminRateRulesPut. Handling admin rate rules PUT request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var rules []models.RateRule
+	err = json.Unmarshal(reqBody, &rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateRateRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesPut. Invalid rate rules:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	newRules, err := controller.service.ReplaceRateRules(req.PathValue(`hotelUid`), rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesPut. service.ReplaceRateRules returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	newRulesJSON, err := json.Marshal(newRules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(newRulesJSON)
+}
+
+func (controller *ReservationController) handleHotelTaxRulesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleHotelTaxRulesGet. Handling hotel tax rules GET request")
+
+	rules, err := controller.service.ReadHotelTaxRules(req.PathValue(`hotelUid`))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelTaxRulesGet. service.ReadHotelTaxRules returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleHotelTaxRulesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(rulesJSON)
+}
+
+func (controller *ReservationController) handleAdminTaxRulesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminTaxRulesGet. Handling admin tax rules GET request")
+
+	rules, err := controller.service.ReadTaxRules()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesGet. service.ReadTaxRules returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(rulesJSON)
+}
+
+func (controller *ReservationController) handleAdminTaxRulesPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminTaxRulesPut. Handling admin tax rules PUT request")
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesPut. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var rules []models.TaxRule
+	err = json.Unmarshal(reqBody, &rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesPut. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validErrRes, err := models.ValidateTaxRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesPut. Invalid tax rules:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return
+	}
+
+	newRules, err := controller.service.ReplaceTaxRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesPut. service.ReplaceTaxRules returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	newRulesJSON, err := json.Marshal(newRules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(newRulesJSON)
+}
+
+// Rates come either as a JSON array or as a CSV file with a from,to,rate header.
+func readExchangeRatesRequest(res http.ResponseWriter, req *http.Request) (rates []models.ExchangeRate, ok bool) {
+	defer req.Body.Close()
+
+	var err error
+
+	if strings.HasPrefix(req.Header.Get(`Content-Type`), `text/csv`) {
+		rates, err = models.ParseExchangeRatesCSV(req.Body)
+	} else {
+		var reqBody []byte
+		reqBody, err = io.ReadAll(req.Body)
+
+		if err == nil {
+			err = json.Unmarshal(reqBody, &rates)
+		}
+	}
+
+	if err != nil {
+		log.Println("[ERROR] readExchangeRatesRequest. Error while parsing request body: ", err)
+		errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(errResJSON)
+		return rates, false
+	}
+
+	validErrRes, err := models.ValidateExchangeRates(rates)
+
+	if err != nil {
+		log.Println("[ERROR] readExchangeRatesRequest. Invalid exchange rates:", err)
+		validErrResJSON, _ := json.Marshal(validErrRes)
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusBadRequest)
+		res.Write(validErrResJSON)
+		return rates, false
+	}
+
+	if rates == nil {
+		rates = make([]models.ExchangeRate, 0)
+	}
+
+	return rates, true
+}
+
+func (controller *ReservationController) handleExchangeRatesGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleExchangeRatesGet. Handling exchange rates GET request")
+
+	rates, err := controller.service.ReadExchangeRates()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleExchangeRatesGet. service.ReadExchangeRates returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	ratesJSON, err := json.Marshal(rates)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleExchangeRatesGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(ratesJSON)
+}
+
+func (controller *ReservationController) handleAdminExchangeRatesPut(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleAdminExchangeRatesPut. Handling admin exchange rates PUT request")
+
+	rates, ok := readExchangeRatesRequest(res, req)
+
+	if !ok {
+		return
+	}
+
+	newRates, err := controller.service.ReplaceExchangeRates(rates)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminExchangeRatesPut. service.ReplaceExchangeRates returned error: ", err)
+		writeHotelAdminError(res, err)
+		return
+	}
+
+	newRatesJSON, err := json.Marshal(newRates)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleAdminExchangeRatesPut. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(newRatesJSON)
+}
+
+func writePromoCodeError(res http.ResponseWriter, err error) {
+	if errors.Is(err, serverrors.ErrEntityNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, serverrors.ErrPromoCodeExpired) ||
+		errors.Is(err, serverrors.ErrPromoCodeExhausted) ||
+		errors.Is(err, serverrors.ErrPromoCodeNotApplicable) {
+		errResJSON, _ := json.Marshal(models.ErrorResponse{Message: err.Error()})
+
+		res.Header().Add(`Content-Type`, `application/json`)
+		res.WriteHeader(http.StatusConflict)
+		res.Write(errResJSON)
+		return
+	}
+
+	if errors.Is(err, serverrors.ErrInvalidPromoCode) {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	res.WriteHeader(http.StatusInternalServerError)
+}
+
+func (controller *ReservationController) handlePromoCodeGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handlePromoCodeGet. Handling promo code GET request")
+
+	code := req.PathValue(`code`)
+	username := req.Header.Get(`X-User-Name`)
+	hotelUid := req.FormValue(`hotelUid`)
+	reservUid := req.FormValue(`reservationUid`)
+
+	if strings.Trim(code, ` `) == `` || strings.Trim(username, ` `) == `` ||
+		(reservUid != `` && uuid.Validate(reservUid) != nil) {
+		log.Println("[ERROR] ReservationController.handlePromoCodeGet. Invalid parameters or headers")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	promoCode, err := controller.service.ReadApplicablePromoCode(code, username, hotelUid, reservUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeGet. service.ReadApplicablePromoCode returned error: ", err)
+		writePromoCodeError(res, err)
+		return
+	}
+
+	promoCodeJSON, err := json.Marshal(promoCode)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeGet. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(promoCodeJSON)
+}
+
+func (controller *ReservationController) handlePromoCodeUsagePost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handlePromoCodeUsagePost. Handling promo code usage POST request")
+
+	code := req.PathValue(`code`)
+
+	if strings.Trim(code, ` `) == `` {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsagePost. Invalid promo code")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	defer req.Body.Close()
+
+	reqBody, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsagePost. Error while reading request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var usage models.PromoCodeUsage
+	err = json.Unmarshal(reqBody, &usage)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsagePost. Error while parsing JSON request body: ", err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	usage.Code = code
+	newUsage, err := controller.service.RedeemPromoCode(&usage)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsagePost. service.RedeemPromoCode returned error: ", err)
+		writePromoCodeError(res, err)
+		return
+	}
+
+	newUsageJSON, err := json.Marshal(&newUsage)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsagePost. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusCreated)
+	res.Write(newUsageJSON)
+}
+
+func (controller *ReservationController) handleHotelsRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		if strings.Trim(req.Header.Get(`Hotel-Id`), ` `) == `` {
+			log.Println("[INFO] ReservationController.handleHotelsRequest. Got hotels GET request")
+			controller.handleAllHotelsGet(res, req)
+		} else {
+			log.Println("[INFO] ReservationController.handleHotelsRequest. Got hotel by id GET request")
+			controller.handleHotelByIdGet(res, req)
+		}
+	} else {
+		log.Println("[ERROR] ReservationController.handleHotelsRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleHotelWithUidRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleHotelWithUidRequest. Got hotel with uid GET request")
+		controller.handleHotelByUidGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleHotelWithUidRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleHotelAvailabilityRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleHotelAvailabilityRequest. Got hotel availability GET request")
+		controller.handleHotelAvailabilityGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleHotelAvailabilityRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminHotelsRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminHotelsRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `POST` {
+		log.Println("[INFO] ReservationController.handleAdminHotelsRequest. Got admin hotels POST request")
+		controller.handleAdminHotelPost(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminHotelsRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminReservsRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminReservsRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleAdminReservsRequest. Got admin reservations GET request")
+		controller.handleAdminReservsGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminReservsRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminHotelWithUidRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminHotelWithUidRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] ReservationController.handleAdminHotelWithUidRequest. Got admin hotel PUT request")
+		controller.handleAdminHotelPut(res, req)
+	} else if req.Method == `DELETE` {
+		log.Println("[INFO] ReservationController.handleAdminHotelWithUidRequest. Got admin hotel DELETE request")
+		controller.handleAdminHotelDelete(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminHotelWithUidRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminCancelPolicyRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] ReservationController.handleAdminCancelPolicyRequest. Got admin cancellation policy PUT request")
+		controller.handleAdminCancelPolicyPut(res, req)
+	} else if req.Method == `DELETE` {
+		log.Println("[INFO] ReservationController.handleAdminCancelPolicyRequest. Got admin cancellation policy DELETE request")
+		controller.handleAdminCancelPolicyDelete(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminCancelPolicyRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleHotelPricesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleHotelPricesRequest. Got hotel nightly prices GET request")
+		controller.handleHotelPricesGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleHotelPricesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminRateRulesRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleAdminRateRulesRequest. Got admin rate rules GET request")
+		controller.handleAdminRateRulesGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] ReservationController.handleAdminRateRulesRequest. Got admin rate rules PUT request")
+		controller.handleAdminRateRulesPut(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminRateRulesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleHotelTaxRulesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleHotelTaxRulesRequest. Got hotel tax rules GET request")
+		controller.handleHotelTaxRulesGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleHotelTaxRulesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminTaxRulesRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleAdminTaxRulesRequest. Got admin tax rules GET request")
+		controller.handleAdminTaxRulesGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] ReservationController.handleAdminTaxRulesRequest. Got admin tax rules PUT request")
+		controller.handleAdminTaxRulesPut(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminTaxRulesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleExchangeRatesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleExchangeRatesRequest. Got exchange rates GET request")
+		controller.handleExchangeRatesGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleExchangeRatesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleAdminExchangeRatesRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handleAdminExchangeRatesRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+	} else if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleAdminExchangeRatesRequest. Got admin exchange rates GET request")
+		controller.handleExchangeRatesGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] ReservationController.handleAdminExchangeRatesRequest. Got admin exchange rates PUT request")
+		controller.handleAdminExchangeRatesPut(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleAdminExchangeRatesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleReservsRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		if strings.Trim(req.Header.Get(`X-User-Name`), ` `) != `` {
+			log.Println("[INFO] ReservationController.handleReservsRequest. Got reservation GET request")
+			controller.handleReservsByUsernameGet(res, req)
+		} else {
+			log.Println("[ERROR] PaymentController.handleReservsRequest. Invalid request")
+			res.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	} else if req.Method == `POST` {
+		log.Println("[INFO] ReservationController.handleReservsRequest. Got reservation POST request")
+		controller.handleReservPost(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleReservsRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handleReservWithUidRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleReservWithUidRequest. Got reservation with uid GET request")
+		controller.handleReservByUidGet(res, req)
+	} else if req.Method == `PUT` {
+		log.Println("[INFO] ReservationController.handleReservWithUidRequest. Got reservation with uid PUT request")
+		controller.handleReservByUidPut(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleReservWithUidRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handlePromoCodesRequest(res http.ResponseWriter, req *http.Request) {
+	if !controller.isAdminRequest(req) {
+		log.Println("[ERROR] ReservationController.handlePromoCodesRequest. Unauthorized")
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if req.Method == `POST` {
+		log.Println("[INFO] ReservationController.handlePromoCodesRequest. Got promo code POST request")
+		controller.handlePromoCodePost(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handlePromoCodesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handlePromoCodeWithCodeRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handlePromoCodeWithCodeRequest. Got promo code GET request")
+		controller.handlePromoCodeGet(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handlePromoCodeWithCodeRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handlePromoCodeUsageDelete(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handlePromoCodeUsageDelete. Handling promo code usage DELETE request")
+
+	reservUid := req.PathValue(`reservUid`)
+
+	if uuid.Validate(reservUid) != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsageDelete. Invalid reservation uid")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err := controller.service.ReleasePromoCode(reservUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsageDelete. service.ReleasePromoCode returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (controller *ReservationController) handlePromoCodeUsagesRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `POST` {
+		log.Println("[INFO] ReservationController.handlePromoCodeUsagesRequest. Got promo code usage POST request")
+		controller.handlePromoCodeUsagePost(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsagesRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) handlePromoCodeUsageWithUidRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `DELETE` {
+		log.Println("[INFO] ReservationController.handlePromoCodeUsageWithUidRequest. Got promo code usage DELETE request")
+		controller.handlePromoCodeUsageDelete(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handlePromoCodeUsageWithUidRequest. Method not allowed")
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (controller *ReservationController) writeCalendarFeed(res http.ResponseWriter, feed *models.CalendarFeed) {
+	feedJSON, err := json.Marshal(feed)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.writeCalendarFeed. Cannot convert result into JSON format: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Add(`Content-Type`, `application/json`)
+	res.WriteHeader(http.StatusOK)
+	res.Write(feedJSON)
+}
+
+func (controller *ReservationController) handleCalendarFeedGet(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleCalendarFeedGet. Handling calendar feed GET request")
+
+	username := req.PathValue(`username`)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] ReservationController.handleCalendarFeedGet. Invalid username")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	feed, err := controller.service.ReadCalendarFeed(username)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleCalendarFeedGet. service.ReadCalendarFeed returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	controller.writeCalendarFeed(res, &feed)
+}
+
+func (controller *ReservationController) handleCalendarFeedPost(res http.ResponseWriter, req *http.Request) {
+	log.Println("[INFO] ReservationController.handleCalendarFeedPost. Handling calendar feed POST request")
+
+	username := req.PathValue(`username`)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] ReservationController.handleCalendarFeedPost. Invalid username")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	feed, err := controller.service.RotateCalendarFeed(username)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationController.handleCalendarFeedPost. service.RotateCalendarFeed returned error: ", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	controller.writeCalendarFeed(res, &feed)
+}
+
+func (controller *ReservationController) handleCalendarFeedRequest(res http.ResponseWriter, req *http.Request) {
+	if req.Method == `GET` {
+		log.Println("[INFO] ReservationController.handleCalendarFeedRequest. Got calendar feed GET request")
+		controller.handleCalendarFeedGet(res, req)
+	} else if req.Method == `POST` {
+		log.Println("[INFO] ReservationController.handleCalendarFeedRequest. Got calendar feed POST request")
+		controller.handleCalendarFeedPost(res, req)
+	} else {
+		log.Println("[ERROR] ReservationController.handleCalendarFeedRequest. Method not allowed")
 		res.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
@@ -397,8 +1502,24 @@ func (controller *ReservationController) handleHealthRequest(res http.ResponseWr
 func (controller *ReservationController) Prepare() error {
 	http.HandleFunc(`/api/v1/hotels`, controller.handleHotelsRequest)
 	http.HandleFunc(`/api/v1/hotels/{hotelUid}`, controller.handleHotelWithUidRequest)
+	http.HandleFunc(`/api/v1/hotels/{hotelUid}/availability`, controller.handleHotelAvailabilityRequest)
+	http.HandleFunc(`/api/v1/hotels/{hotelUid}/prices`, controller.handleHotelPricesRequest)
+	http.HandleFunc(`/api/v1/hotels/{hotelUid}/tax-rules`, controller.handleHotelTaxRulesRequest)
+	http.HandleFunc(`/api/v1/admin/hotels`, controller.handleAdminHotelsRequest)
+	http.HandleFunc(`/api/v1/admin/hotels/{hotelUid}`, controller.handleAdminHotelWithUidRequest)
+	http.HandleFunc(`/api/v1/admin/hotels/{hotelUid}/cancellation-policy`, controller.handleAdminCancelPolicyRequest)
+	http.HandleFunc(`/api/v1/admin/hotels/{hotelUid}/rate-rules`, controller.handleAdminRateRulesRequest)
+	http.HandleFunc(`/api/v1/admin/reservations`, controller.handleAdminReservsRequest)
+	http.HandleFunc(`/api/v1/admin/exchange-rates`, controller.handleAdminExchangeRatesRequest)
+	http.HandleFunc(`/api/v1/admin/tax-rules`, controller.handleAdminTaxRulesRequest)
+	http.HandleFunc(`/api/v1/exchange-rates`, controller.handleExchangeRatesRequest)
 	http.HandleFunc(`/api/v1/reservations`, controller.handleReservsRequest)
 	http.HandleFunc(`/api/v1/reservations/{reservUid}`, controller.handleReservWithUidRequest)
+	http.HandleFunc(`/api/v1/promocodes`, controller.handlePromoCodesRequest)
+	http.HandleFunc(`/api/v1/promocodes/{code}`, controller.handlePromoCodeWithCodeRequest)
+	http.HandleFunc(`/api/v1/promocodes/{code}/usages`, controller.handlePromoCodeUsagesRequest)
+	http.HandleFunc(`/api/v1/promocodes/{code}/usages/{reservUid}`, controller.handlePromoCodeUsageWithUidRequest)
+	http.HandleFunc(`/api/v1/calendar-feeds/{username}`, controller.handleCalendarFeedRequest)
 
 	http.HandleFunc(`/manage/health`, controller.handleHealthRequest)
 
@@ -1,6 +1,10 @@
 package database
 
-import "container/list"
+import (
+	"container/list"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+)
 
 type IDAO[T any] interface {
 	SetConnectionString(string)
@@ -17,3 +21,66 @@ type IDAO[T any] interface {
 	Delete(*T) error
 	DeleteByAttr(string, string) error
 }
+
+type IReservationDAO interface {
+	IDAO[models.Reservation]
+
+	ExpireHolds() (list.List, error)
+}
+
+type ILoyaltyDAO interface {
+	IDAO[models.Loyalty]
+
+	ChangeReservationCount(*models.LoyaltyEvent, int, int) (models.Loyalty, error)
+	CorrectReservationCount(string, *models.LoyaltyCountCorrection, int) (models.Loyalty, error)
+}
+
+type IPaymentDAO interface {
+	IDAO[models.Payment]
+
+	ApplyTransaction(string, *models.PaymentTransaction, func(*models.PaymentTransaction) error) (models.Payment, error)
+	GetTransactions(string) (list.List, error)
+	GetInvoice(string) (models.Invoice, error)
+	IssueInvoice(string) (models.Invoice, error)
+}
+
+type ILedgerDAO interface {
+	IDAO[models.LedgerEntry]
+
+	GetBalances(string) (list.List, error)
+	Check() (models.LedgerCheckResponse, error)
+}
+
+type IHotelDAO interface {
+	IDAO[models.Hotel]
+
+	GetFiltered(*models.HotelFilter, int, int) (list.List, error)
+	GetFilteredAfter(*models.HotelFilter, *models.HotelCursor, int) (list.List, error)
+	CountFiltered(*models.HotelFilter) (int, error)
+	GetAvailability(*models.Hotel, string, string) (list.List, error)
+	UpsertBatch([]models.Hotel) (int, error)
+}
+
+type IRateRuleDAO interface {
+	IDAO[models.RateRule]
+
+	ReplaceByHotel(int, []models.RateRule) (list.List, error)
+}
+
+type IExchangeRateDAO interface {
+	IDAO[models.ExchangeRate]
+
+	ReplaceAll([]models.ExchangeRate) (list.List, error)
+}
+
+type ITaxRuleDAO interface {
+	IDAO[models.TaxRule]
+
+	ReplaceAll([]models.TaxRule) (list.List, error)
+}
+
+type ICalendarFeedDAO interface {
+	IDAO[models.CalendarFeed]
+
+	Rotate(string) (models.CalendarFeed, error)
+}
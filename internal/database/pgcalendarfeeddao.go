@@ -0,0 +1,133 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresCalendarFeedDAO struct {
+	connStr string
+}
+
+func NewPostgresCalendarFeedDAO(connStr string) ICalendarFeedDAO {
+	return &PostgresCalendarFeedDAO{connStr}
+}
+
+func (dao *PostgresCalendarFeedDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+func (dao *PostgresCalendarFeedDAO) Create(feed *models.CalendarFeed) (models.CalendarFeed, error) {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.Create. Method is not implemented")
+	return models.CalendarFeed{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCalendarFeedDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCalendarFeedDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCalendarFeedDAO) GetById(feed *models.CalendarFeed) (models.CalendarFeed, error) {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.GetById. Method is not implemented")
+	return models.CalendarFeed{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCalendarFeedDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCalendarFeedDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(`select username, version from calendar_feed where %s = $1;`, attrName)
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCalendarFeedDAO.GetByAttribute. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var feed models.CalendarFeed
+		err = rows.Scan(&feed.Username, &feed.Version)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresCalendarFeedDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(feed)
+	}
+
+	return resLst, rows.Err()
+}
+
+func (dao *PostgresCalendarFeedDAO) Update(feed *models.CalendarFeed) (models.CalendarFeed, error) {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.Update. Method is not implemented")
+	return models.CalendarFeed{}, serverrors.ErrMethodIsNotImplemented
+}
+
+// Users without a row are on version 0, so the first rotation moves them to 1.
+func (dao *PostgresCalendarFeedDAO) Rotate(username string) (feed models.CalendarFeed, err error) {
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] PostgresCalendarFeedDAO.Rotate. Invalid username")
+		return feed, serverrors.ErrInvalidUsername
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCalendarFeedDAO.Rotate. Cannot connect to database:", err)
+		return feed, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	err = conn.QueryRow(
+		context.Background(),
+		`insert into calendar_feed (username, version)
+		values ($1, 1)
+		on conflict (username) do update
+		set version = calendar_feed.version + 1
+		returning username, version;`,
+		username,
+	).Scan(&feed.Username, &feed.Version)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCalendarFeedDAO.Rotate. Error while reading query result:", err)
+		return models.CalendarFeed{}, serverrors.ErrEntityInsert
+	}
+
+	return feed, nil
+}
+
+func (dao *PostgresCalendarFeedDAO) Delete(feed *models.CalendarFeed) error {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCalendarFeedDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresCalendarFeedDAO.DeleteByAttr. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresCancelPolicyDAO struct {
+	connStr string
+}
+
+func NewPostgresCancelPolicyDAO(connStr string) IDAO[models.CancellationPolicy] {
+	return &PostgresCancelPolicyDAO{connStr}
+}
+
+func (dao *PostgresCancelPolicyDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+const cancelPolicyColumns = `id, hotel_id, free_cancellation_days, penalty_percent, non_refundable`
+
+func scanCancelPolicy(row pgx.Row, policy *models.CancellationPolicy) error {
+	return row.Scan(
+		&policy.Id, &policy.HotelId,
+		&policy.FreeCancellationDays, &policy.PenaltyPercent,
+		&policy.NonRefundable,
+	)
+}
+
+func (dao *PostgresCancelPolicyDAO) Create(policy *models.CancellationPolicy) (newPolicy models.CancellationPolicy, err error) {
+	_, err = models.ValidateCancellationPolicy(policy)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.Create. Invalid cancellation policy data:", err)
+		return newPolicy, err
+	}
+
+	if policy.HotelId <= 0 {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.Create. Invalid hotel ID")
+		return newPolicy, serverrors.ErrInvalidHotelId
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.Create. Cannot connect to database:", err)
+		return newPolicy, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	row := conn.QueryRow(
+		context.Background(),
+		`insert into cancellation_policy (hotel_id, free_cancellation_days, penalty_percent, non_refundable)
+		values ($1, $2, $3, $4)
+		on conflict (hotel_id) do update
+		set free_cancellation_days = excluded.free_cancellation_days,
+			penalty_percent = excluded.penalty_percent,
+			non_refundable = excluded.non_refundable
+		returning `+cancelPolicyColumns+`;`,
+		policy.HotelId, policy.FreeCancellationDays,
+		policy.PenaltyPercent, policy.NonRefundable,
+	)
+
+	err = scanCancelPolicy(row, &newPolicy)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.Create. Error while reading query result:", err)
+		err = serverrors.ErrEntityInsert
+	}
+
+	return newPolicy, err
+}
+
+func (dao *PostgresCancelPolicyDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresCancelPolicyDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCancelPolicyDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresCancelPolicyDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCancelPolicyDAO) GetById(policy *models.CancellationPolicy) (models.CancellationPolicy, error) {
+	log.Println("[ERROR] PostgresCancelPolicyDAO.GetById. Method is not implemented")
+	return models.CancellationPolicy{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCancelPolicyDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`select %s from cancellation_policy where %s = $1;`,
+		cancelPolicyColumns,
+		attrName,
+	)
+
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresCancelPolicyDAO.GetByAttribute. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var policy models.CancellationPolicy
+		err = scanCancelPolicy(rows, &policy)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresCancelPolicyDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(policy)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresCancelPolicyDAO) Update(policy *models.CancellationPolicy) (models.CancellationPolicy, error) {
+	return dao.Create(policy)
+}
+
+func (dao *PostgresCancelPolicyDAO) Delete(policy *models.CancellationPolicy) error {
+	log.Println("[ERROR] PostgresCancelPolicyDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresCancelPolicyDAO) DeleteByAttr(attrName string, attrValue string) error {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.DeleteByAttr. Cannot connect to database:", err)
+		return serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(
+		context.Background(),
+		fmt.Sprintf(`delete from cancellation_policy where %s = $1;`, attrName),
+		attrValue,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresCancelPolicyDAO.DeleteByAttr. Error while executing query:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	return nil
+}
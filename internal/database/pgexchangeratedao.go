@@ -0,0 +1,187 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresExchangeRateDAO struct {
+	connStr string
+}
+
+func NewPostgresExchangeRateDAO(connStr string) IExchangeRateDAO {
+	return &PostgresExchangeRateDAO{connStr}
+}
+
+func (dao *PostgresExchangeRateDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+const exchangeRateColumns = `from_currency, to_currency, rate::text, updated_at`
+
+func scanExchangeRate(row pgx.Row, rate *models.ExchangeRate) (err error) {
+	var rateStr string
+	var updatedAt time.Time
+
+	err = row.Scan(&rate.From, &rate.To, &rateStr, &updatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	rate.Rate, err = models.ParseExchangeRateValue(rateStr)
+	rate.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+
+	return err
+}
+
+func insertExchangeRate(tx pgx.Tx, rate *models.ExchangeRate) (newRate models.ExchangeRate, err error) {
+	row := tx.QueryRow(
+		context.Background(),
+		`insert into exchange_rate (from_currency, to_currency, rate)
+		values ($1, $2, $3::numeric)
+		returning `+exchangeRateColumns+`;`,
+		strings.ToUpper(rate.From), strings.ToUpper(rate.To), rate.Rate.String(),
+	)
+
+	err = scanExchangeRate(row, &newRate)
+
+	return newRate, err
+}
+
+func (dao *PostgresExchangeRateDAO) Create(rate *models.ExchangeRate) (models.ExchangeRate, error) {
+	log.Println("[ERROR] PostgresExchangeRateDAO.Create. Method is not implemented")
+	return models.ExchangeRate{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresExchangeRateDAO) ReplaceAll(rates []models.ExchangeRate) (resLst list.List, err error) {
+	_, err = models.ValidateExchangeRates(rates)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.ReplaceAll. Invalid exchange rates:", err)
+		return resLst, err
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.ReplaceAll. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.ReplaceAll. Cannot begin transaction:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(context.Background(), `delete from exchange_rate;`)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.ReplaceAll. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	for i := range rates {
+		newRate, err := insertExchangeRate(tx, &rates[i])
+
+		if err != nil {
+			log.Println("[ERROR] PostgresExchangeRateDAO.ReplaceAll. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrEntityInsert
+		}
+
+		resLst.PushBack(newRate)
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.ReplaceAll. Cannot commit transaction:", err)
+		return list.List{}, serverrors.ErrQueryExec
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresExchangeRateDAO) Get() (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.Get. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(
+		context.Background(),
+		`select `+exchangeRateColumns+` from exchange_rate order by from_currency, to_currency;`,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresExchangeRateDAO.Get. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate models.ExchangeRate
+		err = scanExchangeRate(rows, &rate)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresExchangeRateDAO.Get. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(rate)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresExchangeRateDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresExchangeRateDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresExchangeRateDAO) GetById(rate *models.ExchangeRate) (models.ExchangeRate, error) {
+	log.Println("[ERROR] PostgresExchangeRateDAO.GetById. Method is not implemented")
+	return models.ExchangeRate{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresExchangeRateDAO) GetByAttribute(attrName string, attrValue string) (list.List, error) {
+	log.Println("[ERROR] PostgresExchangeRateDAO.GetByAttribute. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresExchangeRateDAO) Update(rate *models.ExchangeRate) (models.ExchangeRate, error) {
+	log.Println("[ERROR] PostgresExchangeRateDAO.Update. Method is not implemented")
+	return models.ExchangeRate{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresExchangeRateDAO) Delete(rate *models.ExchangeRate) error {
+	log.Println("[ERROR] PostgresExchangeRateDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresExchangeRateDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresExchangeRateDAO.DeleteByAttr. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
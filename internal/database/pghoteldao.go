@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	_ "github.com/jackc/pgx"
 	"github.com/jackc/pgx/v5"
@@ -18,7 +20,7 @@ type PostgresHotelDAO struct {
 	connStr string
 }
 
-func NewPostgresHotelDAO(connStr string) IDAO[models.Hotel] {
+func NewPostgresHotelDAO(connStr string) IHotelDAO {
 	return &PostgresHotelDAO{connStr}
 }
 
@@ -26,29 +28,398 @@ func (dao *PostgresHotelDAO) SetConnectionString(connStr string) {
 	dao.connStr = connStr
 }
 
-func (dao *PostgresHotelDAO) Create(hotel *models.Hotel) (models.Hotel, error) {
-	log.Println("[ERROR] PostgresHotelDAO.Create. Method is not implemented")
-	return models.Hotel{}, serverrors.ErrMethodIsNotImplemented
+const hotelColumns = `id, hotel_uid, name, country, city, address, coalesce(stars, 0), price, currency, retired,
+	(select coalesce(sum(rooms_count), 0) from room_type where room_type.hotel_id = hotels.id)`
+
+func scanHotel(row pgx.Row, hotel *models.Hotel) error {
+	return row.Scan(
+		&hotel.Id, &hotel.Uid,
+		&hotel.Name, &hotel.Country,
+		&hotel.City, &hotel.Address,
+		&hotel.Stars, &hotel.Price,
+		&hotel.Currency, &hotel.Retired,
+		&hotel.RoomsCount,
+	)
+}
+
+func (dao *PostgresHotelDAO) Create(hotel *models.Hotel) (newHotel models.Hotel, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Create. Cannot connect to database:", err)
+		return newHotel, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Create. Cannot begin transaction:", err)
+		return newHotel, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	row := tx.QueryRow(
+		context.Background(),
+		`insert into hotels (hotel_uid, name, country, city, address, stars, price, currency)
+		values ($1, $2, $3, $4, $5, $6, $7, $8)
+		returning `+hotelColumns,
+		hotel.Uid, hotel.Name, hotel.Country,
+		hotel.City, hotel.Address, hotel.Stars, hotel.Price,
+		models.NormalizeCurrency(hotel.Currency),
+	)
+
+	err = scanHotel(row, &newHotel)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Create. Error while reading query result:", err)
+		return models.Hotel{}, serverrors.ErrEntityInsert
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		`insert into room_type (room_type_uid, hotel_id, name, rooms_count)
+		values (gen_random_uuid(), $1, 'Standard', $2);`,
+		newHotel.Id, hotel.RoomsCount,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Create. Error while executing query:", err)
+		return models.Hotel{}, serverrors.ErrEntityInsert
+	}
+
+	newHotel.RoomsCount = hotel.RoomsCount
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Create. Cannot commit transaction:", err)
+		return models.Hotel{}, serverrors.ErrQueryExec
+	}
+
+	return newHotel, nil
+}
+
+func (dao *PostgresHotelDAO) UpsertBatch(hotels []models.Hotel) (upsertedCount int, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.UpsertBatch. Cannot connect to database:", err)
+		return upsertedCount, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.UpsertBatch. Cannot begin transaction:", err)
+		return upsertedCount, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	batch := &pgx.Batch{}
+
+	// An existing hotel keeps its retired flag unless the import brings it back:
+	// retiring goes through Delete, which checks for active reservations.
+	for i := range hotels {
+		batch.Queue(
+			`insert into hotels (hotel_uid, name, country, city, address, stars, price, currency, retired)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			on conflict (hotel_uid) do update
+			set name = excluded.name, country = excluded.country, city = excluded.city,
+				address = excluded.address, stars = excluded.stars, price = excluded.price,
+				currency = excluded.currency, retired = hotels.retired and excluded.retired;`,
+			hotels[i].Uid, hotels[i].Name, hotels[i].Country,
+			hotels[i].City, hotels[i].Address, hotels[i].Stars, hotels[i].Price,
+			models.NormalizeCurrency(hotels[i].Currency), hotels[i].Retired,
+		)
+
+		batch.Queue(
+			`insert into room_type (room_type_uid, hotel_id, name, rooms_count)
+			select gen_random_uuid(), id, 'Standard', $2 from hotels where hotel_uid = $1
+			on conflict (hotel_id, name) do update set rooms_count = excluded.rooms_count;`,
+			hotels[i].Uid, hotels[i].RoomsCount,
+		)
+	}
+
+	err = tx.SendBatch(context.Background(), batch).Close()
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.UpsertBatch. Error while executing batch:", err)
+		return upsertedCount, serverrors.ErrQueryExec
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.UpsertBatch. Cannot commit transaction:", err)
+		return upsertedCount, serverrors.ErrQueryExec
+	}
+
+	return len(hotels), nil
 }
 
 func (dao *PostgresHotelDAO) Get() (resLst list.List, err error) {
-	log.Println("[ERROR] PostgresHotelDAO.Get. Method is not implemented")
-	return resLst, serverrors.ErrMethodIsNotImplemented
+	return dao.queryHotels(`Get`, `select `+hotelColumns+` from hotels order by id;`, nil)
 }
 
 func (dao *PostgresHotelDAO) GetPaginated(
 	page int,
 	pageSize int,
+) (resLst list.List, err error) {
+	return dao.GetFiltered(&models.HotelFilter{}, page, pageSize)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func buildHotelFilterQuery(filter *models.HotelFilter) (whereStr string, args []any) {
+	conditions := []string{`not retired`}
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.City != `` {
+		addCondition(`city = $%d`, filter.City)
+	}
+
+	if filter.Country != `` {
+		addCondition(`country = $%d`, filter.Country)
+	}
+
+	if filter.Name != `` {
+		addCondition(`name ilike '%%' || $%d || '%%'`, likeEscaper.Replace(filter.Name))
+	}
+
+	if filter.MinStars > 0 {
+		addCondition(`coalesce(stars, 0) >= $%d`, filter.MinStars)
+	}
+
+	if filter.MaxStars > 0 {
+		addCondition(`coalesce(stars, 0) <= $%d`, filter.MaxStars)
+	}
+
+	// Prices in different currencies are not comparable, so price bounds are
+	// major units of the price currency and only match hotels priced in it.
+	if filter.PriceCurrency != `` || filter.MinPrice > 0 || filter.MaxPrice > 0 {
+		currency := models.NormalizeCurrency(filter.PriceCurrency)
+		addCondition(`currency = $%d`, currency)
+
+		if filter.MinPrice > 0 {
+			addCondition(`price >= $%d`, models.MinorUnits(filter.MinPrice, currency))
+		}
+
+		if filter.MaxPrice > 0 {
+			addCondition(`price <= $%d`, models.MinorUnits(filter.MaxPrice, currency))
+		}
+	}
+
+	whereStr = `where ` + strings.Join(conditions, ` and `)
+
+	return whereStr, args
+}
+
+func hotelSortExpr(filter *models.HotelFilter) (sortExpr string, sortCast string) {
+	switch filter.SortBy {
+	case models.HotelSortPrice:
+		return `price`, `int`
+	case models.HotelSortStars:
+		return `coalesce(stars, 0)`, `int`
+	case models.HotelSortName:
+		return `name`, `text`
+	default:
+		return ``, ``
+	}
+}
+
+func buildHotelOrder(filter *models.HotelFilter, reversed bool) string {
+	sortExpr, _ := hotelSortExpr(filter)
+	desc := filter.SortOrder == models.SortOrderDesc
+
+	if reversed {
+		desc = !desc
+	}
+
+	sortOrder := `asc`
+
+	if desc {
+		sortOrder = `desc`
+	}
+
+	if sortExpr == `` {
+		return `order by id ` + sortOrder
+	}
+
+	return fmt.Sprintf(`order by %s %s, id %s`, sortExpr, sortOrder, sortOrder)
+}
+
+func (dao *PostgresHotelDAO) queryHotels(
+	methodName string,
+	queryStr string,
+	args []any,
+) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Printf("[ERROR] PostgresHotelDAO.%s. Cannot connect to database: %v\n", methodName, err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(context.Background(), queryStr, args...)
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("[ERROR] PostgresHotelDAO.%s. Error while executing query: %v\n", methodName, err)
+		return resLst, serverrors.ErrQueryResRead
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var hotel models.Hotel
+		err = scanHotel(rows, &hotel)
+
+		if err != nil {
+			log.Printf("[ERROR] PostgresHotelDAO.%s. Error while reading query result: %v\n", methodName, err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(hotel)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresHotelDAO) GetFiltered(
+	filter *models.HotelFilter,
+	page int,
+	pageSize int,
 ) (resLst list.List, err error) {
 	if page <= 0 || pageSize <= 0 {
-		log.Println("[ERROR] PostgresHotelDAO.GetPaginated. Invalid pages data")
+		log.Println("[ERROR] PostgresHotelDAO.GetFiltered. Invalid pages data")
+		return resLst, serverrors.ErrInvalidPagesData
+	}
+
+	whereStr, args := buildHotelFilterQuery(filter)
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	queryStr := fmt.Sprintf(
+		`select %s from hotels %s %s limit $%d offset $%d;`,
+		hotelColumns,
+		whereStr,
+		buildHotelOrder(filter, false),
+		len(args)-1,
+		len(args),
+	)
+
+	return dao.queryHotels(`GetFiltered`, queryStr, args)
+}
+
+func (dao *PostgresHotelDAO) GetFilteredAfter(
+	filter *models.HotelFilter,
+	cursor *models.HotelCursor,
+	limit int,
+) (resLst list.List, err error) {
+	if limit <= 0 {
+		log.Println("[ERROR] PostgresHotelDAO.GetFilteredAfter. Invalid limit")
 		return resLst, serverrors.ErrInvalidPagesData
 	}
 
+	whereStr, args := buildHotelFilterQuery(filter)
+	reversed := cursor != nil && cursor.Backward
+
+	if cursor != nil {
+		comparison := `>`
+
+		if (filter.SortOrder == models.SortOrderDesc) != reversed {
+			comparison = `<`
+		}
+
+		sortExpr, sortCast := hotelSortExpr(filter)
+
+		var condition string
+
+		if sortExpr == `` {
+			args = append(args, cursor.Id)
+			condition = fmt.Sprintf(`id %s $%d`, comparison, len(args))
+		} else {
+			args = append(args, cursor.SortValue, cursor.Id)
+			condition = fmt.Sprintf(`(%s, id) %s ($%d::%s, $%d)`, sortExpr, comparison, len(args)-1, sortCast, len(args))
+		}
+
+		if whereStr == `` {
+			whereStr = `where ` + condition
+		} else {
+			whereStr += ` and ` + condition
+		}
+	}
+
+	args = append(args, limit)
+
+	queryStr := fmt.Sprintf(
+		`select %s from hotels %s %s limit $%d;`,
+		hotelColumns,
+		whereStr,
+		buildHotelOrder(filter, reversed),
+		len(args),
+	)
+
+	resLst, err = dao.queryHotels(`GetFilteredAfter`, queryStr, args)
+
+	if err != nil || !reversed {
+		return resLst, err
+	}
+
+	var orderedLst list.List
+
+	for resLstEl := resLst.Front(); resLstEl != nil; resLstEl = resLstEl.Next() {
+		orderedLst.PushFront(resLstEl.Value)
+	}
+
+	return orderedLst, nil
+}
+
+func (dao *PostgresHotelDAO) CountFiltered(filter *models.HotelFilter) (count int, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.CountFiltered. Cannot connect to database:", err)
+		return count, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	whereStr, args := buildHotelFilterQuery(filter)
+
+	err = conn.QueryRow(
+		context.Background(),
+		fmt.Sprintf(`select count(*) from hotels %s;`, whereStr),
+		args...,
+	).Scan(&count)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.CountFiltered. Error while reading query result:", err)
+		return count, serverrors.ErrQueryResRead
+	}
+
+	return count, nil
+}
+
+func (dao *PostgresHotelDAO) GetAvailability(
+	hotel *models.Hotel,
+	from string,
+	to string,
+) (resLst list.List, err error) {
 	conn, err := pgx.Connect(context.Background(), dao.connStr)
 
 	if err != nil {
-		log.Println("[ERROR] PostgresHotelDAO.GetPaginated. Cannot connect to database:", err)
+		log.Println("[ERROR] PostgresHotelDAO.GetAvailability. Cannot connect to database:", err)
 		return resLst, serverrors.ErrDatabaseConnection
 	}
 
@@ -56,33 +427,40 @@ func (dao *PostgresHotelDAO) GetPaginated(
 
 	rows, err := conn.Query(
 		context.Background(),
-		`select * from hotels order by id limit $1 offset $2;`,
-		pageSize,
-		(page-1)*pageSize,
+		`select night::date, coalesce(sum(greatest(rt.rooms_count - (
+			select count(*)
+			from reservation r
+			where r.room_type_id = rt.id and `+activeReservCondition+` and r.start_date <= night and r.end_date > night
+		), 0)), 0)
+		from generate_series($2::date, $3::date - 1, interval '1 day') night
+		left join room_type rt on rt.hotel_id = $1
+		group by night
+		order by night;`,
+		hotel.Id, from, to,
 	)
 
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		log.Println("[ERROR] PostgresHotelDAO.GetPaginated. Error while executing query:", err)
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.GetAvailability. Error while executing query:", err)
 		return resLst, serverrors.ErrQueryResRead
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
-		var hotel models.Hotel
-		err = rows.Scan(
-			&hotel.Id, &hotel.Uid,
-			&hotel.Name, &hotel.Country,
-			&hotel.City, &hotel.Address,
-			&hotel.Stars, &hotel.Price,
-		)
+		var night time.Time
+		var nightAvailability models.NightAvailability
+
+		err = rows.Scan(&night, &nightAvailability.RemainingRooms)
 
 		if err != nil {
-			log.Println("[ERROR] PostgresHotelDAO.GetPaginated. Error while reading query result:", err)
+			log.Println("[ERROR] PostgresHotelDAO.GetAvailability. Error while reading query result:", err)
 			return list.List{}, serverrors.ErrQueryResRead
 		}
 
-		resLst.PushBack(hotel)
+		nightAvailability.Date = night.Format(time.DateOnly)
+		nightAvailability.Price = hotel.Price
+
+		resLst.PushBack(nightAvailability)
 	}
 
 	return resLst, nil
@@ -105,16 +483,11 @@ func (dao *PostgresHotelDAO) GetById(hotel *models.Hotel) (resHotel models.Hotel
 
 	row := conn.QueryRow(
 		context.Background(),
-		`select * from hotels where id = $1;`,
+		`select `+hotelColumns+` from hotels where id = $1;`,
 		hotel.Id,
 	)
 
-	err = row.Scan(
-		&resHotel.Id, &resHotel.Uid,
-		&resHotel.Name, &resHotel.Country,
-		&resHotel.City, &resHotel.Address,
-		&resHotel.Stars, &resHotel.Price,
-	)
+	err = scanHotel(row, &resHotel)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -140,7 +513,8 @@ func (dao *PostgresHotelDAO) GetByAttribute(attrName string, attrValue string) (
 	defer conn.Close(context.Background())
 
 	queryStr := fmt.Sprintf(
-		`select * from hotels where %s = $1;`,
+		`select %s from hotels where %s = $1;`,
+		hotelColumns,
 		attrName,
 	)
 	rows, err := conn.Query(context.Background(), queryStr, attrValue)
@@ -158,12 +532,7 @@ func (dao *PostgresHotelDAO) GetByAttribute(attrName string, attrValue string) (
 
 	for rows.Next() {
 		var hotel models.Hotel
-		err = rows.Scan(
-			&hotel.Id, &hotel.Uid,
-			&hotel.Name, &hotel.Country,
-			&hotel.City, &hotel.Address,
-			&hotel.Stars, &hotel.Price,
-		)
+		err = scanHotel(rows, &hotel)
 
 		if err != nil {
 			log.Println("[ERROR] PostgresHotelDAO.GetByAttribute. Error while reading query result:", err)
@@ -176,14 +545,143 @@ func (dao *PostgresHotelDAO) GetByAttribute(attrName string, attrValue string) (
 	return resLst, nil
 }
 
-func (dao *PostgresHotelDAO) Update(hotel *models.Hotel) (models.Hotel, error) {
-	log.Println("[ERROR] PostgresHotelDAO.Update. Method is not implemented")
-	return models.Hotel{}, serverrors.ErrMethodIsNotImplemented
+func (dao *PostgresHotelDAO) Update(hotel *models.Hotel) (updatedHotel models.Hotel, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Update. Cannot connect to database:", err)
+		return updatedHotel, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Update. Cannot begin transaction:", err)
+		return updatedHotel, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	row := tx.QueryRow(
+		context.Background(),
+		`update hotels
+		set name = $1, country = $2, city = $3, address = $4, stars = $5, price = $6, currency = $7
+		where hotel_uid = $8
+		returning `+hotelColumns,
+		hotel.Name, hotel.Country, hotel.City,
+		hotel.Address, hotel.Stars, hotel.Price,
+		models.NormalizeCurrency(hotel.Currency), hotel.Uid,
+	)
+
+	err = scanHotel(row, &updatedHotel)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresHotelDAO.Update. Entity not found")
+			return models.Hotel{}, serverrors.ErrEntityNotFound
+		}
+
+		log.Println("[ERROR] PostgresHotelDAO.Update. Error while reading query result:", err)
+		return models.Hotel{}, serverrors.ErrQueryResRead
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		`insert into room_type (room_type_uid, hotel_id, name, rooms_count)
+		values (gen_random_uuid(), $1, 'Standard', $2)
+		on conflict (hotel_id, name) do update set rooms_count = excluded.rooms_count;`,
+		updatedHotel.Id, hotel.RoomsCount,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Update. Error while executing query:", err)
+		return models.Hotel{}, serverrors.ErrQueryExec
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Update. Cannot commit transaction:", err)
+		return models.Hotel{}, serverrors.ErrQueryExec
+	}
+
+	updatedHotel.RoomsCount = hotel.RoomsCount
+
+	return updatedHotel, nil
 }
 
 func (dao *PostgresHotelDAO) Delete(hotel *models.Hotel) error {
-	log.Println("[ERROR] PostgresHotelDAO.Delete. Method is not implemented")
-	return serverrors.ErrMethodIsNotImplemented
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Cannot connect to database:", err)
+		return serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Cannot begin transaction:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	var hotelId int
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select id from hotels where hotel_uid = $1 for update;`,
+		hotel.Uid,
+	).Scan(&hotelId)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresHotelDAO.Delete. Entity not found")
+			return serverrors.ErrEntityNotFound
+		}
+
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Error while reading query result:", err)
+		return serverrors.ErrQueryResRead
+	}
+
+	var activeReservsCount int
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select count(*) from reservation where hotel_id = $1 and status in ('PENDING', 'PAID', 'CHECKED_IN') and end_date >= now();`,
+		hotelId,
+	).Scan(&activeReservsCount)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Error while reading query result:", err)
+		return serverrors.ErrQueryResRead
+	}
+
+	if activeReservsCount != 0 {
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Hotel has active reservations")
+		return serverrors.ErrHotelHasActiveReservs
+	}
+
+	_, err = tx.Exec(context.Background(), `update hotels set retired = true where id = $1;`, hotelId)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Error while executing query:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresHotelDAO.Delete. Cannot commit transaction:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	return nil
 }
 
 func (dao *PostgresHotelDAO) DeleteByAttr(attrName string, attrValue string) error {
@@ -0,0 +1,272 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+// Ledger entries are posted together with payment transactions by PostgresPaymentDAO
+// and are never changed afterwards, so this DAO is read-only.
+type PostgresLedgerDAO struct {
+	connStr string
+}
+
+func NewPostgresLedgerDAO(connStr string) ILedgerDAO {
+	return &PostgresLedgerDAO{connStr}
+}
+
+func (dao *PostgresLedgerDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+const ledgerEntryColumns = `e.id, e.transaction_id, p.payment_uid, e.account, e.debit, e.credit, e.created_at`
+
+func scanLedgerEntry(row pgx.Row, entry *models.LedgerEntry) error {
+	var createdAt time.Time
+
+	err := row.Scan(
+		&entry.Id, &entry.TransactionId,
+		&entry.PaymentUid, &entry.Account,
+		&entry.Debit, &entry.Credit,
+		&createdAt,
+	)
+
+	if err == nil {
+		entry.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	}
+
+	return err
+}
+
+func (dao *PostgresLedgerDAO) Create(entry *models.LedgerEntry) (models.LedgerEntry, error) {
+	log.Println("[ERROR] PostgresLedgerDAO.Create. Method is not implemented")
+	return models.LedgerEntry{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLedgerDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresLedgerDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLedgerDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresLedgerDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLedgerDAO) GetById(entry *models.LedgerEntry) (models.LedgerEntry, error) {
+	log.Println("[ERROR] PostgresLedgerDAO.GetById. Method is not implemented")
+	return models.LedgerEntry{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLedgerDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`select %s
+		from ledger_entry e
+		join payment p on p.id = e.payment_id
+		where %s = $1
+		order by e.id;`,
+		ledgerEntryColumns,
+		attrName,
+	)
+
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresLedgerDAO.GetByAttribute. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.LedgerEntry
+		err = scanLedgerEntry(rows, &entry)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresLedgerDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(entry)
+	}
+
+	return resLst, nil
+}
+
+// An empty payment UID means balances over the whole ledger.
+func (dao *PostgresLedgerDAO) GetBalances(paymentUid string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.GetBalances. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(
+		context.Background(),
+		`select e.account, sum(e.debit), sum(e.credit)
+		from ledger_entry e
+		join payment p on p.id = e.payment_id
+		where $1 = '' or p.payment_uid::text = $1
+		group by e.account
+		order by e.account;`,
+		paymentUid,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.GetBalances. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var balance models.LedgerBalance
+		err = rows.Scan(&balance.Account, &balance.Debit, &balance.Credit)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresLedgerDAO.GetBalances. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		balance.Balance = balance.Debit - balance.Credit
+		resLst.PushBack(balance)
+	}
+
+	return resLst, rows.Err()
+}
+
+// Every transaction must have equal debits and credits, and the customer account
+// of every payment must hold the amount captured from it minus refunds.
+func (dao *PostgresLedgerDAO) Check() (checkRes models.LedgerCheckResponse, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.Check. Cannot connect to database:", err)
+		return checkRes, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	err = conn.QueryRow(
+		context.Background(),
+		`select coalesce(sum(debit), 0), coalesce(sum(credit), 0) from ledger_entry;`,
+	).Scan(&checkRes.TotalDebit, &checkRes.TotalCredit)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.Check. Error while reading totals:", err)
+		return checkRes, serverrors.ErrQueryResRead
+	}
+
+	rows, err := conn.Query(
+		context.Background(),
+		`select transaction_id
+		from ledger_entry
+		group by transaction_id
+		having sum(debit) <> sum(credit)
+		order by transaction_id;`,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.Check. Error while executing query:", err)
+		return checkRes, serverrors.ErrQueryExec
+	}
+
+	checkRes.UnbalancedTransactions = []int{}
+
+	for rows.Next() {
+		var transactionId int
+		err = rows.Scan(&transactionId)
+
+		if err != nil {
+			rows.Close()
+			log.Println("[ERROR] PostgresLedgerDAO.Check. Error while reading unbalanced transactions:", err)
+			return checkRes, serverrors.ErrQueryResRead
+		}
+
+		checkRes.UnbalancedTransactions = append(checkRes.UnbalancedTransactions, transactionId)
+	}
+
+	rows.Close()
+
+	rows, err = conn.Query(
+		context.Background(),
+		`select p.payment_uid::text
+		from payment p
+		left join ledger_entry e on e.payment_id = p.id and e.account = 'CUSTOMER'
+		group by p.id
+		having coalesce(sum(e.debit - e.credit), 0) <>
+			case when p.status in ('CAPTURED', 'PARTIALLY_REFUNDED', 'REFUNDED')
+			then p.price - p.refunded_amount else 0 end
+		order by p.id;`,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLedgerDAO.Check. Error while executing query:", err)
+		return checkRes, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	checkRes.MismatchedPayments = []string{}
+
+	for rows.Next() {
+		var paymentUid string
+		err = rows.Scan(&paymentUid)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresLedgerDAO.Check. Error while reading mismatched payments:", err)
+			return checkRes, serverrors.ErrQueryResRead
+		}
+
+		checkRes.MismatchedPayments = append(checkRes.MismatchedPayments, paymentUid)
+	}
+
+	checkRes.Balanced = checkRes.TotalDebit == checkRes.TotalCredit &&
+		len(checkRes.UnbalancedTransactions) == 0 &&
+		len(checkRes.MismatchedPayments) == 0
+
+	return checkRes, nil
+}
+
+func (dao *PostgresLedgerDAO) Update(entry *models.LedgerEntry) (models.LedgerEntry, error) {
+	log.Println("[ERROR] PostgresLedgerDAO.Update. Ledger entries are append-only")
+	return models.LedgerEntry{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLedgerDAO) Delete(entry *models.LedgerEntry) error {
+	log.Println("[ERROR] PostgresLedgerDAO.Delete. Ledger entries are append-only")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLedgerDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresLedgerDAO.DeleteByAttr. Ledger entries are append-only")
+	return serverrors.ErrMethodIsNotImplemented
+}
@@ -17,7 +17,7 @@ type PostgresLoyaltyDAO struct {
 	connStr string
 }
 
-func NewPostgresLoyaltyDAO(connStr string) IDAO[models.Loyalty] {
+func NewPostgresLoyaltyDAO(connStr string) ILoyaltyDAO {
 	return &PostgresLoyaltyDAO{connStr}
 }
 
@@ -30,9 +30,46 @@ func (dao *PostgresLoyaltyDAO) Create(loyalty *models.Loyalty) (models.Loyalty,
 	return models.Loyalty{}, serverrors.ErrMethodIsNotImplemented
 }
 
-func (dao *PostgresLoyaltyDAO) Get() (list.List, error) {
-	log.Println("[ERROR] PostgresLoyaltyDAO.Get. Method is not implemented")
-	return list.List{}, serverrors.ErrMethodIsNotImplemented
+func (dao *PostgresLoyaltyDAO) Get() (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.Get. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(context.Background(), `select * from loyalty order by id;`)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresLoyaltyDAO.Get. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var loyalty models.Loyalty
+		err = rows.Scan(
+			&loyalty.Id, &loyalty.Username,
+			&loyalty.ReservationCount, &loyalty.Status,
+			&loyalty.Discount,
+		)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresLoyaltyDAO.Get. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(loyalty)
+	}
+
+	return resLst, nil
 }
 
 func (dao *PostgresLoyaltyDAO) GetPaginated(
@@ -143,3 +180,182 @@ func (dao *PostgresLoyaltyDAO) DeleteByAttr(attrName string, attrValue string) e
 	log.Println("[ERROR] PostgresLoyaltyDAO.DeleteByAttr. Method is not implemented")
 	return serverrors.ErrMethodIsNotImplemented
 }
+
+func lockLoyalty(tx pgx.Tx, username string) (loyalty models.Loyalty, err error) {
+	err = tx.QueryRow(
+		context.Background(),
+		`select id, username, reservation_count, status, discount from loyalty where username = $1 for update;`,
+		username,
+	).Scan(
+		&loyalty.Id, &loyalty.Username,
+		&loyalty.ReservationCount, &loyalty.Status,
+		&loyalty.Discount,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return loyalty, serverrors.ErrEntityNotFound
+	}
+
+	return loyalty, err
+}
+
+// The status is derived from the events of the rolling window when it is
+// enabled, and from the whole reservation count otherwise.
+func updateLoyaltyCount(tx pgx.Tx, loyalty *models.Loyalty, tierWindowDays int) (err error) {
+	qualifyingCount := loyalty.ReservationCount
+
+	if tierWindowDays > 0 {
+		err = tx.QueryRow(
+			context.Background(),
+			`select count(*) from loyalty_event where username = $1 and created_at > now() - make_interval(days => $2);`,
+			loyalty.Username, tierWindowDays,
+		).Scan(&qualifyingCount)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	models.UpdateLoyaltyStatusByCount(loyalty, qualifyingCount)
+
+	_, err = tx.Exec(
+		context.Background(),
+		`update loyalty set reservation_count = $1, status = $2, discount = $3 where id = $4;`,
+		loyalty.ReservationCount, loyalty.Status, loyalty.Discount, loyalty.Id,
+	)
+
+	return err
+}
+
+// The event of the reservation is added or removed in the same transaction as
+// the count, so a repeated request changes nothing.
+func (dao *PostgresLoyaltyDAO) ChangeReservationCount(
+	event *models.LoyaltyEvent,
+	delta int,
+	tierWindowDays int,
+) (loyalty models.Loyalty, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Cannot connect to database:", err)
+		return loyalty, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Cannot begin transaction:", err)
+		return loyalty, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	loyalty, err = lockLoyalty(tx, event.Username)
+
+	if err != nil {
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Entity not found")
+			return models.Loyalty{}, err
+		}
+
+		log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Error while reading query result:", err)
+		return models.Loyalty{}, serverrors.ErrQueryResRead
+	}
+
+	queryStr := `delete from loyalty_event where username = $1 and reservation_uid = $2;`
+
+	if delta > 0 {
+		queryStr = `insert into loyalty_event (username, reservation_uid)
+		values ($1, $2)
+		on conflict (reservation_uid) do nothing;`
+	}
+
+	tag, err := tx.Exec(context.Background(), queryStr, event.Username, event.ReservationUid)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Error while executing query:", err)
+		return models.Loyalty{}, serverrors.ErrQueryExec
+	}
+
+	if tag.RowsAffected() == 0 {
+		log.Println("[INFO] PostgresLoyaltyDAO.ChangeReservationCount. Reservation is already counted or removed:", event.ReservationUid)
+		return loyalty, nil
+	}
+
+	loyalty.ReservationCount += delta
+	err = updateLoyaltyCount(tx, &loyalty, tierWindowDays)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Error while executing query:", err)
+		return models.Loyalty{}, serverrors.ErrQueryExec
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.ChangeReservationCount. Cannot commit transaction:", err)
+		return models.Loyalty{}, serverrors.ErrQueryExec
+	}
+
+	return loyalty, nil
+}
+
+func (dao *PostgresLoyaltyDAO) CorrectReservationCount(
+	username string,
+	correction *models.LoyaltyCountCorrection,
+	tierWindowDays int,
+) (loyalty models.Loyalty, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Cannot connect to database:", err)
+		return loyalty, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Cannot begin transaction:", err)
+		return loyalty, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	loyalty, err = lockLoyalty(tx, username)
+
+	if err != nil {
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Entity not found")
+			return models.Loyalty{}, err
+		}
+
+		log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Error while reading query result:", err)
+		return models.Loyalty{}, serverrors.ErrQueryResRead
+	}
+
+	if loyalty.ReservationCount != correction.PreviousCount {
+		log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Reservation count was changed")
+		return models.Loyalty{}, serverrors.ErrLoyaltyCountChanged
+	}
+
+	loyalty.ReservationCount = correction.ReservationCount
+	err = updateLoyaltyCount(tx, &loyalty, tierWindowDays)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Error while executing query:", err)
+		return models.Loyalty{}, serverrors.ErrQueryExec
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyDAO.CorrectReservationCount. Cannot commit transaction:", err)
+		return models.Loyalty{}, serverrors.ErrQueryExec
+	}
+
+	return loyalty, nil
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresLoyaltyEventDAO struct {
+	connStr string
+}
+
+func NewPostgresLoyaltyEventDAO(connStr string) IDAO[models.LoyaltyEvent] {
+	return &PostgresLoyaltyEventDAO{connStr}
+}
+
+func (dao *PostgresLoyaltyEventDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+func (dao *PostgresLoyaltyEventDAO) Create(event *models.LoyaltyEvent) (newEvent models.LoyaltyEvent, err error) {
+	if strings.Trim(event.Username, ` `) == `` {
+		log.Println("[ERROR] PostgresLoyaltyEventDAO.Create. Invalid username")
+		return newEvent, serverrors.ErrInvalidUsername
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyEventDAO.Create. Cannot connect to database:", err)
+		return newEvent, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	row := conn.QueryRow(
+		context.Background(),
+		`insert into loyalty_event (username, reservation_uid)
+		values ($1, nullif($2, '')::uuid)
+		returning id, username, coalesce(reservation_uid::text, ''), created_at;`,
+		event.Username, event.ReservationUid,
+	)
+
+	err = row.Scan(&newEvent.Id, &newEvent.Username, &newEvent.ReservationUid, &newEvent.CreatedAt)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyEventDAO.Create. Error while reading query result:", err)
+		err = serverrors.ErrQueryResRead
+	}
+
+	return newEvent, err
+}
+
+func (dao *PostgresLoyaltyEventDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresLoyaltyEventDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLoyaltyEventDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresLoyaltyEventDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLoyaltyEventDAO) GetById(event *models.LoyaltyEvent) (models.LoyaltyEvent, error) {
+	log.Println("[ERROR] PostgresLoyaltyEventDAO.GetById. Method is not implemented")
+	return models.LoyaltyEvent{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLoyaltyEventDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyEventDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`select id, username, coalesce(reservation_uid::text, ''), created_at
+		from loyalty_event
+		where %s = $1
+		order by created_at desc, id desc;`,
+		attrName,
+	)
+
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresLoyaltyEventDAO.GetByAttribute. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.LoyaltyEvent
+		err = rows.Scan(&event.Id, &event.Username, &event.ReservationUid, &event.CreatedAt)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresLoyaltyEventDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(event)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresLoyaltyEventDAO) Update(event *models.LoyaltyEvent) (models.LoyaltyEvent, error) {
+	log.Println("[ERROR] PostgresLoyaltyEventDAO.Update. Method is not implemented")
+	return models.LoyaltyEvent{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresLoyaltyEventDAO) Delete(event *models.LoyaltyEvent) error {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyEventDAO.Delete. Cannot connect to database:", err)
+		return serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(
+		context.Background(),
+		`delete from loyalty_event where id = $1;`,
+		event.Id,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresLoyaltyEventDAO.Delete. Error while executing query:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	return nil
+}
+
+func (dao *PostgresLoyaltyEventDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresLoyaltyEventDAO.DeleteByAttr. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
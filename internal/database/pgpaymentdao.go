@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/jackc/pgx"
@@ -19,7 +20,7 @@ type PostgresPaymentDAO struct {
 	connStr string
 }
 
-func NewPostgresPaymentDAO(connStr string) IDAO[models.Payment] {
+func NewPostgresPaymentDAO(connStr string) IPaymentDAO {
 	return &PostgresPaymentDAO{connStr}
 }
 
@@ -27,18 +28,117 @@ func (dao *PostgresPaymentDAO) SetConnectionString(connStr string) {
 	dao.connStr = connStr
 }
 
+const paymentColumns = `id, payment_uid, status, price, refunded_amount, currency,
+	coalesce(reservation_uid::text, ''), coalesce(idempotency_key, ''), metadata, line_items, updated_at`
+
+func scanPayment(row pgx.Row, payment *models.Payment) error {
+	var updatedAt time.Time
+
+	err := row.Scan(
+		&payment.Id, &payment.Uid,
+		&payment.Status, &payment.Price,
+		&payment.RefundedAmount, &payment.Currency,
+		&payment.ReservationUid, &payment.IdempotencyKey,
+		&payment.Metadata, &payment.LineItems,
+		&updatedAt,
+	)
+
+	payment.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+
+	return err
+}
+
 func validatePayment(payment *models.Payment) (err error) {
 	if uuid.Validate(payment.Uid) != nil {
 		err = serverrors.ErrInvalidPaymentUid
-	} else if payment.Status != `PAID` && payment.Status != `CANCELED` {
+	} else if !models.IsValidPaymentStatus(payment.Status) {
 		err = serverrors.ErrInvalidPaymentStatus
-	} else if payment.Price < 0 {
+	} else if payment.Price < 0 || payment.RefundedAmount < 0 || payment.RefundedAmount > payment.Price {
 		err = serverrors.ErrInvalidPaymentPrice
+	} else if !models.IsValidCurrency(models.NormalizeCurrency(payment.Currency)) {
+		err = serverrors.ErrInvalidCurrency
 	}
 
 	return err
 }
 
+const paymentTransactionColumns = `t.id, p.payment_uid, t.kind, t.amount, coalesce(t.idempotency_key, ''), t.created_at`
+
+func scanPaymentTransaction(row pgx.Row, transaction *models.PaymentTransaction) error {
+	var createdAt time.Time
+
+	err := row.Scan(
+		&transaction.Id, &transaction.PaymentUid,
+		&transaction.Kind, &transaction.Amount,
+		&transaction.IdempotencyKey, &createdAt,
+	)
+
+	if err == nil {
+		transaction.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	}
+
+	return err
+}
+
+func insertPaymentTransaction(tx pgx.Tx, paymentId int, transaction *models.PaymentTransaction, status string) error {
+	return tx.QueryRow(
+		context.Background(),
+		`insert into payment_transaction (payment_id, kind, amount, status, idempotency_key)
+		values ($1, $2, $3, $4, nullif($5, ''))
+		returning id;`,
+		paymentId, transaction.Kind, transaction.Amount, status, transaction.IdempotencyKey,
+	).Scan(&transaction.Id)
+}
+
+func insertLedgerEntries(tx pgx.Tx, paymentId int, transaction *models.PaymentTransaction) error {
+	for _, entry := range transaction.Entries {
+		_, err := tx.Exec(
+			context.Background(),
+			`insert into ledger_entry (transaction_id, payment_id, account, debit, credit)
+			values ($1, $2, $3, $4, $5);`,
+			transaction.Id, paymentId, entry.Account, entry.Debit, entry.Credit,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A transaction stored under the same idempotency key must be the same
+// operation on the same payment; a zero amount matches any stored amount.
+func isTransactionApplied(tx pgx.Tx, paymentId int, transaction *models.PaymentTransaction) (applied bool, err error) {
+	var storedPaymentId, storedAmount int
+	var storedKind, storedStatus string
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select payment_id, kind, amount, status from payment_transaction where idempotency_key = $1;`,
+		transaction.IdempotencyKey,
+	).Scan(&storedPaymentId, &storedKind, &storedAmount, &storedStatus)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, serverrors.ErrQueryResRead
+	}
+
+	if storedPaymentId != paymentId || storedKind != transaction.Kind ||
+		(transaction.Amount != 0 && transaction.Amount != storedAmount) {
+		return false, serverrors.ErrIdempotencyKeyReused
+	}
+
+	if storedStatus == models.PaymentTransactionStatusPending {
+		return false, serverrors.ErrPaymentOperationPending
+	}
+
+	return true, nil
+}
+
 func (dao *PostgresPaymentDAO) Create(payment *models.Payment) (newPayment models.Payment, err error) {
 	err = validatePayment(payment)
 
@@ -56,35 +156,97 @@ func (dao *PostgresPaymentDAO) Create(payment *models.Payment) (newPayment model
 
 	defer conn.Close(context.Background())
 
-	row := conn.QueryRow(
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.Create. Cannot begin transaction:", err)
+		return newPayment, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	metadata := payment.Metadata
+
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+
+	lineItems := payment.LineItems
+
+	if lineItems == nil {
+		lineItems = make([]models.PaymentLineItem, 0)
+	}
+
+	row := tx.QueryRow(
 		context.Background(),
-		`insert into payment (payment_uid, status, price)
-		values ($1, $2, $3)
-		returning *;`,
-		payment.Uid, payment.Status, payment.Price,
+		`insert into payment (payment_uid, status, price, refunded_amount, currency,
+			reservation_uid, idempotency_key, metadata, line_items)
+		values ($1, $2, $3, $4, $5, nullif($6, '')::uuid, nullif($7, ''), $8, $9)
+		returning `+paymentColumns,
+		payment.Uid, payment.Status, payment.Price, payment.RefundedAmount,
+		models.NormalizeCurrency(payment.Currency),
+		payment.ReservationUid, payment.IdempotencyKey, metadata, lineItems,
 	)
 
-	err = row.Scan(
-		&newPayment.Id, &newPayment.Uid,
-		&newPayment.Status, &newPayment.Price,
-	)
+	err = scanPayment(row, &newPayment)
 
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			log.Println("[ERROR] PostgresPaymentDAO.Create. Entity not found")
-			err = serverrors.ErrEntityNotFound
-		} else {
-			log.Println("[ERROR] PostgresPaymentDAO.Create. Error while reading query result:", err)
-			err = serverrors.ErrQueryResRead
+		log.Println("[ERROR] PostgresPaymentDAO.Create. Error while reading query result:", err)
+		return models.Payment{}, serverrors.ErrEntityInsert
+	}
+
+	if newPayment.Status == models.PaymentStatusAuthorized {
+		transaction := models.PaymentTransaction{Kind: models.PaymentTransactionAuthorize, Amount: newPayment.Price}
+		err = insertPaymentTransaction(tx, newPayment.Id, &transaction, models.PaymentTransactionStatusCompleted)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPaymentDAO.Create. Error while recording transaction:", err)
+			return models.Payment{}, serverrors.ErrEntityInsert
 		}
 	}
 
-	return newPayment, err
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.Create. Cannot commit transaction:", err)
+		return models.Payment{}, serverrors.ErrQueryExec
+	}
+
+	return newPayment, nil
 }
 
-func (dao *PostgresPaymentDAO) Get() (list.List, error) {
-	log.Println("[ERROR] PostgresPaymentDAO.Get. Method is not implemented")
-	return list.List{}, serverrors.ErrMethodIsNotImplemented
+func (dao *PostgresPaymentDAO) Get() (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.Get. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(context.Background(), `select `+paymentColumns+` from payment order by id;`)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.Get. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var payment models.Payment
+		err = scanPayment(rows, &payment)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPaymentDAO.Get. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(payment)
+	}
+
+	return resLst, rows.Err()
 }
 
 func (dao *PostgresPaymentDAO) GetPaginated(
@@ -111,7 +273,8 @@ func (dao *PostgresPaymentDAO) GetByAttribute(attrName string, attrValue string)
 	defer conn.Close(context.Background())
 
 	queryStr := fmt.Sprintf(
-		`select * from payment where %s = $1;`,
+		`select %s from payment where %s = $1;`,
+		paymentColumns,
 		attrName,
 	)
 
@@ -130,10 +293,7 @@ func (dao *PostgresPaymentDAO) GetByAttribute(attrName string, attrValue string)
 
 	for rows.Next() {
 		var payment models.Payment
-		err = rows.Scan(
-			&payment.Id, &payment.Uid,
-			&payment.Status, &payment.Price,
-		)
+		err = scanPayment(rows, &payment)
 
 		if err != nil {
 			log.Println("[ERROR] PostgresPaymentDAO.GetByAttribute. Error while reading query result:", err)
@@ -159,17 +319,14 @@ func (dao *PostgresPaymentDAO) Update(payment *models.Payment) (updatedPayment m
 	row := conn.QueryRow(
 		context.Background(),
 		`update payment
-		set status = $1, price = $2
-		where payment_uid = $3
-		returning *`,
-		payment.Status, payment.Price,
+		set status = $1, price = $2, refunded_amount = $3
+		where payment_uid = $4
+		returning `+paymentColumns,
+		payment.Status, payment.Price, payment.RefundedAmount,
 		payment.Uid,
 	)
 
-	err = row.Scan(
-		&updatedPayment.Id, &updatedPayment.Uid,
-		&updatedPayment.Status, &updatedPayment.Price,
-	)
+	err = scanPayment(row, &updatedPayment)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -184,6 +341,459 @@ func (dao *PostgresPaymentDAO) Update(payment *models.Payment) (updatedPayment m
 	return updatedPayment, err
 }
 
+// The transaction is recorded as pending while the payment row is locked, so
+// concurrent transactions on the same payment are checked one at a time and at
+// most one of them is pending. beforeComplete runs between the two database
+// transactions, so no lock is held while the payment provider is called.
+func (dao *PostgresPaymentDAO) ApplyTransaction(
+	paymentUid string,
+	transaction *models.PaymentTransaction,
+	beforeComplete func(*models.PaymentTransaction) error,
+) (updatedPayment models.Payment, err error) {
+	updatedPayment, applied, err := dao.beginTransaction(paymentUid, transaction)
+
+	if err != nil || applied {
+		return updatedPayment, err
+	}
+
+	if beforeComplete != nil {
+		err = beforeComplete(transaction)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPaymentDAO.ApplyTransaction. Transaction is rejected:", err)
+			dao.cancelTransaction(transaction)
+			return models.Payment{}, err
+		}
+	}
+
+	updatedPayment, err = dao.completeTransaction(paymentUid, transaction)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.ApplyTransaction. completeTransaction returned error:", err)
+		dao.cancelTransaction(transaction)
+		return models.Payment{}, err
+	}
+
+	return updatedPayment, nil
+}
+
+// Returns the locked payment unchanged when the transaction was already applied
+// with the same idempotency key.
+func (dao *PostgresPaymentDAO) beginTransaction(
+	paymentUid string,
+	transaction *models.PaymentTransaction,
+) (payment models.Payment, applied bool, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Cannot connect to database:", err)
+		return payment, false, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Cannot begin transaction:", err)
+		return payment, false, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	row := tx.QueryRow(
+		context.Background(),
+		`select `+paymentColumns+` from payment where payment_uid = $1 for update;`,
+		paymentUid,
+	)
+
+	err = scanPayment(row, &payment)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Entity not found")
+			return models.Payment{}, false, serverrors.ErrEntityNotFound
+		}
+
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Error while reading query result:", err)
+		return models.Payment{}, false, serverrors.ErrQueryResRead
+	}
+
+	if transaction.IdempotencyKey != `` {
+		applied, err = isTransactionApplied(tx, payment.Id, transaction)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. isTransactionApplied returned error:", err)
+			return models.Payment{}, false, err
+		}
+
+		if applied {
+			log.Println("[INFO] PostgresPaymentDAO.beginTransaction. Transaction was already applied with idempotency key", transaction.IdempotencyKey)
+			return payment, true, nil
+		}
+	}
+
+	var hasPending bool
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select exists (select 1 from payment_transaction where payment_id = $1 and status = $2);`,
+		payment.Id, models.PaymentTransactionStatusPending,
+	).Scan(&hasPending)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Error while reading query result:", err)
+		return models.Payment{}, false, serverrors.ErrQueryResRead
+	}
+
+	if hasPending {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Another transaction is pending")
+		return models.Payment{}, false, serverrors.ErrPaymentOperationPending
+	}
+
+	// Checked on a copy: the payment is changed when the transaction is completed.
+	checkedPayment := payment
+	err = models.ApplyPaymentTransaction(&checkedPayment, transaction)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Transaction is not applicable:", err)
+		return models.Payment{}, false, err
+	}
+
+	err = insertPaymentTransaction(tx, payment.Id, transaction, models.PaymentTransactionStatusPending)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Error while recording transaction:", err)
+		return models.Payment{}, false, serverrors.ErrEntityInsert
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.beginTransaction. Cannot commit transaction:", err)
+		return models.Payment{}, false, serverrors.ErrQueryExec
+	}
+
+	return payment, false, nil
+}
+
+func (dao *PostgresPaymentDAO) completeTransaction(
+	paymentUid string,
+	transaction *models.PaymentTransaction,
+) (updatedPayment models.Payment, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Cannot connect to database:", err)
+		return updatedPayment, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Cannot begin transaction:", err)
+		return updatedPayment, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	row := tx.QueryRow(
+		context.Background(),
+		`select `+paymentColumns+` from payment where payment_uid = $1 for update;`,
+		paymentUid,
+	)
+
+	err = scanPayment(row, &updatedPayment)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Error while reading query result:", err)
+		return models.Payment{}, serverrors.ErrQueryResRead
+	}
+
+	err = models.ApplyPaymentTransaction(&updatedPayment, transaction)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Transaction is not applicable:", err)
+		return models.Payment{}, err
+	}
+
+	tag, err := tx.Exec(
+		context.Background(),
+		`update payment_transaction set status = $1 where id = $2 and status = $3;`,
+		models.PaymentTransactionStatusCompleted, transaction.Id, models.PaymentTransactionStatusPending,
+	)
+
+	if err != nil || tag.RowsAffected() != 1 {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Pending transaction cannot be completed:", err)
+		return models.Payment{}, serverrors.ErrQueryExec
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		`update payment
+		set status = $1, price = $2, refunded_amount = $3
+		where id = $4;`,
+		updatedPayment.Status, updatedPayment.Price,
+		updatedPayment.RefundedAmount, updatedPayment.Id,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Error while executing query:", err)
+		return models.Payment{}, serverrors.ErrQueryExec
+	}
+
+	err = insertLedgerEntries(tx, updatedPayment.Id, transaction)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Error while recording ledger entries:", err)
+		return models.Payment{}, serverrors.ErrEntityInsert
+	}
+
+	if models.IsPaymentCharged(updatedPayment.Status) {
+		_, err = issueInvoice(tx, updatedPayment.Id)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. issueInvoice returned error:", err)
+			return models.Payment{}, err
+		}
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.completeTransaction. Cannot commit transaction:", err)
+		return models.Payment{}, serverrors.ErrQueryExec
+	}
+
+	return updatedPayment, nil
+}
+
+// Completed transactions are kept; only a pending one is removed.
+func (dao *PostgresPaymentDAO) cancelTransaction(transaction *models.PaymentTransaction) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.cancelTransaction. Cannot connect to database:", err)
+		return
+	}
+
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(
+		context.Background(),
+		`delete from payment_transaction where id = $1 and status = $2;`,
+		transaction.Id, models.PaymentTransactionStatusPending,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.cancelTransaction. Error while executing query:", err)
+	}
+}
+
+func (dao *PostgresPaymentDAO) GetTransactions(paymentUid string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.GetTransactions. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(
+		context.Background(),
+		`select `+paymentTransactionColumns+`
+		from payment_transaction t
+		join payment p on p.id = t.payment_id
+		where p.payment_uid = $1 and t.status = $2
+		order by t.id;`,
+		paymentUid, models.PaymentTransactionStatusCompleted,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.GetTransactions. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var transaction models.PaymentTransaction
+		err = scanPaymentTransaction(rows, &transaction)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPaymentDAO.GetTransactions. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(transaction)
+	}
+
+	return resLst, rows.Err()
+}
+
+const invoiceColumns = `p.payment_uid, i.invoice_number, i.issued_at`
+
+func scanInvoice(row pgx.Row, invoice *models.Invoice) error {
+	var issuedAt time.Time
+
+	err := row.Scan(&invoice.PaymentUid, &invoice.Number, &issuedAt)
+
+	if err == nil {
+		invoice.InvoiceNumber = models.FormatInvoiceNumber(invoice.Number)
+		invoice.IssuedAt = issuedAt.UTC().Format(time.RFC3339)
+	}
+
+	return err
+}
+
+func (dao *PostgresPaymentDAO) GetInvoice(paymentUid string) (invoice models.Invoice, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.GetInvoice. Cannot connect to database:", err)
+		return invoice, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	row := conn.QueryRow(
+		context.Background(),
+		`select `+invoiceColumns+`
+		from invoice i
+		join payment p on p.id = i.payment_id
+		where p.payment_uid = $1;`,
+		paymentUid,
+	)
+
+	err = scanInvoice(row, &invoice)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Invoice{}, serverrors.ErrEntityNotFound
+		}
+
+		log.Println("[ERROR] PostgresPaymentDAO.GetInvoice. Error while reading query result:", err)
+		return models.Invoice{}, serverrors.ErrQueryResRead
+	}
+
+	return invoice, nil
+}
+
+// Looks up the invoice of a locked payment or issues the next number from the
+// counter. The counter row is updated in the caller's transaction, so a rolled
+// back transaction gives its number back.
+func issueInvoice(tx pgx.Tx, paymentId int) (invoice models.Invoice, err error) {
+	row := tx.QueryRow(
+		context.Background(),
+		`select `+invoiceColumns+`
+		from invoice i
+		join payment p on p.id = i.payment_id
+		where i.payment_id = $1;`,
+		paymentId,
+	)
+
+	err = scanInvoice(row, &invoice)
+
+	if err == nil {
+		return invoice, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.Invoice{}, serverrors.ErrQueryResRead
+	}
+
+	row = tx.QueryRow(
+		context.Background(),
+		`with c as (
+			update invoice_counter
+			set last_number = last_number + 1
+			returning last_number
+		), i as (
+			insert into invoice (payment_id, invoice_number)
+			select $1, last_number from c
+			returning payment_id, invoice_number, issued_at
+		)
+		select `+invoiceColumns+`
+		from i
+		join payment p on p.id = i.payment_id;`,
+		paymentId,
+	)
+
+	err = scanInvoice(row, &invoice)
+
+	if err != nil {
+		return models.Invoice{}, serverrors.ErrEntityInsert
+	}
+
+	return invoice, nil
+}
+
+// Invoices are issued when a payment is captured; this covers payments captured
+// before that. The payment row is locked while the invoice is looked up, so
+// concurrent requests for the same payment never take two numbers.
+func (dao *PostgresPaymentDAO) IssueInvoice(paymentUid string) (invoice models.Invoice, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. Cannot connect to database:", err)
+		return invoice, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. Cannot begin transaction:", err)
+		return invoice, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	var paymentId int
+	var status string
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select id, status from payment where payment_uid = $1 for update;`,
+		paymentUid,
+	).Scan(&paymentId, &status)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. Entity not found")
+			return models.Invoice{}, serverrors.ErrEntityNotFound
+		}
+
+		log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. Error while reading query result:", err)
+		return models.Invoice{}, serverrors.ErrQueryResRead
+	}
+
+	if !models.IsPaymentCharged(status) {
+		log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. Payment is not charged, status:", status)
+		return models.Invoice{}, serverrors.ErrPaymentNotCharged
+	}
+
+	invoice, err = issueInvoice(tx, paymentId)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. issueInvoice returned error:", err)
+		return models.Invoice{}, err
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPaymentDAO.IssueInvoice. Cannot commit transaction:", err)
+		return models.Invoice{}, serverrors.ErrQueryExec
+	}
+
+	return invoice, nil
+}
+
 func (dao *PostgresPaymentDAO) Delete(payment *models.Payment) error {
 	log.Println("[ERROR] PostgresPaymentDAO.Delete. Method is not implemented")
 	return serverrors.ErrMethodIsNotImplemented
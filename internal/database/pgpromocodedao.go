@@ -0,0 +1,162 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresPromoCodeDAO struct {
+	connStr string
+}
+
+func NewPostgresPromoCodeDAO(connStr string) IDAO[models.PromoCode] {
+	return &PostgresPromoCodeDAO{connStr}
+}
+
+func (dao *PostgresPromoCodeDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+const promoCodeColumns = `id, code, kind, value, valid_from, valid_to, max_uses, max_uses_per_user, used_count, coalesce(hotel_id, 0), city, stackable`
+
+func scanPromoCode(row pgx.Row, promoCode *models.PromoCode) (err error) {
+	var validFrom, validTo time.Time
+
+	err = row.Scan(
+		&promoCode.Id, &promoCode.Code,
+		&promoCode.Kind, &promoCode.Value,
+		&validFrom, &validTo,
+		&promoCode.MaxUses, &promoCode.MaxUsesPerUser,
+		&promoCode.UsedCount, &promoCode.HotelId,
+		&promoCode.City, &promoCode.Stackable,
+	)
+
+	promoCode.ValidFrom = validFrom.Format(time.DateOnly)
+	promoCode.ValidTo = validTo.Format(time.DateOnly)
+
+	return err
+}
+
+func (dao *PostgresPromoCodeDAO) Create(promoCode *models.PromoCode) (newPromoCode models.PromoCode, err error) {
+	err = models.ValidatePromoCode(promoCode)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeDAO.Create. Invalid promo code data:", err)
+		return newPromoCode, err
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeDAO.Create. Cannot connect to database:", err)
+		return newPromoCode, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	row := conn.QueryRow(
+		context.Background(),
+		`insert into promo_code (code, kind, value, valid_from, valid_to, max_uses, max_uses_per_user, hotel_id, city, stackable)
+		values ($1, $2, $3, $4, $5, $6, $7, nullif($8, 0), $9, $10)
+		returning `+promoCodeColumns+`;`,
+		promoCode.Code, promoCode.Kind, promoCode.Value,
+		promoCode.ValidFrom, promoCode.ValidTo,
+		promoCode.MaxUses, promoCode.MaxUsesPerUser,
+		promoCode.HotelId, promoCode.City, promoCode.Stackable,
+	)
+
+	err = scanPromoCode(row, &newPromoCode)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeDAO.Create. Error while reading query result:", err)
+		err = serverrors.ErrEntityInsert
+	}
+
+	return newPromoCode, err
+}
+
+func (dao *PostgresPromoCodeDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresPromoCodeDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresPromoCodeDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeDAO) GetById(promoCode *models.PromoCode) (models.PromoCode, error) {
+	log.Println("[ERROR] PostgresPromoCodeDAO.GetById. Method is not implemented")
+	return models.PromoCode{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`select %s from promo_code where %s = $1;`,
+		promoCodeColumns,
+		attrName,
+	)
+
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresPromoCodeDAO.GetByAttribute. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var promoCode models.PromoCode
+		err = scanPromoCode(rows, &promoCode)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPromoCodeDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(promoCode)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresPromoCodeDAO) Update(promoCode *models.PromoCode) (models.PromoCode, error) {
+	log.Println("[ERROR] PostgresPromoCodeDAO.Update. Method is not implemented")
+	return models.PromoCode{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeDAO) Delete(promoCode *models.PromoCode) error {
+	log.Println("[ERROR] PostgresPromoCodeDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresPromoCodeDAO.DeleteByAttr. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
@@ -0,0 +1,252 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresPromoCodeUsageDAO struct {
+	connStr string
+}
+
+func NewPostgresPromoCodeUsageDAO(connStr string) IDAO[models.PromoCodeUsage] {
+	return &PostgresPromoCodeUsageDAO{connStr}
+}
+
+func (dao *PostgresPromoCodeUsageDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+func (dao *PostgresPromoCodeUsageDAO) Create(usage *models.PromoCodeUsage) (newUsage models.PromoCodeUsage, err error) {
+	if strings.Trim(usage.Username, ` `) == `` || uuid.Validate(usage.ReservationUid) != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Invalid promo code usage data")
+		return newUsage, serverrors.ErrInvalidPromoCode
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Cannot connect to database:", err)
+		return newUsage, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Cannot begin transaction:", err)
+		return newUsage, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	var promoCodeId, maxUses, maxUsesPerUser, usedCount, userUsedCount int
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select id, max_uses, max_uses_per_user, used_count
+		from promo_code
+		where code = $1
+		for update;`,
+		usage.Code,
+	).Scan(&promoCodeId, &maxUses, &maxUsesPerUser, &usedCount)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Promo code not found")
+			return newUsage, serverrors.ErrEntityNotFound
+		}
+
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Error while reading query result:", err)
+		return newUsage, serverrors.ErrQueryResRead
+	}
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select count(*) from promo_code_usage where promo_code_id = $1 and username = $2;`,
+		promoCodeId, usage.Username,
+	).Scan(&userUsedCount)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Error while reading query result:", err)
+		return newUsage, serverrors.ErrQueryResRead
+	}
+
+	if (maxUses > 0 && usedCount >= maxUses) || (maxUsesPerUser > 0 && userUsedCount >= maxUsesPerUser) {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Promo code usage limit is reached")
+		return newUsage, serverrors.ErrPromoCodeExhausted
+	}
+
+	_, err = tx.Exec(
+		context.Background(),
+		`update promo_code set used_count = used_count + 1 where id = $1;`,
+		promoCodeId,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Error while executing query:", err)
+		return newUsage, serverrors.ErrQueryExec
+	}
+
+	newUsage = *usage
+	newUsage.PromoCodeId = promoCodeId
+
+	err = tx.QueryRow(
+		context.Background(),
+		`insert into promo_code_usage (promo_code_id, username, reservation_uid)
+		values ($1, $2, $3)
+		returning id;`,
+		promoCodeId, usage.Username, usage.ReservationUid,
+	).Scan(&newUsage.Id)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Error while reading query result:", err)
+		return models.PromoCodeUsage{}, serverrors.ErrEntityInsert
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.Create. Cannot commit transaction:", err)
+		return models.PromoCodeUsage{}, serverrors.ErrQueryExec
+	}
+
+	return newUsage, nil
+}
+
+func (dao *PostgresPromoCodeUsageDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresPromoCodeUsageDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeUsageDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresPromoCodeUsageDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeUsageDAO) GetById(usage *models.PromoCodeUsage) (models.PromoCodeUsage, error) {
+	log.Println("[ERROR] PostgresPromoCodeUsageDAO.GetById. Method is not implemented")
+	return models.PromoCodeUsage{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeUsageDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`select u.id, u.promo_code_id, p.code, u.username, u.reservation_uid
+		from promo_code_usage u join promo_code p on p.id = u.promo_code_id
+		where u.%s = $1;`,
+		attrName,
+	)
+
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresPromoCodeUsageDAO.GetByAttribute. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var usage models.PromoCodeUsage
+		err = rows.Scan(
+			&usage.Id, &usage.PromoCodeId,
+			&usage.Code, &usage.Username,
+			&usage.ReservationUid,
+		)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresPromoCodeUsageDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(usage)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresPromoCodeUsageDAO) Update(usage *models.PromoCodeUsage) (models.PromoCodeUsage, error) {
+	log.Println("[ERROR] PostgresPromoCodeUsageDAO.Update. Method is not implemented")
+	return models.PromoCodeUsage{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresPromoCodeUsageDAO) Delete(usage *models.PromoCodeUsage) error {
+	log.Println("[ERROR] PostgresPromoCodeUsageDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+// The used count of every affected promo code is decreased in the same
+// transaction, so a released usage frees its place under the cap.
+func (dao *PostgresPromoCodeUsageDAO) DeleteByAttr(attrName string, attrValue string) error {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.DeleteByAttr. Cannot connect to database:", err)
+		return serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.DeleteByAttr. Cannot begin transaction:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`with deleted as (
+			delete from promo_code_usage where %s = $1 returning promo_code_id
+		)
+		update promo_code p
+		set used_count = greatest(p.used_count - d.count, 0)
+		from (select promo_code_id, count(*) as count from deleted group by promo_code_id) d
+		where p.id = d.promo_code_id;`,
+		attrName,
+	)
+
+	_, err = tx.Exec(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.DeleteByAttr. Error while executing query:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresPromoCodeUsageDAO.DeleteByAttr. Cannot commit transaction:", err)
+		return serverrors.ErrQueryExec
+	}
+
+	return nil
+}
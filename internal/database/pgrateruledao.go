@@ -0,0 +1,249 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresRateRuleDAO struct {
+	connStr string
+}
+
+func NewPostgresRateRuleDAO(connStr string) IRateRuleDAO {
+	return &PostgresRateRuleDAO{connStr}
+}
+
+func (dao *PostgresRateRuleDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+const rateRuleColumns = `id, hotel_id, kind, valid_from, valid_to, weekdays, percent, min_nights`
+
+func scanRateRule(row pgx.Row, rule *models.RateRule) (err error) {
+	var validFrom, validTo *time.Time
+
+	err = row.Scan(
+		&rule.Id, &rule.HotelId,
+		&rule.Kind, &validFrom, &validTo,
+		&rule.Weekdays, &rule.Percent,
+		&rule.MinNights,
+	)
+
+	if validFrom != nil {
+		rule.ValidFrom = validFrom.Format(time.DateOnly)
+	}
+
+	if validTo != nil {
+		rule.ValidTo = validTo.Format(time.DateOnly)
+	}
+
+	return err
+}
+
+func insertRateRule(tx pgx.Tx, rule *models.RateRule) (newRule models.RateRule, err error) {
+	weekdays := rule.Weekdays
+
+	if weekdays == nil {
+		weekdays = []int{}
+	}
+
+	row := tx.QueryRow(
+		context.Background(),
+		`insert into rate_rule (hotel_id, kind, valid_from, valid_to, weekdays, percent, min_nights)
+		values ($1, $2, nullif($3, '')::date, nullif($4, '')::date, $5, $6, $7)
+		returning `+rateRuleColumns+`;`,
+		rule.HotelId, rule.Kind,
+		rule.ValidFrom, rule.ValidTo,
+		weekdays, rule.Percent, rule.MinNights,
+	)
+
+	err = scanRateRule(row, &newRule)
+
+	return newRule, err
+}
+
+func (dao *PostgresRateRuleDAO) Create(rule *models.RateRule) (newRule models.RateRule, err error) {
+	_, err = models.ValidateRateRule(rule)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.Create. Invalid rate rule data:", err)
+		return newRule, err
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.Create. Cannot connect to database:", err)
+		return newRule, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.Create. Cannot begin transaction:", err)
+		return newRule, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	newRule, err = insertRateRule(tx, rule)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.Create. Error while reading query result:", err)
+		return models.RateRule{}, serverrors.ErrEntityInsert
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.Create. Cannot commit transaction:", err)
+		return models.RateRule{}, serverrors.ErrQueryExec
+	}
+
+	return newRule, nil
+}
+
+func (dao *PostgresRateRuleDAO) ReplaceByHotel(hotelId int, rules []models.RateRule) (resLst list.List, err error) {
+	for i := range rules {
+		_, err = models.ValidateRateRule(&rules[i])
+
+		if err != nil {
+			log.Println("[ERROR] PostgresRateRuleDAO.ReplaceByHotel. Invalid rate rule data:", err)
+			return resLst, err
+		}
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.ReplaceByHotel. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.ReplaceByHotel. Cannot begin transaction:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(context.Background(), `delete from rate_rule where hotel_id = $1;`, hotelId)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.ReplaceByHotel. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	for i := range rules {
+		rules[i].HotelId = hotelId
+		newRule, err := insertRateRule(tx, &rules[i])
+
+		if err != nil {
+			log.Println("[ERROR] PostgresRateRuleDAO.ReplaceByHotel. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrEntityInsert
+		}
+
+		resLst.PushBack(newRule)
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.ReplaceByHotel. Cannot commit transaction:", err)
+		return list.List{}, serverrors.ErrQueryExec
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresRateRuleDAO) Get() (list.List, error) {
+	log.Println("[ERROR] PostgresRateRuleDAO.Get. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresRateRuleDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresRateRuleDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresRateRuleDAO) GetById(rule *models.RateRule) (models.RateRule, error) {
+	log.Println("[ERROR] PostgresRateRuleDAO.GetById. Method is not implemented")
+	return models.RateRule{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresRateRuleDAO) GetByAttribute(attrName string, attrValue string) (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresRateRuleDAO.GetByAttribute. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	queryStr := fmt.Sprintf(
+		`select %s from rate_rule where %s = $1 order by id;`,
+		rateRuleColumns,
+		attrName,
+	)
+
+	rows, err := conn.Query(context.Background(), queryStr, attrValue)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresRateRuleDAO.GetByAttribute. Error while executing query:", err)
+			return resLst, serverrors.ErrQueryResRead
+		}
+
+		return resLst, nil
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule models.RateRule
+		err = scanRateRule(rows, &rule)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresRateRuleDAO.GetByAttribute. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(rule)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresRateRuleDAO) Update(rule *models.RateRule) (models.RateRule, error) {
+	log.Println("[ERROR] PostgresRateRuleDAO.Update. Method is not implemented")
+	return models.RateRule{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresRateRuleDAO) Delete(rule *models.RateRule) error {
+	log.Println("[ERROR] PostgresRateRuleDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresRateRuleDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresRateRuleDAO.DeleteByAttr. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
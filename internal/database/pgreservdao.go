@@ -21,7 +21,7 @@ type PostgresReservationDAO struct {
 	connStr string
 }
 
-func NewPostgresReservationDAO(connStr string) IDAO[models.Reservation] {
+func NewPostgresReservationDAO(connStr string) IReservationDAO {
 	return &PostgresReservationDAO{connStr}
 }
 
@@ -29,6 +29,51 @@ func (dao *PostgresReservationDAO) SetConnectionString(connStr string) {
 	dao.connStr = connStr
 }
 
+const reservationColumns = `id, reservation_uid, username, payment_uid, hotel_id, status, start_date, end_date,
+	nightly_rate, nights, discount_percent, discount_source, total_price, tax_amount, guests,
+	coalesce(room_type_id, 0), promo_code, hold_expires_at, updated_at`
+
+// Rooms held by unexpired PENDING reservations are counted as booked.
+const activeReservCondition = `(r.status in ('PAID', 'CHECKED_IN') or (r.status = 'PENDING' and r.hold_expires_at > now()))`
+
+const bookedRoomsQuery = `select coalesce(max(booked), 0)
+	from (
+		select (
+			select count(*)
+			from reservation r
+			where r.room_type_id = $1 and ` + activeReservCondition + ` and r.start_date <= night and r.end_date > night
+				and r.reservation_uid <> $4
+		) booked
+		from generate_series($2::date, $3::date - 1, interval '1 day') night
+	) nights;`
+
+func scanReservation(row pgx.Row, reservation *models.Reservation) (err error) {
+	var startDate, endDate, updatedAt time.Time
+	var holdExpiresAt *time.Time
+
+	err = row.Scan(
+		&reservation.Id, &reservation.Uid,
+		&reservation.Username, &reservation.PaymentUid,
+		&reservation.HotelId, &reservation.Status,
+		&startDate, &endDate,
+		&reservation.NightlyRate, &reservation.Nights,
+		&reservation.DiscountPercent, &reservation.DiscountSource,
+		&reservation.TotalPrice, &reservation.TaxAmount,
+		&reservation.Guests, &reservation.RoomTypeId,
+		&reservation.PromoCode, &holdExpiresAt, &updatedAt,
+	)
+
+	reservation.StartDate = startDate.Format(time.DateOnly)
+	reservation.EndDate = endDate.Format(time.DateOnly)
+	reservation.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+
+	if holdExpiresAt != nil {
+		reservation.HoldExpiresAt = holdExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	return err
+}
+
 func validateReservation(reservation *models.Reservation) (err error) {
 	if uuid.Validate(reservation.Uid) != nil {
 		err = serverrors.ErrInvalidReservUid
@@ -38,7 +83,7 @@ func validateReservation(reservation *models.Reservation) (err error) {
 		err = serverrors.ErrInvalidReservPayUID
 	} else if reservation.HotelId <= 0 {
 		err = serverrors.ErrInvalidReservHotelId
-	} else if reservation.Status != `PAID` && reservation.Status != `CANCELED` {
+	} else if !models.IsValidReservStatus(reservation.Status) {
 		err = serverrors.ErrInvalidReservStatus
 	} else if reservation.StartDate > reservation.EndDate {
 		err = serverrors.ErrInvalidReservDates
@@ -64,36 +109,152 @@ func (dao *PostgresReservationDAO) Create(reservation *models.Reservation) (newR
 
 	defer conn.Close(context.Background())
 
-	row := conn.QueryRow(
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Create. Cannot begin transaction:", err)
+		return newReservation, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	newReservation = *reservation
+
+	// The shared lock keeps the hotel from being retired until the reservation
+	// is committed, so retiring sees it among the active reservations.
+	var hotelRetired bool
+
+	err = tx.QueryRow(
 		context.Background(),
-		`insert into reservation (reservation_uid, username, payment_uid, hotel_id, status, start_date, end_date)
-		values ($1, $2, $3, $4, $5, $6, $7)
+		`select retired from hotels where id = $1 for share;`,
+		reservation.HotelId,
+	).Scan(&hotelRetired)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresReservationDAO.Create. Hotel not found")
+			return models.Reservation{}, serverrors.ErrHotelNotFound
+		}
+
+		log.Println("[ERROR] PostgresReservationDAO.Create. Error while reading query result:", err)
+		return models.Reservation{}, serverrors.ErrQueryResRead
+	}
+
+	if hotelRetired {
+		log.Println("[ERROR] PostgresReservationDAO.Create. Hotel is retired")
+		return models.Reservation{}, serverrors.ErrHotelNotFound
+	}
+
+	var roomsCount int
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select id, rooms_count
+		from room_type
+		where hotel_id = $1 and ($2 = '' or room_type_uid::text = $2)
+		order by id
+		limit 1
+		for update;`,
+		reservation.HotelId, reservation.RoomTypeUid,
+	).Scan(&newReservation.RoomTypeId, &roomsCount)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Println("[ERROR] PostgresReservationDAO.Create. Room type not found")
+			return models.Reservation{}, serverrors.ErrNoRoomsAvailable
+		}
+
+		log.Println("[ERROR] PostgresReservationDAO.Create. Error while reading query result:", err)
+		return models.Reservation{}, serverrors.ErrQueryResRead
+	}
+
+	if reservation.Status == models.ReservStatusPaid || reservation.Status == models.ReservStatusPending {
+		var bookedCount int
+
+		err = tx.QueryRow(
+			context.Background(),
+			bookedRoomsQuery,
+			newReservation.RoomTypeId, reservation.StartDate, reservation.EndDate, reservation.Uid,
+		).Scan(&bookedCount)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresReservationDAO.Create. Error while reading query result:", err)
+			return models.Reservation{}, serverrors.ErrQueryResRead
+		}
+
+		if bookedCount >= roomsCount {
+			log.Println("[ERROR] PostgresReservationDAO.Create. No rooms available for requested dates")
+			return models.Reservation{}, serverrors.ErrNoRoomsAvailable
+		}
+	}
+
+	err = tx.QueryRow(
+		context.Background(),
+		`insert into reservation (
+			reservation_uid, username, payment_uid, hotel_id, status, start_date, end_date,
+			nightly_rate, nights, discount_percent, discount_source, total_price, room_type_id, hold_expires_at,
+			tax_amount, guests, promo_code
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, nullif($14, '')::timestamptz, $15, $16, $17)
 		returning id;`,
 		reservation.Uid, reservation.Username,
 		reservation.PaymentUid, reservation.HotelId,
 		reservation.Status, reservation.StartDate,
-		reservation.EndDate,
-	)
+		reservation.EndDate, reservation.NightlyRate,
+		reservation.Nights, reservation.DiscountPercent,
+		reservation.DiscountSource, reservation.TotalPrice,
+		newReservation.RoomTypeId, reservation.HoldExpiresAt,
+		reservation.TaxAmount, max(reservation.Guests, models.DefaultGuests),
+		reservation.PromoCode,
+	).Scan(&newReservation.Id)
 
-	newReservation = *reservation
-	err = row.Scan(&newReservation.Id)
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Create. Error while reading query result:", err)
+		return models.Reservation{}, serverrors.ErrEntityInsert
+	}
+
+	err = tx.Commit(context.Background())
 
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			log.Println("[ERROR] PostgresReservationDAO.Create. Entity not found")
-			err = serverrors.ErrEntityNotFound
-		} else {
-			log.Println("[ERROR] PostgresReservationDAO.Create. Error while reading query result:", err)
-			err = serverrors.ErrQueryResRead
-		}
+		log.Println("[ERROR] PostgresReservationDAO.Create. Cannot commit transaction:", err)
+		return models.Reservation{}, serverrors.ErrQueryExec
 	}
 
-	return newReservation, err
+	return newReservation, nil
 }
 
-func (dao *PostgresReservationDAO) Get() (list.List, error) {
-	log.Println("[ERROR] PostgresReservationDAO.Get. Method is not implemented")
-	return list.List{}, serverrors.ErrMethodIsNotImplemented
+func (dao *PostgresReservationDAO) Get() (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Get. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(context.Background(), `select `+reservationColumns+` from reservation order by id;`)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Get. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var reservation models.Reservation
+		err = scanReservation(rows, &reservation)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresReservationDAO.Get. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(reservation)
+	}
+
+	return resLst, rows.Err()
 }
 
 func (dao *PostgresReservationDAO) GetPaginated(
@@ -120,7 +281,8 @@ func (dao *PostgresReservationDAO) GetByAttribute(attrName string, attrValue str
 	defer conn.Close(context.Background())
 
 	queryStr := fmt.Sprintf(
-		`select id, reservation_uid, username, payment_uid, hotel_id, status, start_date, end_date from reservation where %s = $1;`,
+		`select %s from reservation where %s = $1;`,
+		reservationColumns,
 		attrName,
 	)
 
@@ -139,28 +301,94 @@ func (dao *PostgresReservationDAO) GetByAttribute(attrName string, attrValue str
 
 	for rows.Next() {
 		var reservation models.Reservation
-		var startDate, endDate time.Time
-		err = rows.Scan(
-			&reservation.Id, &reservation.Uid,
-			&reservation.Username, &reservation.PaymentUid,
-			&reservation.HotelId, &reservation.Status,
-			&startDate, &endDate,
-		)
+		err = scanReservation(rows, &reservation)
 
 		if err != nil {
 			log.Println("[ERROR] PostgresReservationDAO.GetByAttribute. Error while reading query result:", err)
 			return list.List{}, serverrors.ErrQueryResRead
 		}
 
-		reservation.StartDate = startDate.Format(time.DateOnly)
-		reservation.EndDate = endDate.Format(time.DateOnly)
-
 		resLst.PushBack(reservation)
 	}
 
 	return resLst, nil
 }
 
+func (dao *PostgresReservationDAO) checkUpdateAvailability(
+	tx pgx.Tx,
+	reservation *models.Reservation,
+) (err error) {
+	var roomTypeId int
+	var status string
+	var startDate, endDate time.Time
+	var holdActive, holdExpired bool
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select coalesce(room_type_id, 0), status, start_date, end_date,
+			coalesce(hold_expires_at > now(), false), coalesce(hold_expires_at <= now(), false)
+		from reservation
+		where reservation_uid = $1
+		for update;`,
+		reservation.Uid,
+	).Scan(&roomTypeId, &status, &startDate, &endDate, &holdActive, &holdExpired)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return serverrors.ErrEntityNotFound
+		}
+
+		return serverrors.ErrQueryResRead
+	}
+
+	// The transition is checked against the locked row, so concurrent updates
+	// cannot both leave the same status.
+	if !models.CanChangeReservStatus(status, reservation.Status) {
+		return serverrors.ErrInvalidStatusTransition
+	}
+
+	if status == models.ReservStatusPending && reservation.Status == models.ReservStatusPaid && holdExpired {
+		return serverrors.ErrHoldExpired
+	}
+
+	datesChanged := startDate.Format(time.DateOnly) != reservation.StartDate ||
+		endDate.Format(time.DateOnly) != reservation.EndDate
+
+	roomHeld := status == models.ReservStatusPaid || (status == models.ReservStatusPending && holdActive)
+
+	if roomTypeId == 0 || reservation.Status != models.ReservStatusPaid || (roomHeld && !datesChanged) {
+		return nil
+	}
+
+	var roomsCount, bookedCount int
+
+	err = tx.QueryRow(
+		context.Background(),
+		`select rooms_count from room_type where id = $1 for update;`,
+		roomTypeId,
+	).Scan(&roomsCount)
+
+	if err != nil {
+		return serverrors.ErrQueryResRead
+	}
+
+	err = tx.QueryRow(
+		context.Background(),
+		bookedRoomsQuery,
+		roomTypeId, reservation.StartDate, reservation.EndDate, reservation.Uid,
+	).Scan(&bookedCount)
+
+	if err != nil {
+		return serverrors.ErrQueryResRead
+	}
+
+	if bookedCount >= roomsCount {
+		return serverrors.ErrNoRoomsAvailable
+	}
+
+	return nil
+}
+
 func (dao *PostgresReservationDAO) Update(reservation *models.Reservation) (updatedReservation models.Reservation, err error) {
 	conn, err := pgx.Connect(context.Background(), dao.connStr)
 
@@ -171,42 +399,101 @@ func (dao *PostgresReservationDAO) Update(reservation *models.Reservation) (upda
 
 	defer conn.Close(context.Background())
 
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Update. Cannot begin transaction:", err)
+		return updatedReservation, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
 	log.Println("[TRACE] PostgresReservationDAO.Update. Status =", reservation.Status)
 
-	row := conn.QueryRow(
+	err = dao.checkUpdateAvailability(tx, reservation)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Update. Availability check failed:", err)
+		return updatedReservation, err
+	}
+
+	row := tx.QueryRow(
 		context.Background(),
 		`update reservation
-		set username = $1, payment_uid = $2, hotel_id = $3, status = $4, start_date = $5, end_date = $6
-		where reservation_uid = $7
-		returning *`,
+		set username = $1, payment_uid = $2, hotel_id = $3, status = $4, start_date = $5, end_date = $6,
+			nightly_rate = $7, nights = $8, discount_percent = $9, discount_source = $10, total_price = $11,
+			tax_amount = $12, guests = $13, promo_code = $14,
+			hold_expires_at = case when $4 = 'PENDING' then hold_expires_at end
+		where reservation_uid = $15
+		returning `+reservationColumns,
 		reservation.Username, reservation.PaymentUid, reservation.HotelId,
 		reservation.Status, reservation.StartDate, reservation.EndDate,
-		reservation.Uid,
+		reservation.NightlyRate, reservation.Nights, reservation.DiscountPercent,
+		reservation.DiscountSource, reservation.TotalPrice,
+		reservation.TaxAmount, max(reservation.Guests, models.DefaultGuests),
+		reservation.PromoCode, reservation.Uid,
 	)
 
-	var startDate, endDate time.Time
-
-	err = row.Scan(
-		&updatedReservation.Id, &updatedReservation.Uid,
-		&updatedReservation.Username, &updatedReservation.PaymentUid,
-		&updatedReservation.HotelId, &updatedReservation.Status,
-		&startDate, &endDate,
-	)
+	err = scanReservation(row, &updatedReservation)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Println("[ERROR] PostgresReservationDAO.Update. Entity not found")
-			err = serverrors.ErrEntityNotFound
-		} else {
-			log.Println("[ERROR] PostgresReservationDAO.Update. Error while reading query result:", err)
-			err = serverrors.ErrQueryResRead
+			return models.Reservation{}, serverrors.ErrEntityNotFound
 		}
+
+		log.Println("[ERROR] PostgresReservationDAO.Update. Error while reading query result:", err)
+		return models.Reservation{}, serverrors.ErrQueryResRead
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.Update. Cannot commit transaction:", err)
+		return models.Reservation{}, serverrors.ErrQueryExec
+	}
+
+	return updatedReservation, nil
+}
+
+func (dao *PostgresReservationDAO) ExpireHolds() (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.ExpireHolds. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
 	}
 
-	updatedReservation.StartDate = startDate.Format(time.DateOnly)
-	updatedReservation.EndDate = endDate.Format(time.DateOnly)
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(
+		context.Background(),
+		`update reservation
+		set status = 'CANCELED', hold_expires_at = null
+		where status = 'PENDING' and hold_expires_at <= now()
+		returning `+reservationColumns,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresReservationDAO.ExpireHolds. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var reservation models.Reservation
+		err = scanReservation(rows, &reservation)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresReservationDAO.ExpireHolds. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(reservation)
+	}
 
-	return updatedReservation, err
+	return resLst, rows.Err()
 }
 
 func (dao *PostgresReservationDAO) Delete(reservation *models.Reservation) error {
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type PostgresTaxRuleDAO struct {
+	connStr string
+}
+
+func NewPostgresTaxRuleDAO(connStr string) ITaxRuleDAO {
+	return &PostgresTaxRuleDAO{connStr}
+}
+
+func (dao *PostgresTaxRuleDAO) SetConnectionString(connStr string) {
+	dao.connStr = connStr
+}
+
+const taxRuleColumns = `id, kind, name, country, coalesce(city, ''), percent, amount`
+
+func scanTaxRule(row pgx.Row, rule *models.TaxRule) error {
+	return row.Scan(
+		&rule.Id, &rule.Kind,
+		&rule.Name, &rule.Country,
+		&rule.City, &rule.Percent,
+		&rule.Amount,
+	)
+}
+
+func insertTaxRule(tx pgx.Tx, rule *models.TaxRule) (newRule models.TaxRule, err error) {
+	row := tx.QueryRow(
+		context.Background(),
+		`insert into tax_rule (kind, name, country, city, percent, amount)
+		values ($1, $2, $3, nullif($4, ''), $5, $6)
+		returning `+taxRuleColumns+`;`,
+		rule.Kind, rule.Name,
+		rule.Country, rule.City,
+		rule.Percent, rule.Amount,
+	)
+
+	err = scanTaxRule(row, &newRule)
+
+	return newRule, err
+}
+
+func (dao *PostgresTaxRuleDAO) Create(rule *models.TaxRule) (models.TaxRule, error) {
+	log.Println("[ERROR] PostgresTaxRuleDAO.Create. Method is not implemented")
+	return models.TaxRule{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresTaxRuleDAO) ReplaceAll(rules []models.TaxRule) (resLst list.List, err error) {
+	_, err = models.ValidateTaxRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.ReplaceAll. Invalid tax rules:", err)
+		return resLst, err
+	}
+
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.ReplaceAll. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.ReplaceAll. Cannot begin transaction:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(context.Background(), `delete from tax_rule;`)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.ReplaceAll. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	for i := range rules {
+		newRule, err := insertTaxRule(tx, &rules[i])
+
+		if err != nil {
+			log.Println("[ERROR] PostgresTaxRuleDAO.ReplaceAll. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrEntityInsert
+		}
+
+		resLst.PushBack(newRule)
+	}
+
+	err = tx.Commit(context.Background())
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.ReplaceAll. Cannot commit transaction:", err)
+		return list.List{}, serverrors.ErrQueryExec
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresTaxRuleDAO) Get() (resLst list.List, err error) {
+	conn, err := pgx.Connect(context.Background(), dao.connStr)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.Get. Cannot connect to database:", err)
+		return resLst, serverrors.ErrDatabaseConnection
+	}
+
+	defer conn.Close(context.Background())
+
+	rows, err := conn.Query(context.Background(), `select `+taxRuleColumns+` from tax_rule order by id;`)
+
+	if err != nil {
+		log.Println("[ERROR] PostgresTaxRuleDAO.Get. Error while executing query:", err)
+		return resLst, serverrors.ErrQueryExec
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule models.TaxRule
+		err = scanTaxRule(rows, &rule)
+
+		if err != nil {
+			log.Println("[ERROR] PostgresTaxRuleDAO.Get. Error while reading query result:", err)
+			return list.List{}, serverrors.ErrQueryResRead
+		}
+
+		resLst.PushBack(rule)
+	}
+
+	return resLst, nil
+}
+
+func (dao *PostgresTaxRuleDAO) GetPaginated(
+	page int,
+	pageSize int,
+) (resLst list.List, err error) {
+	log.Println("[ERROR] PostgresTaxRuleDAO.GetPaginated. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresTaxRuleDAO) GetById(rule *models.TaxRule) (models.TaxRule, error) {
+	log.Println("[ERROR] PostgresTaxRuleDAO.GetById. Method is not implemented")
+	return models.TaxRule{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresTaxRuleDAO) GetByAttribute(attrName string, attrValue string) (list.List, error) {
+	log.Println("[ERROR] PostgresTaxRuleDAO.GetByAttribute. Method is not implemented")
+	return list.List{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresTaxRuleDAO) Update(rule *models.TaxRule) (models.TaxRule, error) {
+	log.Println("[ERROR] PostgresTaxRuleDAO.Update. Method is not implemented")
+	return models.TaxRule{}, serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresTaxRuleDAO) Delete(rule *models.TaxRule) error {
+	log.Println("[ERROR] PostgresTaxRuleDAO.Delete. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
+
+func (dao *PostgresTaxRuleDAO) DeleteByAttr(attrName string, attrValue string) error {
+	log.Println("[ERROR] PostgresTaxRuleDAO.DeleteByAttr. Method is not implemented")
+	return serverrors.ErrMethodIsNotImplemented
+}
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+const exchangeRateDigits = 8
+
+// Rates are fixed-point numbers with eight fractional digits, so 0.0108 is
+// kept as 1080000 and no conversion ever goes through floating point.
+const ExchangeRateScale = 100000000
+
+type ExchangeRateValue int64
+
+// One unit of From costs Rate units of To.
+type ExchangeRate struct {
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Rate      ExchangeRateValue `json:"rate"`
+	UpdatedAt string            `json:"updatedAt,omitempty"`
+}
+
+func ParseExchangeRateValue(str string) (value ExchangeRateValue, err error) {
+	intPart, fracPart, _ := strings.Cut(strings.TrimSpace(str), `.`)
+
+	if intPart == `` && fracPart == `` || len(intPart) > 10 || len(fracPart) > exchangeRateDigits {
+		return 0, serverrors.ErrInvalidExchangeRate
+	}
+
+	for _, digit := range intPart + fracPart {
+		if digit < '0' || digit > '9' {
+			return 0, serverrors.ErrInvalidExchangeRate
+		}
+	}
+
+	fracPart += strings.Repeat(`0`, exchangeRateDigits-len(fracPart))
+
+	parsed, err := strconv.ParseInt(`0`+intPart+fracPart, 10, 64)
+
+	if err != nil {
+		return 0, serverrors.ErrInvalidExchangeRate
+	}
+
+	return ExchangeRateValue(parsed), nil
+}
+
+func (value ExchangeRateValue) String() string {
+	str := fmt.Sprintf(`%d.%0*d`, value/ExchangeRateScale, exchangeRateDigits, value%ExchangeRateScale)
+
+	return strings.TrimSuffix(strings.TrimRight(str, `0`), `.`)
+}
+
+func (value ExchangeRateValue) MarshalJSON() ([]byte, error) {
+	return []byte(value.String()), nil
+}
+
+// Both JSON numbers and strings are accepted, so a rate is never rounded by a float parser.
+func (value *ExchangeRateValue) UnmarshalJSON(data []byte) (err error) {
+	*value, err = ParseExchangeRateValue(strings.Trim(string(data), `"`))
+
+	return err
+}
+
+func ValidateExchangeRates(
+	rates []ExchangeRate,
+) (validErrRes ValidationErrorResponse, err error) {
+	pairs := make(map[string]bool)
+
+	for i := range rates {
+		from := strings.ToUpper(rates[i].From)
+		to := strings.ToUpper(rates[i].To)
+
+		if !IsValidCurrency(from) {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: fmt.Sprintf(`rates[%d].from`, i), Error: `invalid currency code`})
+		}
+
+		if !IsValidCurrency(to) {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: fmt.Sprintf(`rates[%d].to`, i), Error: `invalid currency code`})
+		} else if from == to {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: fmt.Sprintf(`rates[%d].to`, i), Error: `currencies must differ`})
+		}
+
+		if rates[i].Rate <= 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: fmt.Sprintf(`rates[%d].rate`, i), Error: `value must be positive`})
+		}
+
+		if pairs[from+to] || pairs[to+from] {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: fmt.Sprintf(`rates[%d]`, i), Error: `duplicate currency pair`})
+		}
+
+		pairs[from+to] = true
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid exchange rates`
+		err = serverrors.ErrInvalidExchangeRate
+	}
+
+	return validErrRes, err
+}
+
+// The file starts with a from,to,rate header; column order is free.
+func ParseExchangeRatesCSV(reader io.Reader) (rates []ExchangeRate, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	headerRow, err := csvReader.Read()
+
+	if err != nil {
+		return rates, fmt.Errorf(`%w: cannot read CSV header: %w`, serverrors.ErrInvalidExchangeRate, err)
+	}
+
+	header := make(map[string]int)
+
+	for i, name := range headerRow {
+		header[strings.TrimSpace(name)] = i
+	}
+
+	for _, name := range []string{`from`, `to`, `rate`} {
+		if _, found := header[name]; !found {
+			return rates, fmt.Errorf(`%w: missing %s column`, serverrors.ErrInvalidExchangeRate, name)
+		}
+	}
+
+	rates = make([]ExchangeRate, 0)
+
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return rates, fmt.Errorf(`%w: line %d: %w`, serverrors.ErrInvalidExchangeRate, line, err)
+		}
+
+		field := func(name string) string {
+			if header[name] >= len(row) {
+				return ``
+			}
+
+			return strings.TrimSpace(row[header[name]])
+		}
+
+		rate, err := ParseExchangeRateValue(field(`rate`))
+
+		if err != nil {
+			return rates, fmt.Errorf(`%w: line %d: invalid rate %q`, serverrors.ErrInvalidExchangeRate, line, field(`rate`))
+		}
+
+		rates = append(rates, ExchangeRate{From: field(`from`), To: field(`to`), Rate: rate})
+	}
+
+	return rates, nil
+}
+
+func pow10(digits int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+}
+
+// Converted amounts are rounded half away from zero to the minor unit of the
+// target currency, the same mode Money uses for percentages. A rate stored for
+// the opposite direction of the pair is applied inverted.
+func ConvertMoney(money Money, currency string, rates []ExchangeRate) (Money, error) {
+	from := NormalizeCurrency(money.Currency)
+	to := NormalizeCurrency(currency)
+
+	if from == to {
+		return Money{money.Amount, to}, nil
+	}
+
+	var numerator, denominator *big.Int
+
+	for i := range rates {
+		rateFrom := NormalizeCurrency(rates[i].From)
+		rateTo := NormalizeCurrency(rates[i].To)
+
+		if rateFrom == from && rateTo == to {
+			numerator, denominator = big.NewInt(int64(rates[i].Rate)), big.NewInt(ExchangeRateScale)
+			break
+		}
+
+		if rateFrom == to && rateTo == from && rates[i].Rate > 0 {
+			numerator, denominator = big.NewInt(ExchangeRateScale), big.NewInt(int64(rates[i].Rate))
+		}
+	}
+
+	if numerator == nil {
+		return money, serverrors.ErrExchangeRateNotFound
+	}
+
+	numerator.Mul(numerator, big.NewInt(int64(money.Amount)))
+	numerator.Mul(numerator, pow10(CurrencyMinorDigits(to)))
+	denominator.Mul(denominator, pow10(CurrencyMinorDigits(from)))
+
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(numerator.Sign())))
+	}
+
+	if !quotient.IsInt64() {
+		return money, serverrors.ErrInvalidExchangeRate
+	}
+
+	return Money{int(quotient.Int64()), to}, nil
+}
+
+func convertForDisplay(amount int, fromCurrency string, currency string, rates []ExchangeRate) (*Money, error) {
+	converted, err := ConvertMoney(NewMoney(amount, fromCurrency), currency, rates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &converted, nil
+}
+
+func ConvertHotelResponses(hotels []HotelResponse, currency string, rates []ExchangeRate) (err error) {
+	for i := range hotels {
+		hotels[i].DisplayPrice, err = convertForDisplay(hotels[i].Price, hotels[i].Currency, currency, rates)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reservations are settled in the currency of their payment.
+func ConvertReservationResponse(reservation *ReservationResponse, currency string, rates []ExchangeRate) (err error) {
+	paymentCurrency := reservation.Payment.Currency
+
+	reservation.Payment.DisplayPrice, err = convertForDisplay(reservation.Payment.Price, paymentCurrency, currency, rates)
+
+	if err != nil {
+		return err
+	}
+
+	reservation.Pricing.DisplayNightlyRate, err = convertForDisplay(reservation.Pricing.NightlyRate, paymentCurrency, currency, rates)
+
+	if err != nil {
+		return err
+	}
+
+	reservation.Pricing.DisplayTotalPrice, err = convertForDisplay(reservation.Pricing.TotalPrice, paymentCurrency, currency, rates)
+
+	return err
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+func TestConvertMoney(t *testing.T) {
+	rates := []ExchangeRate{
+		{From: `RUB`, To: `USD`, Rate: 1080000},
+		{From: `EUR`, To: `RUB`, Rate: 9500000000},
+		{From: `RUB`, To: `JPY`, Rate: 165000000},
+		{From: `KWD`, To: `RUB`, Rate: 25000000000},
+	}
+
+	tests := []struct {
+		name     string
+		money    Money
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{`same currency`, Money{10000, `RUB`}, `RUB`, Money{10000, `RUB`}, nil},
+		{`empty currency is the default one`, Money{10000, ``}, `RUB`, Money{10000, `RUB`}, nil},
+		{`direct rate`, Money{10000, `RUB`}, `USD`, Money{108, `USD`}, nil},
+		{`lower case target currency`, Money{10000, `RUB`}, `usd`, Money{108, `USD`}, nil},
+		{`rounds half away from zero`, Money{10050, `RUB`}, `USD`, Money{109, `USD`}, nil},
+		{`rounds below half down`, Money{46, `RUB`}, `USD`, Money{0, `USD`}, nil},
+		{`negative amount rounds away from zero`, Money{-50, `RUB`}, `USD`, Money{-1, `USD`}, nil},
+		{`inverted rate`, Money{108, `USD`}, `RUB`, Money{10000, `RUB`}, nil},
+		{`whole rate`, Money{3, `EUR`}, `RUB`, Money{285, `RUB`}, nil},
+		{`into currency without minor unit`, Money{10000, `RUB`}, `JPY`, Money{165, `JPY`}, nil},
+		{`from currency without minor unit`, Money{165, `JPY`}, `RUB`, Money{10000, `RUB`}, nil},
+		{`from three minor digits`, Money{1005, `KWD`}, `RUB`, Money{25125, `RUB`}, nil},
+		{`missing rate`, Money{10000, `RUB`}, `CNY`, Money{10000, `RUB`}, serverrors.ErrExchangeRateNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ConvertMoney(test.money, test.currency, rates)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf(`ConvertMoney() error = %v, want %v`, err, test.wantErr)
+			}
+
+			if got != test.want {
+				t.Errorf(`ConvertMoney() = %v, want %v`, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	icalProductId  = `-//ds-lab02//Hotel reservations//EN`
+	icalUidDomain  = `reservations.ds-lab02`
+	icalLineLength = 75
+)
+
+// The version is signed into calendar feed tokens; increasing it revokes every
+// token issued to the user before.
+type CalendarFeed struct {
+	Username string `json:"username"`
+	Version  int    `json:"version"`
+}
+
+type CalendarFeedResponse struct {
+	FeedToken string `json:"feedToken"`
+	FeedUrl   string `json:"feedUrl"`
+}
+
+func escapeICalText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`;`, `\;`,
+		`,`, `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// Lines longer than 75 octets are folded with CRLF followed by a space, never
+// splitting a multi-byte character (RFC 5545, section 3.1).
+func writeICalLine(builder *strings.Builder, line string) {
+	limit := icalLineLength
+
+	for len(line) > limit {
+		cut := limit
+
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines start with the folding space.
+		limit = icalLineLength - 1
+	}
+
+	builder.WriteString(line)
+	builder.WriteString("\r\n")
+}
+
+// Stays which have been paid for, including the ones already started or
+// finished, are confirmed events; canceled reservations stay in the feed, so
+// subscribed calendars remove them. Pending holds are not published.
+func reservationEventStatus(status string) (eventStatus string, published bool) {
+	switch status {
+	case ReservStatusPaid, ReservStatusCheckedIn, ReservStatusCompleted:
+		return `CONFIRMED`, true
+	case ReservStatusCanceled:
+		return `CANCELLED`, true
+	}
+
+	return ``, false
+}
+
+func writeReservationEvent(builder *strings.Builder, reservation *ReservationResponse, stamp string) {
+	eventStatus, published := reservationEventStatus(reservation.Status)
+
+	if !published {
+		return
+	}
+
+	startDate, startErr := time.Parse(time.DateOnly, reservation.StartDate)
+	endDate, endErr := time.Parse(time.DateOnly, reservation.EndDate)
+
+	if startErr != nil || endErr != nil {
+		return
+	}
+
+	writeICalLine(builder, `BEGIN:VEVENT`)
+	writeICalLine(builder, `UID:`+reservation.ReservationUid+`@`+icalUidDomain)
+	writeICalLine(builder, `DTSTAMP:`+stamp)
+	writeICalLine(builder, `DTSTART;VALUE=DATE:`+startDate.Format(`20060102`))
+	writeICalLine(builder, `DTEND;VALUE=DATE:`+endDate.Format(`20060102`))
+	writeICalLine(builder, `SUMMARY:`+escapeICalText(reservation.Hotel.Name))
+	writeICalLine(builder, `LOCATION:`+escapeICalText(reservation.Hotel.FullAddress))
+	writeICalLine(builder, `DESCRIPTION:`+escapeICalText(`Reservation `+reservation.ReservationUid))
+	writeICalLine(builder, `STATUS:`+eventStatus)
+	writeICalLine(builder, `TRANSP:TRANSPARENT`)
+	writeICalLine(builder, `END:VEVENT`)
+}
+
+// Every stay is an all-day event from the check-in date up to, but not
+// including, the check-out date.
+func ReservsToICalendar(reservations []ReservationResponse, now time.Time) string {
+	var builder strings.Builder
+
+	stamp := now.UTC().Format(`20060102T150405Z`)
+
+	writeICalLine(&builder, `BEGIN:VCALENDAR`)
+	writeICalLine(&builder, `VERSION:2.0`)
+	writeICalLine(&builder, `PRODID:`+icalProductId)
+	writeICalLine(&builder, `CALSCALE:GREGORIAN`)
+	writeICalLine(&builder, `METHOD:PUBLISH`)
+	writeICalLine(&builder, `X-WR-CALNAME:Hotel reservations`)
+
+	for i := range reservations {
+		writeReservationEvent(&builder, &reservations[i], stamp)
+	}
+
+	writeICalLine(&builder, `END:VCALENDAR`)
+
+	return builder.String()
+}
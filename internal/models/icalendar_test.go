@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriteICalLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{`short line`, `BEGIN:VEVENT`, "BEGIN:VEVENT\r\n"},
+		{`exactly 75 octets`, strings.Repeat(`a`, 75), strings.Repeat(`a`, 75) + "\r\n"},
+		{`76 octets`, strings.Repeat(`a`, 76), strings.Repeat(`a`, 75) + "\r\n a\r\n"},
+		{
+			`continuation lines hold 74 octets`,
+			strings.Repeat(`a`, 150),
+			strings.Repeat(`a`, 75) + "\r\n " + strings.Repeat(`a`, 74) + "\r\n a\r\n",
+		},
+		{
+			`multi-byte character is not split`,
+			strings.Repeat(`a`, 74) + `я`,
+			strings.Repeat(`a`, 74) + "\r\n я\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var builder strings.Builder
+			writeICalLine(&builder, test.line)
+
+			if got := builder.String(); got != test.want {
+				t.Errorf(`writeICalLine() = %q, want %q`, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteICalLineUnfolds(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{`ascii`, `DESCRIPTION:` + strings.Repeat(`Reservation `, 30)},
+		{`two-byte characters`, `SUMMARY:` + strings.Repeat(`я`, 100)},
+		{`three-byte characters`, `LOCATION:` + strings.Repeat(`€`, 60)},
+		{`mixed widths`, `LOCATION:` + strings.Repeat(`a€я`, 40)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var builder strings.Builder
+			writeICalLine(&builder, test.line)
+
+			folded := strings.TrimSuffix(builder.String(), "\r\n")
+
+			for _, physicalLine := range strings.Split(folded, "\r\n") {
+				if len(physicalLine) > icalLineLength {
+					t.Errorf(`line of %d octets is longer than %d`, len(physicalLine), icalLineLength)
+				}
+
+				if !utf8.ValidString(physicalLine) {
+					t.Errorf(`line %q splits a character`, physicalLine)
+				}
+			}
+
+			if unfolded := strings.ReplaceAll(folded, "\r\n ", ``); unfolded != test.line {
+				t.Errorf(`unfolded line = %q, want %q`, unfolded, test.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+const DefaultCurrency = `RUB`
+
+// Currencies whose minor unit is not a hundredth of the major one.
+var currencyMinorDigits = map[string]int{
+	`JPY`: 0,
+	`KRW`: 0,
+	`BHD`: 3,
+	`KWD`: 3,
+}
+
+// Amount is kept in minor units of the currency, e.g. kopecks for RUB. Every
+// percentage is applied with integer arithmetic and rounded half away from zero
+// to the nearest minor unit, so 12.5 kopecks become 13 and -12.5 become -13.
+type Money struct {
+	Amount   int    `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int, currency string) Money {
+	return Money{amount, NormalizeCurrency(currency)}
+}
+
+// Empty currency codes belong to records created before currencies were introduced.
+func NormalizeCurrency(currency string) string {
+	if currency == `` {
+		return DefaultCurrency
+	}
+
+	return strings.ToUpper(currency)
+}
+
+func IsValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+
+	for _, letter := range currency {
+		if letter < 'A' || letter > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func CurrencyMinorDigits(currency string) int {
+	if digits, found := currencyMinorDigits[currency]; found {
+		return digits
+	}
+
+	return 2
+}
+
+func minorUnitsScale(currency string) int {
+	scale := 1
+
+	for range CurrencyMinorDigits(currency) {
+		scale *= 10
+	}
+
+	return scale
+}
+
+// Public JSON keeps amounts as numbers in major units of the currency, the way
+// prices were returned before amounts were kept in minor units, so 1000050
+// kopecks are written as 10000.5 and 1000000 as 10000.
+func MajorUnits(amount int, currency string) json.Number {
+	scale := minorUnitsScale(NormalizeCurrency(currency))
+
+	sign := ``
+
+	if amount < 0 {
+		sign = `-`
+		amount = -amount
+	}
+
+	if amount%scale == 0 {
+		return json.Number(fmt.Sprintf(`%s%d`, sign, amount/scale))
+	}
+
+	fraction := fmt.Sprintf(`%0*d`, CurrencyMinorDigits(NormalizeCurrency(currency)), amount%scale)
+
+	return json.Number(fmt.Sprintf(`%s%d.%s`, sign, amount/scale, strings.TrimRight(fraction, `0`)))
+}
+
+func MinorUnits(majorAmount int, currency string) int {
+	return majorAmount * minorUnitsScale(NormalizeCurrency(currency))
+}
+
+// Fractions finer than the minor unit of the currency are rejected instead of
+// being rounded.
+func ParseMajorUnits(number json.Number, currency string) (int, error) {
+	digits := CurrencyMinorDigits(NormalizeCurrency(currency))
+	wholeStr, fractionStr, _ := strings.Cut(number.String(), `.`)
+
+	sign := 1
+
+	if strings.HasPrefix(wholeStr, `-`) {
+		sign = -1
+		wholeStr = wholeStr[1:]
+	}
+
+	if len(fractionStr) > digits {
+		return 0, serverrors.ErrInvalidAmount
+	}
+
+	whole, err := strconv.ParseUint(wholeStr, 10, 32)
+
+	if err != nil {
+		return 0, serverrors.ErrInvalidAmount
+	}
+
+	fraction := uint64(0)
+
+	if fractionStr != `` {
+		fraction, err = strconv.ParseUint(fractionStr+strings.Repeat(`0`, digits-len(fractionStr)), 10, 32)
+
+		if err != nil {
+			return 0, serverrors.ErrInvalidAmount
+		}
+	}
+
+	return sign * (int(whole)*minorUnitsScale(NormalizeCurrency(currency)) + int(fraction)), nil
+}
+
+func roundDiv(numerator int64, denominator int64) int64 {
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+
+	if numerator < 0 {
+		return -((-numerator + denominator/2) / denominator)
+	}
+
+	return (numerator + denominator/2) / denominator
+}
+
+func (money Money) Percent(percent int) Money {
+	return Money{percentOf(money.Amount, percent), money.Currency}
+}
+
+func (money Money) Add(other Money) (Money, error) {
+	if money.Currency != other.Currency {
+		return money, serverrors.ErrCurrencyMismatch
+	}
+
+	return Money{money.Amount + other.Amount, money.Currency}, nil
+}
+
+func (money Money) Sub(other Money) (Money, error) {
+	if money.Currency != other.Currency {
+		return money, serverrors.ErrCurrencyMismatch
+	}
+
+	return Money{money.Amount - other.Amount, money.Currency}, nil
+}
+
+func (money Money) String() string {
+	digits := CurrencyMinorDigits(money.Currency)
+
+	if digits == 0 {
+		return fmt.Sprintf(`%d %s`, money.Amount, money.Currency)
+	}
+
+	scale := minorUnitsScale(money.Currency)
+
+	sign := ``
+	amount := money.Amount
+
+	if amount < 0 {
+		sign = `-`
+		amount = -amount
+	}
+
+	return fmt.Sprintf(`%s%d.%0*d %s`, sign, amount/scale, digits, amount%scale, money.Currency)
+}
+
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{MajorUnits(money.Amount, money.Currency), money.Currency})
+}
+
+func (money *Money) UnmarshalJSON(data []byte) (err error) {
+	var value moneyJSON
+
+	if err = json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	money.Currency = value.Currency
+	money.Amount, err = ParseMajorUnits(value.Amount, value.Currency)
+
+	return err
+}
+
+func optionalMajorUnits(amount int, currency string) json.Number {
+	if amount == 0 {
+		return ``
+	}
+
+	return MajorUnits(amount, currency)
+}
+
+type appliedDiscountJSON struct {
+	Source  string      `json:"source"`
+	Code    string      `json:"code,omitempty"`
+	Percent int         `json:"percent,omitempty"`
+	Amount  json.Number `json:"amount"`
+}
+
+type appliedTaxJSON struct {
+	Kind    string      `json:"kind"`
+	Name    string      `json:"name"`
+	Percent int         `json:"percent,omitempty"`
+	Amount  json.Number `json:"amount"`
+}
+
+type nightlyPriceJSON struct {
+	Date  string      `json:"date"`
+	Price json.Number `json:"price"`
+}
+
+type nightAvailabilityJSON struct {
+	Date           string      `json:"date"`
+	RemainingRooms int         `json:"remainingRooms"`
+	Price          json.Number `json:"price"`
+}
+
+func appliedDiscountsToJSON(discounts []AppliedDiscount, currency string) []appliedDiscountJSON {
+	if discounts == nil {
+		return nil
+	}
+
+	discountsJSON := make([]appliedDiscountJSON, 0, len(discounts))
+
+	for _, discount := range discounts {
+		discountsJSON = append(discountsJSON, appliedDiscountJSON{
+			discount.Source,
+			discount.Code,
+			discount.Percent,
+			MajorUnits(discount.Amount, currency),
+		})
+	}
+
+	return discountsJSON
+}
+
+func appliedTaxesToJSON(taxes []AppliedTax, currency string) []appliedTaxJSON {
+	if taxes == nil {
+		return nil
+	}
+
+	taxesJSON := make([]appliedTaxJSON, 0, len(taxes))
+
+	for _, tax := range taxes {
+		taxesJSON = append(taxesJSON, appliedTaxJSON{tax.Kind, tax.Name, tax.Percent, MajorUnits(tax.Amount, currency)})
+	}
+
+	return taxesJSON
+}
+
+func nightlyPricesToJSON(prices []NightlyPrice, currency string) []nightlyPriceJSON {
+	if prices == nil {
+		return nil
+	}
+
+	pricesJSON := make([]nightlyPriceJSON, 0, len(prices))
+
+	for _, price := range prices {
+		pricesJSON = append(pricesJSON, nightlyPriceJSON{price.Date, MajorUnits(price.Price, currency)})
+	}
+
+	return pricesJSON
+}
+
+func (hotel HotelResponse) MarshalJSON() ([]byte, error) {
+	type hotelResponse HotelResponse
+
+	return json.Marshal(struct {
+		hotelResponse
+		Price json.Number `json:"price"`
+	}{hotelResponse(hotel), MajorUnits(hotel.Price, hotel.Currency)})
+}
+
+func (paymentInfo PaymentInfo) MarshalJSON() ([]byte, error) {
+	type paymentInfoJSON PaymentInfo
+
+	return json.Marshal(struct {
+		paymentInfoJSON
+		Price          json.Number `json:"price"`
+		RefundedAmount json.Number `json:"refundedAmount,omitempty"`
+	}{
+		paymentInfoJSON(paymentInfo),
+		MajorUnits(paymentInfo.Price, paymentInfo.Currency),
+		optionalMajorUnits(paymentInfo.RefundedAmount, paymentInfo.Currency),
+	})
+}
+
+func (priceInfo ReservationPriceInfo) MarshalJSON() ([]byte, error) {
+	type priceInfoJSON ReservationPriceInfo
+
+	return json.Marshal(struct {
+		priceInfoJSON
+		NightlyRate json.Number `json:"nightlyRate"`
+		TaxAmount   json.Number `json:"taxAmount,omitempty"`
+		TotalPrice  json.Number `json:"totalPrice"`
+	}{
+		priceInfoJSON(priceInfo),
+		MajorUnits(priceInfo.NightlyRate, priceInfo.Currency),
+		optionalMajorUnits(priceInfo.TaxAmount, priceInfo.Currency),
+		MajorUnits(priceInfo.TotalPrice, priceInfo.Currency),
+	})
+}
+
+func (changeRes ChangeReservationResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ReservationResponse
+		PreviousPrice     json.Number `json:"previousPrice"`
+		PriceDifference   json.Number `json:"priceDifference"`
+		PromoCodeReleased bool        `json:"promoCodeReleased,omitempty"`
+	}{
+		changeRes.ReservationResponse,
+		MajorUnits(changeRes.PreviousPrice, changeRes.Pricing.Currency),
+		MajorUnits(changeRes.PriceDifference, changeRes.Pricing.Currency),
+		changeRes.PromoCodeReleased,
+	})
+}
+
+func (quote PriceQuoteResponse) MarshalJSON() ([]byte, error) {
+	type priceQuoteJSON PriceQuoteResponse
+
+	return json.Marshal(struct {
+		priceQuoteJSON
+		NightlyRate      json.Number           `json:"nightlyRate"`
+		BasePrice        json.Number           `json:"basePrice"`
+		AppliedDiscounts []appliedDiscountJSON `json:"appliedDiscounts"`
+		Subtotal         json.Number           `json:"subtotal"`
+		Taxes            []appliedTaxJSON      `json:"taxes"`
+		TotalPrice       json.Number           `json:"totalPrice"`
+		NightlyPrices    []nightlyPriceJSON    `json:"nightlyPrices,omitempty"`
+	}{
+		priceQuoteJSON(quote),
+		MajorUnits(quote.NightlyRate, quote.Currency),
+		MajorUnits(quote.BasePrice, quote.Currency),
+		appliedDiscountsToJSON(quote.AppliedDiscounts, quote.Currency),
+		MajorUnits(quote.Subtotal, quote.Currency),
+		appliedTaxesToJSON(quote.Taxes, quote.Currency),
+		MajorUnits(quote.TotalPrice, quote.Currency),
+		nightlyPricesToJSON(quote.NightlyPrices, quote.Currency),
+	})
+}
+
+func (crReservRes CreateReservationResponse) MarshalJSON() ([]byte, error) {
+	type crReservResJSON CreateReservationResponse
+
+	return json.Marshal(struct {
+		crReservResJSON
+		AppliedDiscounts []appliedDiscountJSON `json:"appliedDiscounts"`
+		Taxes            []appliedTaxJSON      `json:"taxes,omitempty"`
+	}{
+		crReservResJSON(crReservRes),
+		appliedDiscountsToJSON(crReservRes.AppliedDiscounts, crReservRes.Payment.Currency),
+		appliedTaxesToJSON(crReservRes.Taxes, crReservRes.Payment.Currency),
+	})
+}
+
+// The reservation service answers with the same JSON, so it is parsed back
+// into minor units by the gateway.
+func (availabilityRes HotelAvailabilityResponse) MarshalJSON() ([]byte, error) {
+	type availabilityResJSON HotelAvailabilityResponse
+
+	var nightsJSON []nightAvailabilityJSON
+
+	if availabilityRes.Nights != nil {
+		nightsJSON = make([]nightAvailabilityJSON, 0, len(availabilityRes.Nights))
+	}
+
+	for _, night := range availabilityRes.Nights {
+		nightsJSON = append(nightsJSON, nightAvailabilityJSON{
+			night.Date,
+			night.RemainingRooms,
+			MajorUnits(night.Price, availabilityRes.Currency),
+		})
+	}
+
+	return json.Marshal(struct {
+		availabilityResJSON
+		Nights []nightAvailabilityJSON `json:"nights"`
+	}{availabilityResJSON(availabilityRes), nightsJSON})
+}
+
+func (availabilityRes *HotelAvailabilityResponse) UnmarshalJSON(data []byte) (err error) {
+	type availabilityResJSON HotelAvailabilityResponse
+
+	var value struct {
+		availabilityResJSON
+		Nights []nightAvailabilityJSON `json:"nights"`
+	}
+
+	if err = json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*availabilityRes = HotelAvailabilityResponse(value.availabilityResJSON)
+
+	if value.Nights != nil {
+		availabilityRes.Nights = make([]NightAvailability, 0, len(value.Nights))
+	}
+
+	for _, night := range value.Nights {
+		price, err := ParseMajorUnits(night.Price, availabilityRes.Currency)
+
+		if err != nil {
+			return err
+		}
+
+		availabilityRes.Nights = append(availabilityRes.Nights, NightAvailability{night.Date, night.RemainingRooms, price})
+	}
+
+	return nil
+}
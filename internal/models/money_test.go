@@ -0,0 +1,205 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+func TestRoundDiv(t *testing.T) {
+	tests := []struct {
+		name        string
+		numerator   int64
+		denominator int64
+		want        int64
+	}{
+		{`exact`, 100, 10, 10},
+		{`rounds down below half`, 24, 10, 2},
+		{`rounds half up`, 25, 10, 3},
+		{`rounds half away from zero for negatives`, -25, 10, -3},
+		{`rounds negatives below half towards zero`, -24, 10, -2},
+		{`negative denominator`, 7, -2, -4},
+		{`both negative`, -7, -2, 4},
+		{`zero`, 0, 5, 0},
+		{`less than half`, 1, 3, 0},
+		{`more than half`, 2, 3, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := roundDiv(test.numerator, test.denominator); got != test.want {
+				t.Errorf(`roundDiv(%d, %d) = %d, want %d`, test.numerator, test.denominator, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		percent int
+		want    Money
+	}{
+		{`loyalty discount`, Money{30000, `RUB`}, 10, Money{3000, `RUB`}},
+		{`half unit rounds up`, Money{1250, `RUB`}, 1, Money{13, `RUB`}},
+		{`half unit of a negative amount rounds down`, Money{-1250, `RUB`}, 1, Money{-13, `RUB`}},
+		{`below half unit rounds down`, Money{1249, `RUB`}, 1, Money{12, `RUB`}},
+		{`zero percent`, Money{999, `USD`}, 0, Money{0, `USD`}},
+		{`whole amount`, Money{999, `USD`}, 100, Money{999, `USD`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.money.Percent(test.percent); got != test.want {
+				t.Errorf(`%v.Percent(%d) = %v, want %v`, test.money, test.percent, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{`kopecks`, Money{2700050, `RUB`}, `27000.50 RUB`},
+		{`negative`, Money{-305, `USD`}, `-3.05 USD`},
+		{`less than a unit`, Money{-5, `USD`}, `-0.05 USD`},
+		{`zero`, Money{0, `EUR`}, `0.00 EUR`},
+		{`no minor unit`, Money{1500, `JPY`}, `1500 JPY`},
+		{`three minor digits`, Money{1005, `KWD`}, `1.005 KWD`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.money.String(); got != test.want {
+				t.Errorf(`String() = %q, want %q`, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMajorUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int
+		currency string
+		want     json.Number
+	}{
+		{`whole amount`, 1000000, `RUB`, `10000`},
+		{`trailing zeros are dropped`, 1000050, `RUB`, `10000.5`},
+		{`kopecks`, 1000005, `RUB`, `10000.05`},
+		{`negative`, -250, `USD`, `-2.5`},
+		{`empty currency is the default one`, 2700000, ``, `27000`},
+		{`no minor unit`, 1500, `JPY`, `1500`},
+		{`three minor digits`, 1005, `KWD`, `1.005`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MajorUnits(test.amount, test.currency); got != test.want {
+				t.Errorf(`MajorUnits(%d, %q) = %q, want %q`, test.amount, test.currency, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseMajorUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		number   json.Number
+		currency string
+		want     int
+		wantErr  error
+	}{
+		{`whole amount`, `10000`, `RUB`, 1000000, nil},
+		{`fraction`, `10000.5`, `RUB`, 1000050, nil},
+		{`negative`, `-2.5`, `USD`, -250, nil},
+		{`no minor unit`, `1500`, `JPY`, 1500, nil},
+		{`fraction finer than the minor unit`, `1.005`, `RUB`, 0, serverrors.ErrInvalidAmount},
+		{`fraction of a currency without minor unit`, `1.5`, `JPY`, 0, serverrors.ErrInvalidAmount},
+		{`exponent`, `1e3`, `RUB`, 0, serverrors.ErrInvalidAmount},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseMajorUnits(test.number, test.currency)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf(`ParseMajorUnits() error = %v, want %v`, err, test.wantErr)
+			}
+
+			if got != test.want {
+				t.Errorf(`ParseMajorUnits(%q, %q) = %d, want %d`, test.number, test.currency, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPublicJSONKeepsMajorUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			`hotel price`,
+			HotelResponse{HotelUid: `h`, Stars: 5, Price: 1000000, Currency: `RUB`},
+			`{"hotelUid":"h","name":"","country":"","city":"","address":"","stars":5,"currency":"RUB","price":10000}`,
+		},
+		{
+			`payment price`,
+			PaymentInfo{Status: `PAID`, Price: 2700000, Currency: `RUB`},
+			`{"status":"PAID","currency":"RUB","price":27000}`,
+		},
+		{
+			`money`,
+			Money{1000050, `RUB`},
+			`{"amount":10000.5,"currency":"RUB"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := json.Marshal(test.value)
+
+			if err != nil {
+				t.Fatalf(`json.Marshal() error = %v`, err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf(`json.Marshal() = %s, want %s`, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHotelAvailabilityResponseJSON(t *testing.T) {
+	availabilityRes := HotelAvailabilityResponse{
+		HotelUid: `h`,
+		From:     `2026-10-19`,
+		To:       `2026-10-20`,
+		Nights:   []NightAvailability{{`2026-10-19`, 3, 1000050}},
+		Currency: `RUB`,
+	}
+
+	data, err := json.Marshal(availabilityRes)
+
+	if err != nil {
+		t.Fatalf(`json.Marshal() error = %v`, err)
+	}
+
+	var got HotelAvailabilityResponse
+
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf(`json.Unmarshal() error = %v`, err)
+	}
+
+	if got.Nights[0] != availabilityRes.Nights[0] {
+		t.Errorf(`round trip night = %v, want %v`, got.Nights[0], availabilityRes.Nights[0])
+	}
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+)
+
+const invoiceNumberFormat = `INV-%08d`
+
+// Invoice numbers are taken from a gapless counter when a payment is captured
+// and never change afterwards.
+type Invoice struct {
+	PaymentUid    string `json:"paymentUid"`
+	Number        int    `json:"number"`
+	InvoiceNumber string `json:"invoiceNumber"`
+	IssuedAt      string `json:"issuedAt"`
+}
+
+type ReceiptLine struct {
+	Description string `json:"description"`
+	Amount      Money  `json:"amount"`
+}
+
+type Receipt struct {
+	InvoiceNumber  string        `json:"invoiceNumber,omitempty"`
+	IssuedAt       string        `json:"issuedAt,omitempty"`
+	Proforma       bool          `json:"proforma"`
+	ReservationUid string        `json:"reservationUid"`
+	Username       string        `json:"username"`
+	Status         string        `json:"status"`
+	Hotel          HotelInfo     `json:"hotel"`
+	StartDate      string        `json:"startDate"`
+	EndDate        string        `json:"endDate"`
+	Nights         int           `json:"nights"`
+	Guests         int           `json:"guests"`
+	Lines          []ReceiptLine `json:"lines"`
+	Total          Money         `json:"total"`
+	PaymentStatus  string        `json:"paymentStatus"`
+	PaymentState   string        `json:"paymentState"`
+	Adjustments    []ReceiptLine `json:"adjustments"`
+	NetPaid        Money         `json:"netPaid"`
+	LoyaltyStatus  string        `json:"loyaltyStatus,omitempty"`
+}
+
+func FormatInvoiceNumber(number int) string {
+	return fmt.Sprintf(invoiceNumberFormat, number)
+}
+
+func paymentLineItemDescription(lineItem *PaymentLineItem) string {
+	switch lineItem.Kind {
+	case PaymentLineItemRoom:
+		return `Room, ` + lineItem.Description
+	case PaymentLineItemDiscount:
+		return `Discount (` + lineItem.Description + `)`
+	}
+
+	if lineItem.Description != `` {
+		return lineItem.Description
+	}
+
+	return lineItem.Kind
+}
+
+// Payments created before line items were recorded are itemised from the
+// pricing stored with the reservation.
+func receiptLinesFromReservation(reservation *Reservation, payment *Payment) (lines []ReceiptLine) {
+	currency := NormalizeCurrency(payment.Currency)
+	roomPrice := reservation.NightlyRate * reservation.Nights
+
+	if roomPrice == 0 {
+		return []ReceiptLine{{`Accommodation`, Money{payment.Price, currency}}}
+	}
+
+	lines = append(lines, ReceiptLine{fmt.Sprintf(`Room, %d night(s)`, reservation.Nights), Money{roomPrice, currency}})
+
+	if discount := roomPrice + reservation.TaxAmount - reservation.TotalPrice; discount > 0 {
+		lines = append(lines, ReceiptLine{`Discount (` + reservation.DiscountSource + `)`, Money{-discount, currency}})
+	}
+
+	if reservation.TaxAmount > 0 {
+		lines = append(lines, ReceiptLine{`Taxes and fees`, Money{reservation.TaxAmount, currency}})
+	}
+
+	return lines
+}
+
+// The total is the amount currently held or captured by the payment; anything
+// charged on top of the original items, e.g. after a change of dates, is shown
+// as a separate line. Refunds, voids and cancellation fees are listed as
+// adjustments below the total. Without an invoice, i.e. for a payment which was
+// never charged, the receipt is a pro-forma without a number.
+func BuildReceipt(
+	reservation *Reservation,
+	hotel *Hotel,
+	payment *Payment,
+	transactions []PaymentTransaction,
+	invoice *Invoice,
+	loyalty *Loyalty,
+) (receipt Receipt) {
+	currency := NormalizeCurrency(payment.Currency)
+
+	if invoice != nil {
+		receipt.InvoiceNumber = invoice.InvoiceNumber
+		receipt.IssuedAt = invoice.IssuedAt
+	} else {
+		receipt.Proforma = true
+	}
+
+	receipt.ReservationUid = reservation.Uid
+	receipt.Username = reservation.Username
+	receipt.Status = reservation.Status
+	receipt.StartDate = reservation.StartDate
+	receipt.EndDate = reservation.EndDate
+	receipt.Nights = reservation.Nights
+	receipt.Guests = max(reservation.Guests, DefaultGuests)
+	receipt.PaymentStatus = PaymentStatusSummary(payment.Status)
+	receipt.PaymentState = payment.Status
+	receipt.Adjustments = make([]ReceiptLine, 0)
+
+	hotelToHotelInfo(&receipt.Hotel, hotel)
+
+	if len(payment.LineItems) == 0 {
+		receipt.Lines = receiptLinesFromReservation(reservation, payment)
+	} else {
+		for i := range payment.LineItems {
+			receipt.Lines = append(receipt.Lines, ReceiptLine{
+				paymentLineItemDescription(&payment.LineItems[i]),
+				Money{payment.LineItems[i].Amount, currency},
+			})
+		}
+	}
+
+	itemsTotal := 0
+
+	for _, line := range receipt.Lines {
+		itemsTotal += line.Amount.Amount
+	}
+
+	if itemsTotal != payment.Price {
+		receipt.Lines = append(receipt.Lines, ReceiptLine{`Price adjustment`, Money{payment.Price - itemsTotal, currency}})
+	}
+
+	receipt.Total = Money{payment.Price, currency}
+
+	for _, transaction := range transactions {
+		switch transaction.Kind {
+		case PaymentTransactionVoid:
+			receipt.Adjustments = append(receipt.Adjustments, ReceiptLine{
+				`Authorization released ` + transaction.CreatedAt,
+				Money{-transaction.Amount, currency},
+			})
+		case PaymentTransactionRefund:
+			receipt.Adjustments = append(receipt.Adjustments, ReceiptLine{
+				`Refund ` + transaction.CreatedAt,
+				Money{-transaction.Amount, currency},
+			})
+		}
+	}
+
+	if IsPaymentCharged(payment.Status) {
+		receipt.NetPaid = Money{payment.Price - payment.RefundedAmount, currency}
+	} else {
+		receipt.NetPaid = Money{0, currency}
+	}
+
+	if reservation.Status == ReservStatusCanceled && receipt.NetPaid.Amount > 0 {
+		receipt.Adjustments = append(receipt.Adjustments, ReceiptLine{`Cancellation fee retained`, receipt.NetPaid})
+	}
+
+	if loyalty != nil {
+		receipt.LoyaltyStatus = loyalty.Status
+	}
+
+	return receipt
+}
+
+var receiptTextTemplate = template.Must(template.New(`receipt`).Parse(
+	`{{if .Proforma}}PRO-FORMA RECEIPT
+Not an invoice: the payment has not been charged.
+{{else}}RECEIPT {{.InvoiceNumber}}
+Issued: {{.IssuedAt}}
+{{end}}
+Reservation: {{.ReservationUid}}
+Status:      {{.Status}}
+Guest:       {{.Username}}{{if .LoyaltyStatus}} ({{.LoyaltyStatus}}){{end}}
+Hotel:       {{.Hotel.Name}}, {{.Hotel.FullAddress}}
+Dates:       {{.StartDate}} - {{.EndDate}}, {{.Nights}} night(s), {{.Guests}} guest(s)
+
+{{range .Lines}}{{printf "%-48s %20s" .Description .Amount}}
+{{end}}{{printf "%-48s %20s" "Total" .Total}}
+
+Payment:     {{.PaymentStatus}} ({{.PaymentState}})
+{{if .Adjustments}}
+{{range .Adjustments}}{{printf "%-48s %20s" .Description .Amount}}
+{{end}}{{end}}{{printf "%-48s %20s" "Net paid" .NetPaid}}
+`))
+
+var receiptHTMLTemplate = htmltemplate.Must(htmltemplate.New(`receipt`).Parse(
+	`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+{{if .Proforma}}<title>Pro-forma receipt</title>
+</head>
+<body>
+<h1>Pro-forma receipt</h1>
+<p>Not an invoice: the payment has not been charged.</p>
+{{else}}<title>Receipt {{.InvoiceNumber}}</title>
+</head>
+<body>
+<h1>Receipt {{.InvoiceNumber}}</h1>
+<p>Issued: {{.IssuedAt}}</p>
+{{end}}
+<dl>
+<dt>Reservation</dt><dd>{{.ReservationUid}}</dd>
+<dt>Status</dt><dd>{{.Status}}</dd>
+<dt>Guest</dt><dd>{{.Username}}{{if .LoyaltyStatus}} ({{.LoyaltyStatus}}){{end}}</dd>
+<dt>Hotel</dt><dd>{{.Hotel.Name}}, {{.Hotel.FullAddress}}</dd>
+<dt>Dates</dt><dd>{{.StartDate}} &ndash; {{.EndDate}}, {{.Nights}} night(s), {{.Guests}} guest(s)</dd>
+</dl>
+<table>
+{{range .Lines}}<tr><td>{{.Description}}</td><td>{{.Amount}}</td></tr>
+{{end}}<tr><th>Total</th><th>{{.Total}}</th></tr>
+</table>
+<p>Payment: {{.PaymentStatus}} ({{.PaymentState}})</p>
+<table>
+{{range .Adjustments}}<tr><td>{{.Description}}</td><td>{{.Amount}}</td></tr>
+{{end}}<tr><th>Net paid</th><th>{{.NetPaid}}</th></tr>
+</table>
+</body>
+</html>
+`))
+
+func RenderReceiptText(writer io.Writer, receipt *Receipt) error {
+	return receiptTextTemplate.Execute(writer, receipt)
+}
+
+func RenderReceiptHTML(writer io.Writer, receipt *Receipt) error {
+	return receiptHTMLTemplate.Execute(writer, receipt)
+}
@@ -1,28 +1,193 @@
 package models
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/agarmirus/ds-lab02/internal/serverrors"
 	"github.com/google/uuid"
 )
 
+const (
+	PromoKindPercent = `PERCENT`
+	PromoKindFixed   = `FIXED`
+
+	DiscountSourceLoyalty = `LOYALTY`
+	DiscountSourcePromo   = `PROMO`
+	DiscountSourceNone    = `NONE`
+
+	HotelSortPrice = `price`
+	HotelSortStars = `stars`
+	HotelSortName  = `name`
+
+	SortOrderAsc  = `asc`
+	SortOrderDesc = `desc`
+
+	MaxAvailabilityNights = 366
+
+	ReservStatusPending   = `PENDING`
+	ReservStatusPaid      = `PAID`
+	ReservStatusCheckedIn = `CHECKED_IN`
+	ReservStatusCompleted = `COMPLETED`
+	ReservStatusCanceled  = `CANCELED`
+	ReservStatusNoShow    = `NO_SHOW`
+
+	PaymentStatusPending  = `PENDING`
+	PaymentStatusPaid     = `PAID`
+	PaymentStatusCanceled = `CANCELED`
+
+	PaymentStatusAuthorized        = `AUTHORIZED`
+	PaymentStatusCaptured          = `CAPTURED`
+	PaymentStatusVoided            = `VOIDED`
+	PaymentStatusRefunded          = `REFUNDED`
+	PaymentStatusPartiallyRefunded = `PARTIALLY_REFUNDED`
+
+	PaymentTransactionAuthorize = `AUTHORIZE`
+	PaymentTransactionCapture   = `CAPTURE`
+	PaymentTransactionVoid      = `VOID`
+	PaymentTransactionRefund    = `REFUND`
+
+	PaymentTransactionStatusPending   = `PENDING`
+	PaymentTransactionStatusCompleted = `COMPLETED`
+
+	ProviderStatusNotCharged = `NOT_CHARGED`
+
+	LedgerAccountCustomer        = `CUSTOMER`
+	LedgerAccountHotel           = `HOTEL`
+	LedgerAccountPlatformRevenue = `PLATFORM_REVENUE`
+	LedgerAccountRefunds         = `REFUNDS`
+
+	ReconcileIssueMissingPayment  = `MISSING_PAYMENT`
+	ReconcileIssueOrphanedPayment = `ORPHANED_PAYMENT`
+	ReconcileIssuePaymentMismatch = `PAYMENT_STATUS_MISMATCH`
+	ReconcileIssueLoyaltyMismatch = `LOYALTY_COUNT_MISMATCH`
+
+	RateRuleKindSeason   = `SEASON`
+	RateRuleKindWeekday  = `WEEKDAY`
+	RateRuleKindMinStay  = `MIN_STAY`
+	RateRuleKindLongStay = `LONG_STAY`
+)
+
+var reservStatusTransitions = map[string][]string{
+	ReservStatusPending:   {ReservStatusPaid, ReservStatusCanceled},
+	ReservStatusPaid:      {ReservStatusCheckedIn, ReservStatusCanceled, ReservStatusNoShow},
+	ReservStatusCheckedIn: {ReservStatusCompleted},
+	ReservStatusCompleted: {},
+	ReservStatusCanceled:  {},
+	ReservStatusNoShow:    {},
+}
+
+// Captured payments accept further captures, so they may keep their status.
+var paymentStatusTransitions = map[string][]string{
+	PaymentStatusAuthorized:        {PaymentStatusCaptured, PaymentStatusVoided},
+	PaymentStatusCaptured:          {PaymentStatusRefunded, PaymentStatusPartiallyRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusRefunded},
+	PaymentStatusVoided:            {},
+	PaymentStatusRefunded:          {},
+}
+
+// Summary statuses reported to clients, which only distinguish pending, paid and canceled payments.
+var paymentStatusSummaries = map[string]string{
+	PaymentStatusAuthorized:        PaymentStatusPending,
+	PaymentStatusCaptured:          PaymentStatusPaid,
+	PaymentStatusPartiallyRefunded: PaymentStatusPaid,
+	PaymentStatusVoided:            PaymentStatusCanceled,
+	PaymentStatusRefunded:          PaymentStatusCanceled,
+}
+
 type Reservation struct {
-	Id         int    `json:"id"`
-	Uid        string `json:"reservationUid"`
-	Username   string `json:"username"`
-	PaymentUid string `json:"paymentUid"`
-	HotelId    int    `json:"hotelId"`
-	Status     string `json:"status"`
-	StartDate  string `json:"startDate"`
-	EndDate    string `json:"endDate"`
+	Id              int    `json:"id"`
+	Uid             string `json:"reservationUid"`
+	Username        string `json:"username"`
+	PaymentUid      string `json:"paymentUid"`
+	HotelId         int    `json:"hotelId"`
+	Status          string `json:"status"`
+	StartDate       string `json:"startDate"`
+	EndDate         string `json:"endDate"`
+	NightlyRate     int    `json:"nightlyRate"`
+	Nights          int    `json:"nights"`
+	DiscountPercent int    `json:"discountPercent"`
+	DiscountSource  string `json:"discountSource"`
+	TotalPrice      int    `json:"totalPrice"`
+	TaxAmount       int    `json:"taxAmount"`
+	Guests          int    `json:"guests"`
+	RoomTypeId      int    `json:"roomTypeId"`
+	RoomTypeUid     string `json:"roomTypeUid,omitempty"`
+	PromoCode       string `json:"promoCode,omitempty"`
+	HoldExpiresAt   string `json:"holdExpiresAt,omitempty"`
+	UpdatedAt       string `json:"updatedAt,omitempty"`
 }
 
 type Payment struct {
-	Id     int    `json:"id"`
-	Uid    string `json:"paymentUid"`
-	Status string `json:"status"`
-	Price  int    `json:"price"`
+	Id             int    `json:"id"`
+	Uid            string `json:"paymentUid"`
+	Status         string `json:"status"`
+	Price          int    `json:"price"`
+	Currency       string `json:"currency"`
+	RefundedAmount int    `json:"refundedAmount"`
+	ReservationUid string `json:"reservationUid,omitempty"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	UpdatedAt      string `json:"updatedAt,omitempty"`
+
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	LineItems []PaymentLineItem `json:"lineItems,omitempty"`
+}
+
+type CreatePaymentRequest struct {
+	Amount         int               `json:"amount"`
+	Currency       string            `json:"currency"`
+	ReservationUid string            `json:"reservationUid"`
+	IdempotencyKey string            `json:"idempotencyKey"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	LineItems      []PaymentLineItem `json:"lineItems,omitempty"`
+}
+
+type PaymentTransaction struct {
+	Id             int    `json:"id"`
+	PaymentUid     string `json:"paymentUid"`
+	Kind           string `json:"kind"`
+	Amount         int    `json:"amount"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	CreatedAt      string `json:"createdAt"`
+
+	Entries []LedgerEntry `json:"entries,omitempty"`
+}
+
+type LedgerEntry struct {
+	Id            int    `json:"id"`
+	TransactionId int    `json:"transactionId"`
+	PaymentUid    string `json:"paymentUid"`
+	Account       string `json:"account"`
+	Debit         int    `json:"debit"`
+	Credit        int    `json:"credit"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+type LedgerBalance struct {
+	Account string `json:"account"`
+	Debit   int    `json:"debit"`
+	Credit  int    `json:"credit"`
+	Balance int    `json:"balance"`
+}
+
+type LedgerCheckResponse struct {
+	Balanced               bool     `json:"balanced"`
+	TotalDebit             int      `json:"totalDebit"`
+	TotalCredit            int      `json:"totalCredit"`
+	UnbalancedTransactions []int    `json:"unbalancedTransactions"`
+	MismatchedPayments     []string `json:"mismatchedPayments"`
+}
+
+type ProviderStatusResponse struct {
+	PaymentUid string `json:"paymentUid"`
+	Status     string `json:"status"`
 }
 
 type Loyalty struct {
@@ -33,20 +198,190 @@ type Loyalty struct {
 	Discount         int    `json:"discount"`
 }
 
+// The count is only replaced while it still equals PreviousCount.
+type LoyaltyCountCorrection struct {
+	PreviousCount    int `json:"previousCount"`
+	ReservationCount int `json:"reservationCount"`
+}
+
+type LoyaltyEvent struct {
+	Id             int       `json:"id"`
+	Username       string    `json:"username"`
+	ReservationUid string    `json:"reservationUid,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type ReconcileIssue struct {
+	Kind           string `json:"kind"`
+	ReservationUid string `json:"reservationUid,omitempty"`
+	PaymentUid     string `json:"paymentUid,omitempty"`
+	Username       string `json:"username,omitempty"`
+	Details        string `json:"details"`
+	Expected       int    `json:"expected,omitempty"`
+	Actual         int    `json:"actual,omitempty"`
+	Fixable        bool   `json:"fixable"`
+	Fixed          bool   `json:"fixed"`
+	FixError       string `json:"fixError,omitempty"`
+}
+
+type ReconcileReport struct {
+	StartedAt           string           `json:"startedAt"`
+	ApplyFixes          bool             `json:"applyFixes"`
+	ReservationsChecked int              `json:"reservationsChecked"`
+	PaymentsChecked     int              `json:"paymentsChecked"`
+	LoyaltiesChecked    int              `json:"loyaltiesChecked"`
+	Issues              []ReconcileIssue `json:"issues"`
+}
+
+type LoyaltyTierChange struct {
+	Username         string `json:"username"`
+	ReservationCount int    `json:"reservationCount"`
+	QualifyingCount  int    `json:"qualifyingCount"`
+	OldStatus        string `json:"oldStatus"`
+	NewStatus        string `json:"newStatus"`
+	OldDiscount      int    `json:"oldDiscount"`
+	NewDiscount      int    `json:"newDiscount"`
+}
+
 type Hotel struct {
-	Id      int    `json:"id"`
-	Uid     string `json:"hotelUid"`
-	Name    string `json:"name"`
-	Country string `json:"country"`
-	City    string `json:"city"`
-	Address string `json:"address"`
-	Stars   int    `json:"stars"`
-	Price   int    `json:"price"`
+	Id         int    `json:"id"`
+	Uid        string `json:"hotelUid"`
+	Name       string `json:"name"`
+	Country    string `json:"country"`
+	City       string `json:"city"`
+	Address    string `json:"address"`
+	Stars      int    `json:"stars"`
+	Price      int    `json:"price"`
+	Currency   string `json:"currency,omitempty"`
+	Retired    bool   `json:"retired"`
+	RoomsCount int    `json:"roomsCount,omitempty"`
+
+	CancellationPolicy *CancellationPolicy `json:"cancellationPolicy,omitempty"`
+}
+
+type CancellationPolicy struct {
+	Id                   int  `json:"id"`
+	HotelId              int  `json:"hotelId"`
+	FreeCancellationDays int  `json:"freeCancellationDays"`
+	PenaltyPercent       int  `json:"penaltyPercent"`
+	NonRefundable        bool `json:"nonRefundable"`
+}
+
+type RateRule struct {
+	Id        int    `json:"id"`
+	HotelId   int    `json:"hotelId"`
+	Kind      string `json:"kind"`
+	ValidFrom string `json:"validFrom,omitempty"`
+	ValidTo   string `json:"validTo,omitempty"`
+	Weekdays  []int  `json:"weekdays,omitempty"`
+	Percent   int    `json:"percent"`
+	MinNights int    `json:"minNights,omitempty"`
+}
+
+type NightlyPrice struct {
+	Date  string `json:"date"`
+	Price int    `json:"price"`
+}
+
+type NightlyPriceResponse struct {
+	HotelUid         string         `json:"hotelUid"`
+	From             string         `json:"from"`
+	To               string         `json:"to"`
+	Nights           []NightlyPrice `json:"nights"`
+	Subtotal         int            `json:"subtotal"`
+	LongStayPercent  int            `json:"longStayPercent"`
+	LongStayDiscount int            `json:"longStayDiscount"`
+	TotalPrice       int            `json:"totalPrice"`
+	Currency         string         `json:"currency"`
+}
+
+type PromoCode struct {
+	Id             int    `json:"id"`
+	Code           string `json:"code"`
+	Kind           string `json:"kind"`
+	Value          int    `json:"value"`
+	ValidFrom      string `json:"validFrom"`
+	ValidTo        string `json:"validTo"`
+	MaxUses        int    `json:"maxUses"`
+	MaxUsesPerUser int    `json:"maxUsesPerUser"`
+	UsedCount      int    `json:"usedCount"`
+	HotelId        int    `json:"hotelId"`
+	City           string `json:"city"`
+	Stackable      bool   `json:"stackable"`
+}
+
+type PromoCodeUsage struct {
+	Id             int    `json:"id"`
+	PromoCodeId    int    `json:"promoCodeId"`
+	Code           string `json:"code"`
+	Username       string `json:"username"`
+	ReservationUid string `json:"reservationUid"`
+}
+
+type AppliedDiscount struct {
+	Source  string `json:"source"`
+	Code    string `json:"code,omitempty"`
+	Percent int    `json:"percent,omitempty"`
+	Amount  int    `json:"amount"`
+}
+
+type HotelFilter struct {
+	City          string `json:"city,omitempty"`
+	Country       string `json:"country,omitempty"`
+	Name          string `json:"name,omitempty"`
+	MinStars      int    `json:"minStars,omitempty"`
+	MaxStars      int    `json:"maxStars,omitempty"`
+	MinPrice      int    `json:"minPrice,omitempty"`
+	MaxPrice      int    `json:"maxPrice,omitempty"`
+	PriceCurrency string `json:"priceCurrency,omitempty"`
+	SortBy        string `json:"sortBy,omitempty"`
+	SortOrder     string `json:"sortOrder,omitempty"`
+}
+
+type HotelCursor struct {
+	SortBy    string `json:"s,omitempty"`
+	SortOrder string `json:"o,omitempty"`
+	SortValue string `json:"v,omitempty"`
+	Id        int    `json:"id"`
+	Backward  bool   `json:"b,omitempty"`
+}
+
+type HotelsPage struct {
+	Items         []Hotel `json:"items"`
+	TotalElements int     `json:"totalElements"`
+	NextCursor    string  `json:"nextCursor,omitempty"`
+	PrevCursor    string  `json:"prevCursor,omitempty"`
+}
+
+type NightAvailability struct {
+	Date           string `json:"date"`
+	RemainingRooms int    `json:"remainingRooms"`
+	Price          int    `json:"price"`
+}
+
+type HotelAvailabilityResponse struct {
+	HotelUid string              `json:"hotelUid"`
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Nights   []NightAvailability `json:"nights"`
+	Currency string              `json:"currency,omitempty"`
 }
 
 type PaymentInfo struct {
-	Status string `json:"status"`
-	Price  int    `json:"price"`
+	Status         string `json:"status"`
+	State          string `json:"state,omitempty"`
+	Price          int    `json:"price"`
+	Currency       string `json:"currency,omitempty"`
+	RefundedAmount int    `json:"refundedAmount,omitempty"`
+
+	DisplayPrice *Money `json:"displayPrice,omitempty"`
+}
+
+type CancellationPolicyInfo struct {
+	FreeCancellationDays int    `json:"freeCancellationDays"`
+	PenaltyPercent       int    `json:"penaltyPercent"`
+	NonRefundable        bool   `json:"nonRefundable"`
+	Summary              string `json:"summary"`
 }
 
 type LoyaltyInfoResponse struct {
@@ -63,6 +398,9 @@ type HotelResponse struct {
 	Address  string `json:"address"`
 	Stars    int    `json:"stars"`
 	Price    int    `json:"price"`
+	Currency string `json:"currency,omitempty"`
+
+	DisplayPrice *Money `json:"displayPrice,omitempty"`
 }
 
 type HotelInfo struct {
@@ -72,13 +410,41 @@ type HotelInfo struct {
 	Stars       int    `json:"stars"`
 }
 
+type ReservationPriceInfo struct {
+	NightlyRate     int    `json:"nightlyRate"`
+	Nights          int    `json:"nights"`
+	DiscountPercent int    `json:"discountPercent"`
+	DiscountSource  string `json:"discountSource"`
+	TaxAmount       int    `json:"taxAmount,omitempty"`
+	TotalPrice      int    `json:"totalPrice"`
+	Currency        string `json:"currency,omitempty"`
+
+	DisplayNightlyRate *Money `json:"displayNightlyRate,omitempty"`
+	DisplayTotalPrice  *Money `json:"displayTotalPrice,omitempty"`
+}
+
 type ReservationResponse struct {
-	ReservationUid string      `json:"reservationUid"`
-	Hotel          HotelInfo   `json:"hotel"`
-	StartDate      string      `json:"startDate"`
-	EndDate        string      `json:"endDate"`
-	Status         string      `json:"status"`
-	Payment        PaymentInfo `json:"payment"`
+	ReservationUid string               `json:"reservationUid"`
+	Hotel          HotelInfo            `json:"hotel"`
+	StartDate      string               `json:"startDate"`
+	EndDate        string               `json:"endDate"`
+	Status         string               `json:"status"`
+	Payment        PaymentInfo          `json:"payment"`
+	Pricing        ReservationPriceInfo `json:"pricing"`
+
+	CancellationPolicy *CancellationPolicyInfo `json:"cancellationPolicy,omitempty"`
+}
+
+type ChangeReservationRequest struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+type ChangeReservationResponse struct {
+	ReservationResponse
+	PreviousPrice     int  `json:"previousPrice"`
+	PriceDifference   int  `json:"priceDifference"`
+	PromoCodeReleased bool `json:"promoCodeReleased,omitempty"`
 }
 
 type UserInfoResponse struct {
@@ -87,19 +453,48 @@ type UserInfoResponse struct {
 }
 
 type CreateReservationRequest struct {
-	HotelUid  string `json:"hotelUid"`
-	StartDate string `json:"startDate"`
-	EndDate   string `json:"endDate"`
+	HotelUid    string `json:"hotelUid"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	PromoCode   string `json:"promoCode,omitempty"`
+	QuoteToken  string `json:"quoteToken,omitempty"`
+	RoomTypeUid string `json:"roomTypeUid,omitempty"`
+	Guests      int    `json:"guests,omitempty"`
+}
+
+type PriceQuoteResponse struct {
+	HotelUid         string            `json:"hotelUid"`
+	StartDate        string            `json:"startDate"`
+	EndDate          string            `json:"endDate"`
+	Nights           int               `json:"nights"`
+	NightlyRate      int               `json:"nightlyRate"`
+	BasePrice        int               `json:"basePrice"`
+	AppliedDiscounts []AppliedDiscount `json:"appliedDiscounts"`
+	Subtotal         int               `json:"subtotal"`
+	Guests           int               `json:"guests"`
+	Taxes            []AppliedTax      `json:"taxes"`
+	TotalPrice       int               `json:"totalPrice"`
+	Currency         string            `json:"currency,omitempty"`
+	NightlyPrices    []NightlyPrice    `json:"nightlyPrices,omitempty"`
+	QuoteToken       string            `json:"quoteToken"`
+	ExpiresAt        string            `json:"expiresAt"`
 }
 
 type CreateReservationResponse struct {
-	ReservationUid string      `json:"reservationUid"`
-	HotelUid       string      `json:"hotelUid"`
-	StartDate      string      `json:"startDate"`
-	EndDate        string      `json:"endDate"`
-	Discount       int         `json:"discount"`
-	Status         string      `json:"status"`
-	Payment        PaymentInfo `json:"payment"`
+	ReservationUid   string            `json:"reservationUid"`
+	HotelUid         string            `json:"hotelUid"`
+	StartDate        string            `json:"startDate"`
+	EndDate          string            `json:"endDate"`
+	Discount         int               `json:"discount"`
+	Status           string            `json:"status"`
+	Payment          PaymentInfo       `json:"payment"`
+	AppliedDiscounts []AppliedDiscount `json:"appliedDiscounts"`
+	Taxes            []AppliedTax      `json:"taxes,omitempty"`
+}
+
+type PaginationLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
 }
 
 type PagiationResponse struct {
@@ -107,6 +502,7 @@ type PagiationResponse struct {
 	PageSize      int             `json:"pageSize"`
 	TotalElements int             `json:"totalElements"`
 	Items         []HotelResponse `json:"items"`
+	Links         PaginationLinks `json:"links"`
 }
 
 type ErrorDiscription struct {
@@ -134,26 +530,80 @@ func HotelToHotelReponse(
 	hotelRes.Address = hotel.Address
 	hotelRes.Stars = hotel.Stars
 	hotelRes.Price = hotel.Price
+	hotelRes.Currency = hotel.Currency
 }
 
-func HotelsSliceToPagRes(
+func HotelsPageToPagRes(
 	pagRes *PagiationResponse,
-	hotelsSlice []Hotel,
+	hotelsPage *HotelsPage,
 	page int,
 	pageSize int,
 ) {
 	pagRes.Page = page
 	pagRes.PageSize = pageSize
-	pagRes.TotalElements = len(hotelsSlice)
+	pagRes.TotalElements = hotelsPage.TotalElements
+	pagRes.Items = make([]HotelResponse, 0)
 
-	for i := 0; i < pagRes.TotalElements; i++ {
+	for i := 0; i < len(hotelsPage.Items); i++ {
 		var hotelRes HotelResponse
-		HotelToHotelReponse(&hotelRes, &hotelsSlice[i])
+		HotelToHotelReponse(&hotelRes, &hotelsPage.Items[i])
 
 		pagRes.Items = append(pagRes.Items, hotelRes)
 	}
 }
 
+func EncodeHotelCursor(
+	hotel *Hotel,
+	filter *HotelFilter,
+	backward bool,
+) string {
+	cursor := HotelCursor{SortBy: filter.SortBy, SortOrder: filter.SortOrder, Id: hotel.Id, Backward: backward}
+
+	switch filter.SortBy {
+	case HotelSortPrice:
+		cursor.SortValue = strconv.Itoa(hotel.Price)
+	case HotelSortStars:
+		cursor.SortValue = strconv.Itoa(hotel.Stars)
+	case HotelSortName:
+		cursor.SortValue = hotel.Name
+	}
+
+	cursorJSON, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+func DecodeHotelCursor(
+	cursorStr string,
+	filter *HotelFilter,
+) (cursor HotelCursor, err error) {
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(cursorStr)
+
+	if err != nil {
+		return cursor, serverrors.ErrInvalidCursor
+	}
+
+	err = json.Unmarshal(cursorJSON, &cursor)
+
+	if err != nil || cursor.Id <= 0 {
+		return cursor, serverrors.ErrInvalidCursor
+	}
+
+	if cursor.SortBy != filter.SortBy || cursor.SortOrder != filter.SortOrder {
+		return cursor, serverrors.ErrInvalidCursor
+	}
+
+	if cursor.SortBy == HotelSortPrice || cursor.SortBy == HotelSortStars {
+		_, err = strconv.Atoi(cursor.SortValue)
+
+		if err != nil {
+			return cursor, serverrors.ErrInvalidCursor
+		}
+	}
+
+	return cursor, nil
+}
+
 func hotelToHotelInfo(
 	hotelInfo *HotelInfo,
 	hotel *Hotel,
@@ -168,8 +618,39 @@ func paymentToPaymentInfo(
 	paymentInfo *PaymentInfo,
 	payment *Payment,
 ) {
-	paymentInfo.Status = payment.Status
+	paymentInfo.Status = PaymentStatusSummary(payment.Status)
+	paymentInfo.State = payment.Status
 	paymentInfo.Price = payment.Price
+	paymentInfo.Currency = payment.Currency
+	paymentInfo.RefundedAmount = payment.RefundedAmount
+}
+
+func cancelPolicyToCancelPolicyInfo(
+	policy *CancellationPolicy,
+) *CancellationPolicyInfo {
+	if policy == nil {
+		return nil
+	}
+
+	policyInfo := CancellationPolicyInfo{
+		FreeCancellationDays: policy.FreeCancellationDays,
+		PenaltyPercent:       policy.PenaltyPercent,
+		NonRefundable:        policy.NonRefundable,
+	}
+
+	if policy.NonRefundable {
+		policyInfo.Summary = `Non-refundable`
+	} else if policy.PenaltyPercent == 0 {
+		policyInfo.Summary = `Free cancellation`
+	} else {
+		policyInfo.Summary = fmt.Sprintf(
+			`Free cancellation until %d days before check-in, %d%% penalty after`,
+			policy.FreeCancellationDays,
+			policy.PenaltyPercent,
+		)
+	}
+
+	return &policyInfo
 }
 
 func ReservToReservRes(
@@ -185,6 +666,49 @@ func ReservToReservRes(
 
 	hotelToHotelInfo(&reservRes.Hotel, hotel)
 	paymentToPaymentInfo(&reservRes.Payment, payment)
+	reservToReservPriceInfo(&reservRes.Pricing, reservation, payment)
+	reservRes.CancellationPolicy = cancelPolicyToCancelPolicyInfo(hotel.CancellationPolicy)
+}
+
+func reservToReservPriceInfo(
+	priceInfo *ReservationPriceInfo,
+	reservation *Reservation,
+	payment *Payment,
+) {
+	priceInfo.NightlyRate = reservation.NightlyRate
+	priceInfo.Nights = reservation.Nights
+	priceInfo.DiscountPercent = reservation.DiscountPercent
+	priceInfo.DiscountSource = reservation.DiscountSource
+	priceInfo.TaxAmount = reservation.TaxAmount
+	priceInfo.TotalPrice = reservation.TotalPrice
+	priceInfo.Currency = NormalizeCurrency(payment.Currency)
+}
+
+func QuoteToReservPricing(
+	reservation *Reservation,
+	quote *PriceQuoteResponse,
+) {
+	reservation.NightlyRate = quote.NightlyRate
+	reservation.Nights = quote.Nights
+	reservation.TotalPrice = quote.TotalPrice
+	reservation.TaxAmount = TaxAmount(quote.Taxes)
+	reservation.Guests = quote.Guests
+	reservation.DiscountPercent = 0
+	reservation.DiscountSource = DiscountSourceNone
+
+	if quote.BasePrice > 0 {
+		reservation.DiscountPercent = percentFromAmount(quote.BasePrice-quote.Subtotal, quote.BasePrice)
+	}
+
+	sources := make([]string, 0)
+
+	for _, appliedDiscount := range quote.AppliedDiscounts {
+		sources = append(sources, appliedDiscount.Source)
+	}
+
+	if len(sources) > 0 {
+		reservation.DiscountSource = strings.Join(sources, `,`)
+	}
 }
 
 func ReservsSliceToReservRes(
@@ -231,15 +755,22 @@ func ReservToCrReservRes(
 	crReservRes *CreateReservationResponse,
 	reservation *Reservation,
 	payment *Payment,
-	loyalty *Loyalty,
+	quote *PriceQuoteResponse,
 	hotelUid string,
 ) {
 	crReservRes.ReservationUid = reservation.Uid
 	crReservRes.HotelUid = hotelUid
 	crReservRes.StartDate = reservation.StartDate
 	crReservRes.EndDate = reservation.EndDate
-	crReservRes.Discount = loyalty.Discount
 	crReservRes.Status = reservation.Status
+	crReservRes.AppliedDiscounts = quote.AppliedDiscounts
+	crReservRes.Taxes = quote.Taxes
+
+	for _, appliedDiscount := range quote.AppliedDiscounts {
+		if appliedDiscount.Source == DiscountSourceLoyalty {
+			crReservRes.Discount = appliedDiscount.Percent
+		}
+	}
 
 	paymentToPaymentInfo(&crReservRes.Payment, payment)
 }
@@ -266,6 +797,17 @@ func ValidateCrReservReq(
 		err = serverrors.ErrInvalidReservDates
 	}
 
+	if createReservReq.RoomTypeUid != `` && uuid.Validate(createReservReq.RoomTypeUid) != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `roomTypeUid`, Error: `invalid uid`})
+		err = serverrors.ErrInvalidCrReservReq
+	}
+
+	// A missing or zero guests count means the default number of guests.
+	if createReservReq.Guests < 0 || createReservReq.Guests > MaxGuests {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `guests`, Error: fmt.Sprintf(`value must be between 0 (default) and %d`, MaxGuests)})
+		err = serverrors.ErrInvalidCrReservReq
+	}
+
 	if err != nil {
 		validErrRes.Message = `invalid reservation request data`
 	}
@@ -273,13 +815,808 @@ func ValidateCrReservReq(
 	return validErrRes, err
 }
 
+const maxIdempotencyKeyLength = 255
+const maxPaymentMetadataKeys = 20
+const maxPaymentMetadataKeyLength = 40
+const maxPaymentMetadataValueLength = 500
+
+func IsValidIdempotencyKey(key string) bool {
+	return strings.TrimSpace(key) != `` && utf8.RuneCountInString(key) <= maxIdempotencyKeyLength
+}
+
+func ValidateCrPaymentReq(
+	crPaymentReq *CreatePaymentRequest,
+) (validErrRes ValidationErrorResponse, err error) {
+	if crPaymentReq.Amount <= 0 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `amount`, Error: `value must be positive`})
+	}
+
+	if crPaymentReq.Currency == `` {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `currency`, Error: `field is required`})
+	} else if !IsValidCurrency(strings.ToUpper(crPaymentReq.Currency)) {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `currency`, Error: `invalid currency code`})
+	}
+
+	if uuid.Validate(crPaymentReq.ReservationUid) != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `reservationUid`, Error: `invalid uid`})
+	}
+
+	if strings.TrimSpace(crPaymentReq.IdempotencyKey) == `` {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `idempotencyKey`, Error: `field is required`})
+	} else if utf8.RuneCountInString(crPaymentReq.IdempotencyKey) > maxIdempotencyKeyLength {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `idempotencyKey`, Error: fmt.Sprintf(`value must not be longer than %d characters`, maxIdempotencyKeyLength)})
+	}
+
+	if len(crPaymentReq.Metadata) > maxPaymentMetadataKeys {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `metadata`, Error: fmt.Sprintf(`must not contain more than %d keys`, maxPaymentMetadataKeys)})
+	}
+
+	metadataKeys := make([]string, 0, len(crPaymentReq.Metadata))
+
+	for key := range crPaymentReq.Metadata {
+		metadataKeys = append(metadataKeys, key)
+	}
+
+	slices.Sort(metadataKeys)
+
+	for _, key := range metadataKeys {
+		value := crPaymentReq.Metadata[key]
+
+		if key == `` || utf8.RuneCountInString(key) > maxPaymentMetadataKeyLength {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `metadata`, Error: fmt.Sprintf(`keys must be between 1 and %d characters long`, maxPaymentMetadataKeyLength)})
+		} else if utf8.RuneCountInString(value) > maxPaymentMetadataValueLength {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `metadata.` + key, Error: fmt.Sprintf(`value must not be longer than %d characters`, maxPaymentMetadataValueLength)})
+		}
+	}
+
+	if len(crPaymentReq.LineItems) != 0 {
+		lineItemsTotal := 0
+
+		for _, lineItem := range crPaymentReq.LineItems {
+			lineItemsTotal += lineItem.Amount
+		}
+
+		if lineItemsTotal != crPaymentReq.Amount {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `lineItems`, Error: `amounts must add up to the payment amount`})
+		}
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid payment request data`
+		err = serverrors.ErrInvalidCrPaymentReq
+	}
+
+	return validErrRes, err
+}
+
+func CrPaymentReqToPayment(payment *Payment, crPaymentReq *CreatePaymentRequest) {
+	payment.Price = crPaymentReq.Amount
+	payment.Currency = NormalizeCurrency(crPaymentReq.Currency)
+	payment.ReservationUid = crPaymentReq.ReservationUid
+	payment.IdempotencyKey = crPaymentReq.IdempotencyKey
+	payment.Metadata = crPaymentReq.Metadata
+	payment.LineItems = crPaymentReq.LineItems
+}
+
+// A replayed request matches the stored payment when it is for the same
+// reservation and currency. The amount is only compared while the payment is
+// still authorized, since a partial capture changes it afterwards.
+func IsSamePaymentRequest(stored *Payment, requested *Payment) bool {
+	if stored.ReservationUid != requested.ReservationUid || stored.Currency != requested.Currency {
+		return false
+	}
+
+	return stored.Status != PaymentStatusAuthorized || stored.Price == requested.Price
+}
+
+func IsValidReservStatus(status string) bool {
+	_, found := reservStatusTransitions[status]
+	return found
+}
+
+func IsValidPaymentStatus(status string) bool {
+	_, found := paymentStatusTransitions[status]
+	return found
+}
+
+// Keeping the same status is an ordinary update, which terminal statuses do not accept.
+func canChangeStatus(transitions map[string][]string, from string, to string) bool {
+	nextStatuses, found := transitions[from]
+
+	if !found {
+		return false
+	}
+
+	if from == to {
+		return len(nextStatuses) != 0
+	}
+
+	return slices.Contains(nextStatuses, to)
+}
+
+func CanChangeReservStatus(from string, to string) bool {
+	return canChangeStatus(reservStatusTransitions, from, to)
+}
+
+func CanChangePaymentStatus(from string, to string) bool {
+	return canChangeStatus(paymentStatusTransitions, from, to)
+}
+
+var LedgerAccounts = []string{
+	LedgerAccountCustomer,
+	LedgerAccountHotel,
+	LedgerAccountPlatformRevenue,
+	LedgerAccountRefunds,
+}
+
+// Only captures and refunds move money, so authorizations and voids are not posted.
+// A capture is split between the hotel and the platform fee, a refund is posted
+// against the refunds account, so the customer account always holds the net
+// amount charged.
+func LedgerEntriesForTransaction(transaction *PaymentTransaction, platformFeePercent int) []LedgerEntry {
+	amount := transaction.Amount
+
+	if amount <= 0 {
+		return nil
+	}
+
+	switch transaction.Kind {
+	case PaymentTransactionCapture:
+		fee := percentOf(amount, platformFeePercent)
+		entries := []LedgerEntry{{Account: LedgerAccountCustomer, Debit: amount}}
+
+		if fee < amount {
+			entries = append(entries, LedgerEntry{Account: LedgerAccountHotel, Credit: amount - fee})
+		}
+
+		if fee > 0 {
+			entries = append(entries, LedgerEntry{Account: LedgerAccountPlatformRevenue, Credit: fee})
+		}
+
+		return entries
+	case PaymentTransactionRefund:
+		return []LedgerEntry{
+			{Account: LedgerAccountRefunds, Debit: amount},
+			{Account: LedgerAccountCustomer, Credit: amount},
+		}
+	}
+
+	return nil
+}
+
+// Only charged payments get an invoice number.
+func IsPaymentCharged(status string) bool {
+	switch status {
+	case PaymentStatusCaptured, PaymentStatusPartiallyRefunded, PaymentStatusRefunded:
+		return true
+	}
+
+	return false
+}
+
+func PaymentStatusSummary(status string) string {
+	if summary, found := paymentStatusSummaries[status]; found {
+		return summary
+	}
+
+	return status
+}
+
+// Applies a capture, void or refund to the payment. A zero amount captures the
+// authorized price or refunds the remaining captured balance; a positive capture
+// on an already captured payment charges the amount on top of it.
+func ApplyPaymentTransaction(payment *Payment, transaction *PaymentTransaction) error {
+	if transaction.Amount < 0 {
+		return serverrors.ErrInvalidPaymentAmount
+	}
+
+	switch transaction.Kind {
+	case PaymentTransactionCapture:
+		switch payment.Status {
+		case PaymentStatusAuthorized:
+			if transaction.Amount > payment.Price {
+				return serverrors.ErrInvalidPaymentAmount
+			}
+
+			if transaction.Amount == 0 {
+				transaction.Amount = payment.Price
+			}
+
+			payment.Price = transaction.Amount
+			payment.Status = PaymentStatusCaptured
+		case PaymentStatusCaptured, PaymentStatusPartiallyRefunded:
+			if transaction.Amount == 0 {
+				return serverrors.ErrInvalidPaymentAmount
+			}
+
+			payment.Price += transaction.Amount
+		default:
+			return serverrors.ErrInvalidStatusTransition
+		}
+	case PaymentTransactionVoid:
+		if !CanChangePaymentStatus(payment.Status, PaymentStatusVoided) {
+			return serverrors.ErrInvalidStatusTransition
+		}
+
+		transaction.Amount = payment.Price
+		payment.Status = PaymentStatusVoided
+	case PaymentTransactionRefund:
+		if payment.Status != PaymentStatusCaptured && payment.Status != PaymentStatusPartiallyRefunded {
+			return serverrors.ErrInvalidStatusTransition
+		}
+
+		remaining := payment.Price - payment.RefundedAmount
+
+		if transaction.Amount > remaining {
+			return serverrors.ErrInvalidPaymentAmount
+		}
+
+		if transaction.Amount == 0 {
+			transaction.Amount = remaining
+		}
+
+		payment.RefundedAmount += transaction.Amount
+
+		if payment.RefundedAmount == payment.Price {
+			payment.Status = PaymentStatusRefunded
+		} else {
+			payment.Status = PaymentStatusPartiallyRefunded
+		}
+	default:
+		return serverrors.ErrInvalidPaymentTransaction
+	}
+
+	return nil
+}
+
+func ValidateChangeReservReq(
+	changeReservReq *ChangeReservationRequest,
+) (validErrRes ValidationErrorResponse, err error) {
+	startDate, startErr := time.Parse(time.DateOnly, changeReservReq.StartDate)
+
+	if startErr != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `startDate`, Error: `invalid date format`})
+	}
+
+	endDate, endErr := time.Parse(time.DateOnly, changeReservReq.EndDate)
+
+	if endErr != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `endDate`, Error: `invalid date format`})
+	} else if startErr == nil && startDate.Unix() > endDate.Unix() {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `startDate`, Error: `invalid date period`})
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid reservation request data`
+		err = serverrors.ErrInvalidReservDates
+	}
+
+	return validErrRes, err
+}
+
+func ValidateHotel(
+	hotel *Hotel,
+) (validErrRes ValidationErrorResponse, err error) {
+	checkText := func(field string, value string, maxLen int) {
+		if strings.TrimSpace(value) == `` {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: field, Error: `field is required`})
+		} else if utf8.RuneCountInString(value) > maxLen {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: field, Error: `value is too long`})
+		}
+	}
+
+	checkText(`name`, hotel.Name, 255)
+	checkText(`country`, hotel.Country, 80)
+	checkText(`city`, hotel.City, 80)
+	checkText(`address`, hotel.Address, 255)
+
+	if hotel.Stars < 1 || hotel.Stars > 5 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `stars`, Error: `stars must be between 1 and 5`})
+	}
+
+	if hotel.Price <= 0 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `price`, Error: `price must be positive`})
+	}
+
+	if !IsValidCurrency(NormalizeCurrency(hotel.Currency)) {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `currency`, Error: `currency must be an ISO 4217 code`})
+	}
+
+	if hotel.RoomsCount <= 0 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `roomsCount`, Error: `rooms count must be positive`})
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid hotel data`
+		err = serverrors.ErrInvalidHotel
+	}
+
+	return validErrRes, err
+}
+
+func ValidateAvailabilityRange(
+	from string,
+	to string,
+) (validErrRes ValidationErrorResponse, err error) {
+	fromDate, fromErr := time.Parse(time.DateOnly, from)
+
+	if fromErr != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `from`, Error: `invalid date format`})
+	}
+
+	toDate, toErr := time.Parse(time.DateOnly, to)
+
+	if toErr != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `to`, Error: `invalid date format`})
+	}
+
+	if fromErr == nil && toErr == nil {
+		nights := int(toDate.Sub(fromDate).Hours() / 24)
+
+		if nights <= 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `from`, Error: `invalid date period`})
+		} else if nights > MaxAvailabilityNights {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `to`, Error: `date period is too long`})
+		}
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid availability request data`
+		err = serverrors.ErrInvalidAvailabilityRange
+	}
+
+	return validErrRes, err
+}
+
+func parseNonNegativeParam(
+	values url.Values,
+	name string,
+	validErrRes *ValidationErrorResponse,
+) int {
+	valueStr := values.Get(name)
+
+	if valueStr == `` {
+		return 0
+	}
+
+	value, err := strconv.Atoi(valueStr)
+
+	if err != nil || value < 0 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: name, Error: `invalid number`})
+		return 0
+	}
+
+	return value
+}
+
+func ParseHotelFilter(
+	values url.Values,
+) (filter HotelFilter, validErrRes ValidationErrorResponse, err error) {
+	filter.City = strings.TrimSpace(values.Get(`city`))
+	filter.Country = strings.TrimSpace(values.Get(`country`))
+	filter.Name = strings.TrimSpace(values.Get(`name`))
+	filter.MinStars = parseNonNegativeParam(values, `minStars`, &validErrRes)
+	filter.MaxStars = parseNonNegativeParam(values, `maxStars`, &validErrRes)
+	filter.MinPrice = parseNonNegativeParam(values, `minPrice`, &validErrRes)
+	filter.MaxPrice = parseNonNegativeParam(values, `maxPrice`, &validErrRes)
+	filter.PriceCurrency = strings.ToUpper(strings.TrimSpace(values.Get(`priceCurrency`)))
+	filter.SortBy = values.Get(`sortBy`)
+	filter.SortOrder = strings.ToLower(values.Get(`sortOrder`))
+
+	if filter.MaxStars != 0 && filter.MinStars > filter.MaxStars {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `minStars`, Error: `invalid stars range`})
+	}
+
+	if filter.MaxPrice != 0 && filter.MinPrice > filter.MaxPrice {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `minPrice`, Error: `invalid price range`})
+	}
+
+	if filter.PriceCurrency != `` && !IsValidCurrency(filter.PriceCurrency) {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `priceCurrency`, Error: `invalid currency code`})
+	}
+
+	if filter.SortBy != `` && filter.SortBy != HotelSortPrice && filter.SortBy != HotelSortStars && filter.SortBy != HotelSortName {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `sortBy`, Error: `unknown sort field`})
+	}
+
+	if filter.SortOrder != `` && filter.SortOrder != SortOrderAsc && filter.SortOrder != SortOrderDesc {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `sortOrder`, Error: `unknown sort order`})
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid hotels filter`
+		err = serverrors.ErrInvalidHotelFilter
+	}
+
+	return filter, validErrRes, err
+}
+
+func HotelFilterToValues(
+	values url.Values,
+	filter *HotelFilter,
+) {
+	setStr := func(name string, value string) {
+		if value != `` {
+			values.Set(name, value)
+		}
+	}
+
+	setInt := func(name string, value int) {
+		if value != 0 {
+			values.Set(name, strconv.Itoa(value))
+		}
+	}
+
+	setStr(`city`, filter.City)
+	setStr(`country`, filter.Country)
+	setStr(`name`, filter.Name)
+	setInt(`minStars`, filter.MinStars)
+	setInt(`maxStars`, filter.MaxStars)
+	setInt(`minPrice`, filter.MinPrice)
+	setInt(`maxPrice`, filter.MaxPrice)
+	setStr(`priceCurrency`, filter.PriceCurrency)
+	setStr(`sortBy`, filter.SortBy)
+	setStr(`sortOrder`, filter.SortOrder)
+}
+
+func ValidatePromoCode(
+	promoCode *PromoCode,
+) error {
+	if strings.Trim(promoCode.Code, ` `) == `` {
+		return serverrors.ErrInvalidPromoCode
+	}
+
+	if promoCode.Kind == PromoKindPercent {
+		if promoCode.Value <= 0 || promoCode.Value > 100 {
+			return serverrors.ErrInvalidPromoCode
+		}
+	} else if promoCode.Kind == PromoKindFixed {
+		if promoCode.Value <= 0 {
+			return serverrors.ErrInvalidPromoCode
+		}
+	} else {
+		return serverrors.ErrInvalidPromoCode
+	}
+
+	validFrom, err := time.Parse(time.DateOnly, promoCode.ValidFrom)
+
+	if err != nil {
+		return serverrors.ErrInvalidPromoCode
+	}
+
+	validTo, err := time.Parse(time.DateOnly, promoCode.ValidTo)
+
+	if err != nil || validTo.Before(validFrom) {
+		return serverrors.ErrInvalidPromoCode
+	}
+
+	if promoCode.MaxUses < 0 || promoCode.MaxUsesPerUser < 0 || promoCode.HotelId < 0 {
+		return serverrors.ErrInvalidPromoCode
+	}
+
+	return nil
+}
+
+func ValidateCancellationPolicy(
+	policy *CancellationPolicy,
+) (validErrRes ValidationErrorResponse, err error) {
+	if policy.FreeCancellationDays < 0 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `freeCancellationDays`, Error: `value must not be negative`})
+	}
+
+	if policy.PenaltyPercent < 0 || policy.PenaltyPercent > 100 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `penaltyPercent`, Error: `value must be between 0 and 100`})
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid cancellation policy`
+		err = serverrors.ErrInvalidCancelPolicy
+	}
+
+	return validErrRes, err
+}
+
+func ComputeCancellationRefund(
+	policy *CancellationPolicy,
+	paidAmount int,
+	startDate string,
+	now time.Time,
+) int {
+	if policy == nil {
+		return paidAmount
+	}
+
+	if policy.NonRefundable {
+		return 0
+	}
+
+	start, err := time.Parse(time.DateOnly, startDate)
+
+	if err != nil {
+		return paidAmount
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysBeforeStart := int(start.Sub(today).Hours() / 24)
+
+	if daysBeforeStart >= policy.FreeCancellationDays {
+		return paidAmount
+	}
+
+	return paidAmount - percentOf(paidAmount, policy.PenaltyPercent)
+}
+
+func ValidateRateRule(
+	rule *RateRule,
+) (validErrRes ValidationErrorResponse, err error) {
+	switch rule.Kind {
+	case RateRuleKindSeason:
+		if rule.Percent < -100 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `value must not be less than -100`})
+		}
+	case RateRuleKindWeekday:
+		if rule.Percent < -100 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `value must not be less than -100`})
+		}
+
+		if len(rule.Weekdays) == 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `weekdays`, Error: `field is required`})
+		}
+
+		for _, weekday := range rule.Weekdays {
+			if weekday < int(time.Sunday) || weekday > int(time.Saturday) {
+				validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `weekdays`, Error: `values must be between 0 (Sunday) and 6 (Saturday)`})
+				break
+			}
+		}
+	case RateRuleKindMinStay:
+		if rule.MinNights <= 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `minNights`, Error: `value must be positive`})
+		}
+	case RateRuleKindLongStay:
+		if rule.MinNights <= 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `minNights`, Error: `value must be positive`})
+		}
+
+		if rule.Percent <= 0 || rule.Percent > 100 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `value must be between 1 and 100`})
+		}
+	default:
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `kind`, Error: `unknown rate rule kind`})
+	}
+
+	validFrom, fromErr := time.Parse(time.DateOnly, rule.ValidFrom)
+	validTo, toErr := time.Parse(time.DateOnly, rule.ValidTo)
+
+	if rule.ValidFrom != `` && fromErr != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `validFrom`, Error: `invalid date format`})
+	}
+
+	if rule.ValidTo != `` && toErr != nil {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `validTo`, Error: `invalid date format`})
+	}
+
+	if fromErr == nil && toErr == nil && validTo.Before(validFrom) {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `validTo`, Error: `invalid date period`})
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid rate rule`
+		err = serverrors.ErrInvalidRateRule
+	}
+
+	return validErrRes, err
+}
+
+func ValidateRateRules(
+	rules []RateRule,
+) (validErrRes ValidationErrorResponse, err error) {
+	for i := range rules {
+		ruleErrRes, ruleErr := ValidateRateRule(&rules[i])
+
+		if ruleErr != nil {
+			for _, errDescription := range ruleErrRes.Errors {
+				errDescription.Field = fmt.Sprintf(`rules[%d].%s`, i, errDescription.Field)
+				validErrRes.Errors = append(validErrRes.Errors, errDescription)
+			}
+		}
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid rate rules`
+		err = serverrors.ErrInvalidRateRule
+	}
+
+	return validErrRes, err
+}
+
+// Both ends of a rule's date range are inclusive; an empty end is unbounded.
+func rateRuleCoversDate(rule *RateRule, date string) bool {
+	return (rule.ValidFrom == `` || rule.ValidFrom <= date) &&
+		(rule.ValidTo == `` || date <= rule.ValidTo)
+}
+
+func NightlyRates(
+	basePrice int,
+	rules []RateRule,
+	from string,
+	to string,
+) []NightlyPrice {
+	nightlyPrices := make([]NightlyPrice, 0)
+
+	startDate, startErr := time.Parse(time.DateOnly, from)
+	endDate, endErr := time.Parse(time.DateOnly, to)
+
+	if startErr != nil || endErr != nil {
+		return nightlyPrices
+	}
+
+	for night := startDate; night.Before(endDate); night = night.AddDate(0, 0, 1) {
+		date := night.Format(time.DateOnly)
+		price := basePrice
+
+		for i := range rules {
+			if !rateRuleCoversDate(&rules[i], date) {
+				continue
+			}
+
+			if rules[i].Kind == RateRuleKindSeason ||
+				(rules[i].Kind == RateRuleKindWeekday && slices.Contains(rules[i].Weekdays, int(night.Weekday()))) {
+				price += percentOf(basePrice, rules[i].Percent)
+			}
+		}
+
+		nightlyPrices = append(nightlyPrices, NightlyPrice{Date: date, Price: max(price, 0)})
+	}
+
+	return nightlyPrices
+}
+
+// Season and weekday surcharges are summed per night on top of the hotel price.
+// Minimum stay and long-stay rules are matched against the check-in date, and
+// only the largest applicable long-stay discount is taken.
+func ComputeNightlyPrices(
+	hotel *Hotel,
+	rules []RateRule,
+	from string,
+	to string,
+) (priceRes NightlyPriceResponse, err error) {
+	priceRes.HotelUid = hotel.Uid
+	priceRes.From = from
+	priceRes.To = to
+	priceRes.Currency = NormalizeCurrency(hotel.Currency)
+	priceRes.Nights = NightlyRates(hotel.Price, rules, from, to)
+
+	nightsCount := len(priceRes.Nights)
+
+	for _, nightlyPrice := range priceRes.Nights {
+		priceRes.Subtotal += nightlyPrice.Price
+	}
+
+	for i := range rules {
+		if !rateRuleCoversDate(&rules[i], from) {
+			continue
+		}
+
+		if rules[i].Kind == RateRuleKindMinStay && nightsCount < rules[i].MinNights {
+			return priceRes, serverrors.ErrMinStayNotMet
+		}
+
+		if rules[i].Kind == RateRuleKindLongStay && nightsCount >= rules[i].MinNights {
+			priceRes.LongStayPercent = max(priceRes.LongStayPercent, rules[i].Percent)
+		}
+	}
+
+	priceRes.LongStayDiscount = percentOf(priceRes.Subtotal, priceRes.LongStayPercent)
+	priceRes.TotalPrice = priceRes.Subtotal - priceRes.LongStayDiscount
+
+	return priceRes, nil
+}
+
+func percentOf(price int, percent int) int {
+	return int(roundDiv(int64(price)*int64(percent), 100))
+}
+
+// A quoted price stays valid while it differs from the current price by at
+// most maxDriftPercent of the current price.
+func IsWithinPriceLock(quotedPrice int, currentPrice int, maxDriftPercent int) bool {
+	drift := quotedPrice - currentPrice
+
+	if drift < 0 {
+		drift = -drift
+	}
+
+	return drift <= percentOf(currentPrice, maxDriftPercent)
+}
+
+func percentFromAmount(amount int, total int) int {
+	return int(roundDiv(int64(amount)*100, int64(total)))
+}
+
+// Fixed promo values are minor units of the currency of the price they are applied to.
+func promoDiscountAmount(price Money, promoCode *PromoCode) Money {
+	if promoCode.Kind == PromoKindPercent {
+		return price.Percent(promoCode.Value)
+	}
+
+	return Money{min(promoCode.Value, price.Amount), price.Currency}
+}
+
+// Loyalty discount is applied to the base price first. A stackable promo code is
+// then applied to the already discounted price; a non-stackable one competes with
+// the loyalty discount and only the larger of the two is applied.
+func ApplyDiscounts(
+	basePrice Money,
+	loyaltyDiscount int,
+	promoCode *PromoCode,
+) (price Money, appliedDiscounts []AppliedDiscount) {
+	appliedDiscounts = make([]AppliedDiscount, 0)
+	price = basePrice
+
+	loyaltyAmount := Money{0, basePrice.Currency}
+
+	if loyaltyDiscount > 0 {
+		loyaltyAmount = basePrice.Percent(loyaltyDiscount)
+	}
+
+	if promoCode == nil || promoCode.Stackable {
+		if loyaltyAmount.Amount > 0 {
+			price, _ = price.Sub(loyaltyAmount)
+			appliedDiscounts = append(appliedDiscounts, AppliedDiscount{
+				Source: DiscountSourceLoyalty, Percent: loyaltyDiscount, Amount: loyaltyAmount.Amount,
+			})
+		}
+
+		if promoCode != nil {
+			promoAmount := promoDiscountAmount(price, promoCode)
+			price, _ = price.Sub(promoAmount)
+			appliedDiscounts = append(appliedDiscounts, promoCodeToAppliedDiscount(promoCode, promoAmount.Amount))
+		}
+
+		return price, appliedDiscounts
+	}
+
+	promoAmount := promoDiscountAmount(basePrice, promoCode)
+
+	if loyaltyAmount.Amount >= promoAmount.Amount && loyaltyAmount.Amount > 0 {
+		price, _ = price.Sub(loyaltyAmount)
+		appliedDiscounts = append(appliedDiscounts, AppliedDiscount{
+			Source: DiscountSourceLoyalty, Percent: loyaltyDiscount, Amount: loyaltyAmount.Amount,
+		})
+	} else if promoAmount.Amount > 0 {
+		price, _ = price.Sub(promoAmount)
+		appliedDiscounts = append(appliedDiscounts, promoCodeToAppliedDiscount(promoCode, promoAmount.Amount))
+	}
+
+	return price, appliedDiscounts
+}
+
+func promoCodeToAppliedDiscount(promoCode *PromoCode, amount int) (appliedDiscount AppliedDiscount) {
+	appliedDiscount.Source = DiscountSourcePromo
+	appliedDiscount.Code = promoCode.Code
+	appliedDiscount.Amount = amount
+
+	if promoCode.Kind == PromoKindPercent {
+		appliedDiscount.Percent = promoCode.Value
+	}
+
+	return appliedDiscount
+}
+
 func UpdateLoyaltyStatus(
 	loyalty *Loyalty,
 ) {
-	if loyalty.ReservationCount > 20 {
+	UpdateLoyaltyStatusByCount(loyalty, loyalty.ReservationCount)
+}
+
+func UpdateLoyaltyStatusByCount(
+	loyalty *Loyalty,
+	qualifyingCount int,
+) {
+	if qualifyingCount > 20 {
 		loyalty.Status = `GOLD`
 		loyalty.Discount = 10
-	} else if loyalty.ReservationCount > 10 {
+	} else if qualifyingCount > 10 {
 		loyalty.Status = `SILVER`
 		loyalty.Discount = 7
 	} else {
@@ -287,3 +1624,143 @@ func UpdateLoyaltyStatus(
 		loyalty.Discount = 5
 	}
 }
+
+// Reservations which were paid for and count towards the loyalty programme.
+func isPaidReservStatus(status string) bool {
+	return status == ReservStatusPaid || status == ReservStatusCheckedIn ||
+		status == ReservStatusCompleted || status == ReservStatusNoShow
+}
+
+// Records without a valid timestamp are treated as settled.
+func isUpdatedAfter(updatedAt string, cutoff time.Time) bool {
+	updatedAtTime, err := time.Parse(time.RFC3339, updatedAt)
+
+	return err == nil && updatedAtTime.After(cutoff)
+}
+
+// PENDING reservations are skipped, as they are being created or expire on their own.
+// Payments left without a reservation are reported unless they are already settled.
+// Records updated after settledBefore may be in the middle of a booking or a
+// cancellation, so they are skipped along with the loyalty of their users.
+// A loyalty count above the paid reservations may include stays made before
+// reservations were recorded, so it is reported but not fixed.
+func FindReconcileIssues(
+	reservations []Reservation,
+	payments []Payment,
+	loyalties []Loyalty,
+	settledBefore time.Time,
+) []ReconcileIssue {
+	issues := []ReconcileIssue{}
+	paymentsByUid := make(map[string]Payment, len(payments))
+	paidCounts := make(map[string]int)
+	unsettledUsers := make(map[string]bool)
+
+	for _, payment := range payments {
+		paymentsByUid[payment.Uid] = payment
+	}
+
+	for _, reservation := range reservations {
+		if isPaidReservStatus(reservation.Status) {
+			paidCounts[reservation.Username]++
+		}
+
+		payment, found := paymentsByUid[reservation.PaymentUid]
+		delete(paymentsByUid, reservation.PaymentUid)
+
+		if isUpdatedAfter(reservation.UpdatedAt, settledBefore) {
+			unsettledUsers[reservation.Username] = true
+			continue
+		}
+
+		if reservation.Status == ReservStatusPending {
+			continue
+		}
+
+		if !found {
+			issues = append(issues, ReconcileIssue{
+				Kind:           ReconcileIssueMissingPayment,
+				ReservationUid: reservation.Uid,
+				PaymentUid:     reservation.PaymentUid,
+				Username:       reservation.Username,
+				Details:        fmt.Sprintf(`%s reservation has no payment`, reservation.Status),
+				Fixable:        CanChangeReservStatus(reservation.Status, ReservStatusCanceled),
+			})
+
+			continue
+		}
+
+		paymentStatus := PaymentStatusSummary(payment.Status)
+
+		if isPaidReservStatus(reservation.Status) && paymentStatus != PaymentStatusPaid ||
+			reservation.Status == ReservStatusCanceled && payment.Status == PaymentStatusAuthorized {
+			issues = append(issues, ReconcileIssue{
+				Kind:           ReconcileIssuePaymentMismatch,
+				ReservationUid: reservation.Uid,
+				PaymentUid:     payment.Uid,
+				Username:       reservation.Username,
+				Details:        fmt.Sprintf(`%s reservation has %s payment`, reservation.Status, payment.Status),
+				Fixable:        payment.Status == PaymentStatusAuthorized && reservation.Status == ReservStatusCanceled,
+			})
+		}
+	}
+
+	for _, payment := range payments {
+		if _, found := paymentsByUid[payment.Uid]; !found {
+			continue
+		}
+
+		if payment.Status == PaymentStatusVoided || payment.Status == PaymentStatusRefunded ||
+			isUpdatedAfter(payment.UpdatedAt, settledBefore) {
+			continue
+		}
+
+		issues = append(issues, ReconcileIssue{
+			Kind:       ReconcileIssueOrphanedPayment,
+			PaymentUid: payment.Uid,
+			Details:    fmt.Sprintf(`%s payment has no reservation`, payment.Status),
+			Actual:     payment.Price - payment.RefundedAmount,
+			Fixable:    true,
+		})
+	}
+
+	for _, loyalty := range loyalties {
+		paidCount := paidCounts[loyalty.Username]
+		delete(paidCounts, loyalty.Username)
+
+		if unsettledUsers[loyalty.Username] {
+			continue
+		}
+
+		if paidCount != loyalty.ReservationCount {
+			issues = append(issues, ReconcileIssue{
+				Kind:     ReconcileIssueLoyaltyMismatch,
+				Username: loyalty.Username,
+				Details:  `loyalty reservation count differs from paid reservations`,
+				Expected: paidCount,
+				Actual:   loyalty.ReservationCount,
+				Fixable:  paidCount > loyalty.ReservationCount,
+			})
+		}
+	}
+
+	usernames := make([]string, 0, len(paidCounts))
+
+	for username := range paidCounts {
+		if !unsettledUsers[username] {
+			usernames = append(usernames, username)
+		}
+	}
+
+	slices.Sort(usernames)
+
+	for _, username := range usernames {
+		issues = append(issues, ReconcileIssue{
+			Kind:     ReconcileIssueLoyaltyMismatch,
+			Username: username,
+			Details:  `user with paid reservations has no loyalty`,
+			Expected: paidCounts[username],
+		})
+	}
+
+	return issues
+}
@@ -0,0 +1,318 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyDiscounts(t *testing.T) {
+	percentPromo := &PromoCode{Code: `SPRING`, Kind: PromoKindPercent, Value: 10, Stackable: true}
+	bigFixedPromo := &PromoCode{Code: `BIG`, Kind: PromoKindFixed, Value: 1500}
+	smallFixedPromo := &PromoCode{Code: `SMALL`, Kind: PromoKindFixed, Value: 500}
+	hugeFixedPromo := &PromoCode{Code: `HUGE`, Kind: PromoKindFixed, Value: 20000}
+
+	tests := []struct {
+		name            string
+		basePrice       Money
+		loyaltyDiscount int
+		promoCode       *PromoCode
+		wantPrice       Money
+		wantDiscounts   []AppliedDiscount
+	}{
+		{
+			`no discounts`,
+			Money{10000, `RUB`}, 0, nil,
+			Money{10000, `RUB`}, []AppliedDiscount{},
+		},
+		{
+			`loyalty only`,
+			Money{30000, `RUB`}, 10, nil,
+			Money{27000, `RUB`},
+			[]AppliedDiscount{{Source: DiscountSourceLoyalty, Percent: 10, Amount: 3000}},
+		},
+		{
+			`stackable promo applies to the discounted price`,
+			Money{10000, `RUB`}, 10, percentPromo,
+			Money{8100, `RUB`},
+			[]AppliedDiscount{
+				{Source: DiscountSourceLoyalty, Percent: 10, Amount: 1000},
+				{Source: DiscountSourcePromo, Code: `SPRING`, Percent: 10, Amount: 900},
+			},
+		},
+		{
+			`larger non-stackable promo wins`,
+			Money{10000, `RUB`}, 10, bigFixedPromo,
+			Money{8500, `RUB`},
+			[]AppliedDiscount{{Source: DiscountSourcePromo, Code: `BIG`, Amount: 1500}},
+		},
+		{
+			`larger loyalty discount wins`,
+			Money{10000, `RUB`}, 10, smallFixedPromo,
+			Money{9000, `RUB`},
+			[]AppliedDiscount{{Source: DiscountSourceLoyalty, Percent: 10, Amount: 1000}},
+		},
+		{
+			`fixed promo never exceeds the price`,
+			Money{10000, `RUB`}, 0, hugeFixedPromo,
+			Money{0, `RUB`},
+			[]AppliedDiscount{{Source: DiscountSourcePromo, Code: `HUGE`, Amount: 10000}},
+		},
+		{
+			`loyalty rounds half away from zero`,
+			Money{1010, `RUB`}, 5, nil,
+			Money{959, `RUB`},
+			[]AppliedDiscount{{Source: DiscountSourceLoyalty, Percent: 5, Amount: 51}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotPrice, gotDiscounts := ApplyDiscounts(test.basePrice, test.loyaltyDiscount, test.promoCode)
+
+			if gotPrice != test.wantPrice {
+				t.Errorf(`ApplyDiscounts() price = %v, want %v`, gotPrice, test.wantPrice)
+			}
+
+			if !reflect.DeepEqual(gotDiscounts, test.wantDiscounts) {
+				t.Errorf(`ApplyDiscounts() discounts = %v, want %v`, gotDiscounts, test.wantDiscounts)
+			}
+		})
+	}
+}
+
+func TestComputeCancellationRefund(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 15, 0, 0, 0, time.UTC)
+	weekPolicy := &CancellationPolicy{FreeCancellationDays: 7, PenaltyPercent: 50}
+
+	tests := []struct {
+		name       string
+		policy     *CancellationPolicy
+		paidAmount int
+		startDate  string
+		want       int
+	}{
+		{`no policy`, nil, 27000, `2026-10-20`, 27000},
+		{`non-refundable`, &CancellationPolicy{NonRefundable: true}, 27000, `2026-12-01`, 0},
+		{`last free day`, weekPolicy, 27000, `2026-10-26`, 27000},
+		{`penalty after the free period`, weekPolicy, 27000, `2026-10-25`, 13500},
+		{`penalty rounds half away from zero`, weekPolicy, 999, `2026-10-25`, 499},
+		{`stay starts today`, &CancellationPolicy{PenaltyPercent: 100}, 27000, `2026-10-19`, 27000},
+		{`stay has started`, &CancellationPolicy{PenaltyPercent: 100}, 27000, `2026-10-18`, 0},
+		{`invalid start date`, weekPolicy, 27000, `not a date`, 27000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ComputeCancellationRefund(test.policy, test.paidAmount, test.startDate, now)
+
+			if got != test.want {
+				t.Errorf(`ComputeCancellationRefund() = %d, want %d`, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLedgerEntriesForTransaction(t *testing.T) {
+	tests := []struct {
+		name               string
+		transaction        PaymentTransaction
+		platformFeePercent int
+		want               []LedgerEntry
+	}{
+		{
+			`capture is split between hotel and platform`,
+			PaymentTransaction{Kind: PaymentTransactionCapture, Amount: 27000}, 10,
+			[]LedgerEntry{
+				{Account: LedgerAccountCustomer, Debit: 27000},
+				{Account: LedgerAccountHotel, Credit: 24300},
+				{Account: LedgerAccountPlatformRevenue, Credit: 2700},
+			},
+		},
+		{
+			`fee rounds half away from zero`,
+			PaymentTransaction{Kind: PaymentTransactionCapture, Amount: 1005}, 10,
+			[]LedgerEntry{
+				{Account: LedgerAccountCustomer, Debit: 1005},
+				{Account: LedgerAccountHotel, Credit: 904},
+				{Account: LedgerAccountPlatformRevenue, Credit: 101},
+			},
+		},
+		{
+			`no platform fee`,
+			PaymentTransaction{Kind: PaymentTransactionCapture, Amount: 1000}, 0,
+			[]LedgerEntry{
+				{Account: LedgerAccountCustomer, Debit: 1000},
+				{Account: LedgerAccountHotel, Credit: 1000},
+			},
+		},
+		{
+			`whole amount is the fee`,
+			PaymentTransaction{Kind: PaymentTransactionCapture, Amount: 1000}, 100,
+			[]LedgerEntry{
+				{Account: LedgerAccountCustomer, Debit: 1000},
+				{Account: LedgerAccountPlatformRevenue, Credit: 1000},
+			},
+		},
+		{
+			`refund`,
+			PaymentTransaction{Kind: PaymentTransactionRefund, Amount: 500}, 10,
+			[]LedgerEntry{
+				{Account: LedgerAccountRefunds, Debit: 500},
+				{Account: LedgerAccountCustomer, Credit: 500},
+			},
+		},
+		{`authorization is not posted`, PaymentTransaction{Kind: PaymentTransactionAuthorize, Amount: 1000}, 10, nil},
+		{`void is not posted`, PaymentTransaction{Kind: PaymentTransactionVoid, Amount: 1000}, 10, nil},
+		{`zero amount is not posted`, PaymentTransaction{Kind: PaymentTransactionCapture}, 10, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := LedgerEntriesForTransaction(&test.transaction, test.platformFeePercent)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf(`LedgerEntriesForTransaction() = %v, want %v`, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindReconcileIssues(t *testing.T) {
+	settledBefore := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	settled := `2026-10-19T11:00:00Z`
+	recent := `2026-10-19T12:03:00Z`
+
+	tests := []struct {
+		name         string
+		reservations []Reservation
+		payments     []Payment
+		loyalties    []Loyalty
+		want         []ReconcileIssue
+	}{
+		{
+			`consistent booking`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusPaid, UpdatedAt: settled}},
+			[]Payment{{Uid: `p1`, Status: PaymentStatusCaptured, Price: 10000, UpdatedAt: settled}},
+			[]Loyalty{{Username: `u1`, ReservationCount: 1}},
+			[]ReconcileIssue{},
+		},
+		{
+			`missing payment`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusPaid, UpdatedAt: settled}},
+			nil,
+			[]Loyalty{{Username: `u1`, ReservationCount: 1}},
+			[]ReconcileIssue{{
+				Kind: ReconcileIssueMissingPayment, ReservationUid: `r1`, PaymentUid: `p1`, Username: `u1`,
+				Details: `PAID reservation has no payment`, Fixable: true,
+			}},
+		},
+		{
+			`recent reservation and its user's loyalty are skipped`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusPaid, UpdatedAt: recent}},
+			nil,
+			[]Loyalty{{Username: `u1`, ReservationCount: 0}},
+			[]ReconcileIssue{},
+		},
+		{
+			`pending reservation is skipped`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusPending, UpdatedAt: settled}},
+			[]Payment{{Uid: `p1`, Status: PaymentStatusAuthorized, Price: 10000, UpdatedAt: settled}},
+			nil,
+			[]ReconcileIssue{},
+		},
+		{
+			`canceled reservation with authorized payment`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusCanceled, UpdatedAt: settled}},
+			[]Payment{{Uid: `p1`, Status: PaymentStatusAuthorized, Price: 10000, UpdatedAt: settled}},
+			nil,
+			[]ReconcileIssue{{
+				Kind: ReconcileIssuePaymentMismatch, ReservationUid: `r1`, PaymentUid: `p1`, Username: `u1`,
+				Details: `CANCELED reservation has AUTHORIZED payment`, Fixable: true,
+			}},
+		},
+		{
+			`paid reservation with voided payment is not fixable`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusPaid, UpdatedAt: settled}},
+			[]Payment{{Uid: `p1`, Status: PaymentStatusVoided, Price: 10000, UpdatedAt: settled}},
+			[]Loyalty{{Username: `u1`, ReservationCount: 1}},
+			[]ReconcileIssue{{
+				Kind: ReconcileIssuePaymentMismatch, ReservationUid: `r1`, PaymentUid: `p1`, Username: `u1`,
+				Details: `PAID reservation has VOIDED payment`,
+			}},
+		},
+		{
+			`orphaned payment`,
+			nil,
+			[]Payment{{Uid: `p1`, Status: PaymentStatusPartiallyRefunded, Price: 10000, RefundedAmount: 4000, UpdatedAt: settled}},
+			nil,
+			[]ReconcileIssue{{
+				Kind: ReconcileIssueOrphanedPayment, PaymentUid: `p1`,
+				Details: `PARTIALLY_REFUNDED payment has no reservation`, Actual: 6000, Fixable: true,
+			}},
+		},
+		{
+			`recent orphaned payment is skipped`,
+			nil,
+			[]Payment{{Uid: `p1`, Status: PaymentStatusAuthorized, Price: 10000, UpdatedAt: recent}},
+			nil,
+			[]ReconcileIssue{},
+		},
+		{
+			`settled orphaned payments are skipped`,
+			nil,
+			[]Payment{
+				{Uid: `p1`, Status: PaymentStatusVoided, Price: 10000, UpdatedAt: settled},
+				{Uid: `p2`, Status: PaymentStatusRefunded, Price: 10000, RefundedAmount: 10000, UpdatedAt: settled},
+			},
+			nil,
+			[]ReconcileIssue{},
+		},
+		{
+			`loyalty above paid reservations is not fixable`,
+			nil,
+			nil,
+			[]Loyalty{{Username: `Test Max`, ReservationCount: 25}},
+			[]ReconcileIssue{{
+				Kind: ReconcileIssueLoyaltyMismatch, Username: `Test Max`,
+				Details: `loyalty reservation count differs from paid reservations`, Actual: 25,
+			}},
+		},
+		{
+			`loyalty below paid reservations is fixable`,
+			[]Reservation{{Uid: `r1`, Username: `u1`, PaymentUid: `p1`, Status: ReservStatusPaid, UpdatedAt: settled}},
+			[]Payment{{Uid: `p1`, Status: PaymentStatusCaptured, Price: 10000, UpdatedAt: settled}},
+			[]Loyalty{{Username: `u1`, ReservationCount: 0}},
+			[]ReconcileIssue{{
+				Kind: ReconcileIssueLoyaltyMismatch, Username: `u1`,
+				Details: `loyalty reservation count differs from paid reservations`, Expected: 1, Fixable: true,
+			}},
+		},
+		{
+			`paid reservations without loyalty`,
+			[]Reservation{
+				{Uid: `r1`, Username: `u2`, PaymentUid: `p1`, Status: ReservStatusPaid, UpdatedAt: settled},
+				{Uid: `r2`, Username: `u1`, PaymentUid: `p2`, Status: ReservStatusCompleted, UpdatedAt: settled},
+			},
+			[]Payment{
+				{Uid: `p1`, Status: PaymentStatusCaptured, Price: 10000, UpdatedAt: settled},
+				{Uid: `p2`, Status: PaymentStatusCaptured, Price: 10000, UpdatedAt: settled},
+			},
+			nil,
+			[]ReconcileIssue{
+				{Kind: ReconcileIssueLoyaltyMismatch, Username: `u1`, Details: `user with paid reservations has no loyalty`, Expected: 1},
+				{Kind: ReconcileIssueLoyaltyMismatch, Username: `u2`, Details: `user with paid reservations has no loyalty`, Expected: 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FindReconcileIssues(test.reservations, test.payments, test.loyalties, settledBefore)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FindReconcileIssues() =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+const (
+	TaxKindVAT        = `VAT`
+	TaxKindTouristTax = `TOURIST_TAX`
+	TaxKindServiceFee = `SERVICE_FEE`
+)
+
+const (
+	PaymentLineItemRoom     = `ROOM`
+	PaymentLineItemDiscount = `DISCOUNT`
+)
+
+const DefaultGuests = 1
+const MaxGuests = 10
+
+// A rule without a city applies to the whole country. Amounts are minor units
+// of the hotel currency: per night per guest for tourist tax and per booking
+// for a fixed service fee.
+type TaxRule struct {
+	Id      int    `json:"id"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+	City    string `json:"city,omitempty"`
+	Percent int    `json:"percent,omitempty"`
+	Amount  int    `json:"amount,omitempty"`
+}
+
+type AppliedTax struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Percent int    `json:"percent,omitempty"`
+	Amount  int    `json:"amount"`
+}
+
+type PaymentLineItem struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description,omitempty"`
+	Amount      int    `json:"amount"`
+}
+
+func ValidateTaxRule(
+	rule *TaxRule,
+) (validErrRes ValidationErrorResponse, err error) {
+	switch rule.Kind {
+	case TaxKindVAT:
+		if rule.Percent <= 0 || rule.Percent > 100 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `value must be between 1 and 100`})
+		}
+
+		if rule.Amount != 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `amount`, Error: `field is not allowed for VAT`})
+		}
+	case TaxKindTouristTax:
+		if rule.Amount <= 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `amount`, Error: `value must be positive`})
+		}
+
+		if rule.Percent != 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `field is not allowed for tourist tax`})
+		}
+	case TaxKindServiceFee:
+		if (rule.Percent == 0) == (rule.Amount == 0) {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `exactly one of percent and amount is required`})
+		} else if rule.Percent < 0 || rule.Percent > 100 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `percent`, Error: `value must be between 1 and 100`})
+		} else if rule.Amount < 0 {
+			validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `amount`, Error: `value must be positive`})
+		}
+	default:
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `kind`, Error: `unknown tax kind`})
+	}
+
+	if strings.TrimSpace(rule.Name) == `` {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `name`, Error: `field is required`})
+	} else if utf8.RuneCountInString(rule.Name) > 80 {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `name`, Error: `value must not be longer than 80 characters`})
+	}
+
+	if strings.TrimSpace(rule.Country) == `` {
+		validErrRes.Errors = append(validErrRes.Errors, ErrorDiscription{Field: `country`, Error: `field is required`})
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid tax rule`
+		err = serverrors.ErrInvalidTaxRule
+	}
+
+	return validErrRes, err
+}
+
+func ValidateTaxRules(
+	rules []TaxRule,
+) (validErrRes ValidationErrorResponse, err error) {
+	for i := range rules {
+		ruleErrRes, ruleErr := ValidateTaxRule(&rules[i])
+
+		if ruleErr != nil {
+			for _, errDescription := range ruleErrRes.Errors {
+				errDescription.Field = fmt.Sprintf(`rules[%d].%s`, i, errDescription.Field)
+				validErrRes.Errors = append(validErrRes.Errors, errDescription)
+			}
+		}
+	}
+
+	if len(validErrRes.Errors) != 0 {
+		validErrRes.Message = `invalid tax rules`
+		err = serverrors.ErrInvalidTaxRule
+	}
+
+	return validErrRes, err
+}
+
+// City rules replace country-wide rules of the same kind, so a city can
+// override the national tourist tax without dropping the national VAT.
+func SelectTaxRules(rules []TaxRule, country string, city string) []TaxRule {
+	cityKinds := make(map[string]bool)
+
+	for i := range rules {
+		if strings.EqualFold(rules[i].Country, country) && rules[i].City != `` && strings.EqualFold(rules[i].City, city) {
+			cityKinds[rules[i].Kind] = true
+		}
+	}
+
+	selected := make([]TaxRule, 0)
+
+	for i := range rules {
+		if !strings.EqualFold(rules[i].Country, country) {
+			continue
+		}
+
+		if rules[i].City == `` && !cityKinds[rules[i].Kind] || rules[i].City != `` && strings.EqualFold(rules[i].City, city) {
+			selected = append(selected, rules[i])
+		}
+	}
+
+	return selected
+}
+
+func taxRuleToAppliedTax(rule *TaxRule, amount Money) AppliedTax {
+	return AppliedTax{Kind: rule.Kind, Name: rule.Name, Percent: rule.Percent, Amount: amount.Amount}
+}
+
+// Service fees are charged on the discounted price and VAT on the price with
+// the fees included. Tourist tax is charged per night per guest and is not
+// subject to VAT.
+func ApplyTaxes(
+	price Money,
+	nights int,
+	guests int,
+	rules []TaxRule,
+) (total Money, appliedTaxes []AppliedTax) {
+	appliedTaxes = make([]AppliedTax, 0)
+	total = price
+
+	for _, kind := range []string{TaxKindServiceFee, TaxKindVAT, TaxKindTouristTax} {
+		taxBase := total
+
+		for i := range rules {
+			if rules[i].Kind != kind {
+				continue
+			}
+
+			var amount Money
+
+			switch {
+			case kind == TaxKindTouristTax:
+				amount = Money{rules[i].Amount * nights * guests, price.Currency}
+			case rules[i].Percent > 0:
+				amount = taxBase.Percent(rules[i].Percent)
+			default:
+				amount = Money{rules[i].Amount, price.Currency}
+			}
+
+			if amount.Amount <= 0 {
+				continue
+			}
+
+			total, _ = total.Add(amount)
+			appliedTaxes = append(appliedTaxes, taxRuleToAppliedTax(&rules[i], amount))
+		}
+	}
+
+	return total, appliedTaxes
+}
+
+func TaxAmount(appliedTaxes []AppliedTax) (amount int) {
+	for _, appliedTax := range appliedTaxes {
+		amount += appliedTax.Amount
+	}
+
+	return amount
+}
+
+// The items add up to the quoted total: room nights, minus every discount,
+// plus every tax and fee.
+func QuoteToPaymentLineItems(quote *PriceQuoteResponse) []PaymentLineItem {
+	lineItems := []PaymentLineItem{{
+		Kind:        PaymentLineItemRoom,
+		Description: fmt.Sprintf(`%d night(s)`, quote.Nights),
+		Amount:      quote.BasePrice,
+	}}
+
+	for _, appliedDiscount := range quote.AppliedDiscounts {
+		lineItems = append(lineItems, PaymentLineItem{
+			Kind:        PaymentLineItemDiscount,
+			Description: appliedDiscount.Source,
+			Amount:      -appliedDiscount.Amount,
+		})
+	}
+
+	for _, appliedTax := range quote.Taxes {
+		lineItems = append(lineItems, PaymentLineItem{
+			Kind:        appliedTax.Kind,
+			Description: appliedTax.Name,
+			Amount:      appliedTax.Amount,
+		})
+	}
+
+	return lineItems
+}
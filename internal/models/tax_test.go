@@ -0,0 +1,113 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectTaxRules(t *testing.T) {
+	vat := TaxRule{Id: 1, Kind: TaxKindVAT, Name: `VAT`, Country: `Россия`, Percent: 20}
+	touristTax := TaxRule{Id: 2, Kind: TaxKindTouristTax, Name: `Tourist tax`, Country: `Россия`, Amount: 100}
+	cityTouristTax := TaxRule{Id: 3, Kind: TaxKindTouristTax, Name: `City tourist tax`, Country: `Россия`, City: `Москва`, Amount: 150}
+	otherVat := TaxRule{Id: 4, Kind: TaxKindVAT, Name: `VAT`, Country: `France`, Percent: 20}
+	rules := []TaxRule{vat, touristTax, cityTouristTax, otherVat}
+
+	tests := []struct {
+		name    string
+		country string
+		city    string
+		want    []TaxRule
+	}{
+		{`city rule replaces country rule of the same kind`, `Россия`, `Москва`, []TaxRule{vat, cityTouristTax}},
+		{`country rules apply to other cities`, `Россия`, `Казань`, []TaxRule{vat, touristTax}},
+		{`names are compared case-insensitively`, `россия`, `москва`, []TaxRule{vat, cityTouristTax}},
+		{`no rules for the country`, `Italia`, `Roma`, []TaxRule{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := SelectTaxRules(rules, test.country, test.city)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf(`SelectTaxRules() = %v, want %v`, got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyTaxes(t *testing.T) {
+	serviceFee := TaxRule{Kind: TaxKindServiceFee, Name: `Service fee`, Percent: 5}
+	fixedServiceFee := TaxRule{Kind: TaxKindServiceFee, Name: `Booking fee`, Amount: 300}
+	vat := TaxRule{Kind: TaxKindVAT, Name: `VAT`, Percent: 20}
+	touristTax := TaxRule{Kind: TaxKindTouristTax, Name: `Tourist tax`, Amount: 100}
+
+	tests := []struct {
+		name      string
+		price     Money
+		nights    int
+		guests    int
+		rules     []TaxRule
+		wantTotal Money
+		wantTaxes []AppliedTax
+	}{
+		{
+			`no rules`,
+			Money{10000, `RUB`}, 3, 2, nil,
+			Money{10000, `RUB`}, []AppliedTax{},
+		},
+		{
+			`fee before VAT, tourist tax not taxed`,
+			Money{10000, `RUB`}, 3, 2, []TaxRule{serviceFee, vat, touristTax},
+			Money{13200, `RUB`},
+			[]AppliedTax{
+				{Kind: TaxKindServiceFee, Name: `Service fee`, Percent: 5, Amount: 500},
+				{Kind: TaxKindVAT, Name: `VAT`, Percent: 20, Amount: 2100},
+				{Kind: TaxKindTouristTax, Name: `Tourist tax`, Amount: 600},
+			},
+		},
+		{
+			`rule order does not matter`,
+			Money{10000, `RUB`}, 3, 2, []TaxRule{touristTax, vat, serviceFee},
+			Money{13200, `RUB`},
+			[]AppliedTax{
+				{Kind: TaxKindServiceFee, Name: `Service fee`, Percent: 5, Amount: 500},
+				{Kind: TaxKindVAT, Name: `VAT`, Percent: 20, Amount: 2100},
+				{Kind: TaxKindTouristTax, Name: `Tourist tax`, Amount: 600},
+			},
+		},
+		{
+			`fixed fee is charged per booking`,
+			Money{10000, `RUB`}, 3, 2, []TaxRule{fixedServiceFee, vat},
+			Money{12360, `RUB`},
+			[]AppliedTax{
+				{Kind: TaxKindServiceFee, Name: `Booking fee`, Amount: 300},
+				{Kind: TaxKindVAT, Name: `VAT`, Percent: 20, Amount: 2060},
+			},
+		},
+		{
+			`percentage rounds half away from zero`,
+			Money{1005, `RUB`}, 1, 1, []TaxRule{{Kind: TaxKindVAT, Name: `VAT`, Percent: 10}},
+			Money{1106, `RUB`},
+			[]AppliedTax{{Kind: TaxKindVAT, Name: `VAT`, Percent: 10, Amount: 101}},
+		},
+		{
+			`zero amounts are not applied`,
+			Money{4, `RUB`}, 1, 1, []TaxRule{{Kind: TaxKindVAT, Name: `VAT`, Percent: 10}},
+			Money{4, `RUB`}, []AppliedTax{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotTotal, gotTaxes := ApplyTaxes(test.price, test.nights, test.guests, test.rules)
+
+			if gotTotal != test.wantTotal {
+				t.Errorf(`ApplyTaxes() total = %v, want %v`, gotTotal, test.wantTotal)
+			}
+
+			if !reflect.DeepEqual(gotTaxes, test.wantTaxes) {
+				t.Errorf(`ApplyTaxes() taxes = %v, want %v`, gotTaxes, test.wantTaxes)
+			}
+		})
+	}
+}
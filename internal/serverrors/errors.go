@@ -7,6 +7,8 @@ var ErrConfigRead error = errors.New(`error while reading config file`)
 
 var ErrServiceBuild error = errors.New(`error while building service`)
 var ErrControllerPrepare error = errors.New(`error while preparing controller`)
+var ErrInsecureSecret error = errors.New(`secret is empty or a placeholder`)
+var ErrInvalidHoldTtl error = errors.New(`hold TTL must be positive`)
 
 // Implementation errors
 var ErrMethodIsNotImplemented error = errors.New(`method is not implemented`)
@@ -19,7 +21,10 @@ var ErrQueryExec error = errors.New(`error while executing SQL-query`)
 
 // Data errors
 var ErrInvalidPagesData error = errors.New(`invalid pages data`)
+var ErrInvalidHotelFilter error = errors.New(`invalid hotels filter`)
+var ErrInvalidCursor error = errors.New(`invalid pagination cursor`)
 var ErrInvalidUsername error = errors.New(`invalid username`)
+var ErrInvalidLoyaltyCount error = errors.New(`invalid loyalty reservation count`)
 var ErrInvalidCrReservReq error = errors.New(`invalid create reservation request data`)
 
 var ErrInvalidReservUid error = errors.New(`invalid reservation UID`)
@@ -29,13 +34,41 @@ var ErrInvalidReservPayUID error = errors.New(`invalid reservation payment UID f
 var ErrInvalidReservHotelId error = errors.New(`invalid reservation hotel ID field`)
 var ErrInvalidReservStatus error = errors.New(`invalid reservation status field`)
 var ErrInvalidReservDates error = errors.New(`invalid reservation dates`)
+var ErrInvalidStatusTransition error = errors.New(`status transition is not allowed`)
 
 var ErrInvalidHotelId error = errors.New(`invalid hotel ID field`)
 var ErrInvalidHoteUid error = errors.New(`invalid hotel UID`)
+var ErrInvalidHotel error = errors.New(`invalid hotel data`)
+var ErrInvalidCancelPolicy error = errors.New(`invalid cancellation policy`)
+var ErrInvalidRateRule error = errors.New(`invalid rate rule`)
+var ErrInvalidTaxRule error = errors.New(`invalid tax rule`)
+var ErrMinStayNotMet error = errors.New(`minimum stay requirement is not met`)
 
 var ErrInvalidPaymentUid error = errors.New(`invalid payment UID`)
 var ErrInvalidPaymentStatus error = errors.New(`invalid payment status`)
 var ErrInvalidPaymentPrice error = errors.New(`invalid payment price`)
+var ErrInvalidPaymentAmount error = errors.New(`invalid payment amount`)
+var ErrInvalidPaymentTransaction error = errors.New(`invalid payment transaction`)
+var ErrPaymentOperationPending error = errors.New(`another operation on the payment is in progress`)
+var ErrInvalidCrPaymentReq error = errors.New(`invalid create payment request`)
+var ErrIdempotencyKeyReused error = errors.New(`idempotency key was already used for a different payment`)
+var ErrInvalidCurrency error = errors.New(`invalid currency code`)
+var ErrCurrencyMismatch error = errors.New(`amounts are in different currencies`)
+var ErrInvalidAmount error = errors.New(`amount is not a whole number of minor units`)
+var ErrInvalidExchangeRate error = errors.New(`invalid exchange rate`)
+var ErrExchangeRateNotFound error = errors.New(`exchange rate not found`)
+
+var ErrInvalidPromoCode error = errors.New(`invalid promo code`)
+var ErrPromoCodeExpired error = errors.New(`promo code is expired`)
+var ErrPromoCodeExhausted error = errors.New(`promo code usage limit is reached`)
+var ErrPromoCodeNotApplicable error = errors.New(`promo code is not applicable`)
+
+var ErrInvalidToken error = errors.New(`invalid token`)
+var ErrQuoteExpired error = errors.New(`price quote is expired`)
+var ErrQuoteMismatch error = errors.New(`price quote does not match reservation request`)
+var ErrQuotePriceChanged error = errors.New(`price changed beyond the quoted price lock`)
+
+var ErrInvalidAvailabilityRange error = errors.New(`invalid availability dates range`)
 
 // Result errors
 var ErrEntityNotFound error = errors.New(`entity not found in database`)
@@ -43,17 +76,30 @@ var ErrReservNotFound error = errors.New(`reservation not found`)
 var ErrHotelNotFound error = errors.New(`hotel not found`)
 var ErrPaymentNotFound error = errors.New(`payment not found`)
 var ErrLoyaltyNotFound error = errors.New(`loyalty not found`)
+var ErrLoyaltyCountChanged error = errors.New(`loyalty reservation count was changed`)
+var ErrPromoCodeNotFound error = errors.New(`promo code not found`)
+var ErrHotelHasActiveReservs error = errors.New(`hotel has active reservations`)
+var ErrNoRoomsAvailable error = errors.New(`no rooms available for requested dates`)
+var ErrHoldExpired error = errors.New(`reservation hold is expired`)
+var ErrPaymentDeclined error = errors.New(`payment is declined by provider`)
+var ErrPaymentNotCharged error = errors.New(`payment is not charged`)
 
 // HTTP errors
 var ErrNewRequestForming error = errors.New(`error while creating new request`)
 var ErrRequestSend error = errors.New(`error while sending request to service`)
 var ErrResponseRead error = errors.New(`error while reading service response`)
 var ErrResponseParse error = errors.New(`error while parsing service response`)
+var ErrServiceResponse error = errors.New(`service returned error status`)
+var ErrProviderTimeout error = errors.New(`payment provider did not respond in time`)
 
 // Internal errors
 var ErrJSONParse error = errors.New(`error while writting entity into json`)
 
+var ErrUnauthorized error = errors.New(`unauthorized`)
+
 var ErrLoyaltyServiceUnavailable error = errors.New(`loyalty service unavailable`)
 
+var ErrReconcileIssueChanged error = errors.New(`entity changed since the discrepancy was found`)
+
 // Unknown :P
 var ErrUnknown error = errors.New(`unknown error`)
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/agarmirus/ds-lab02/internal/database"
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+// Rates are probabilities between 0 and 1. Charges above DeclineAmountAbove are
+// always declined, a zero value disables the limit.
+type FakePaymentProviderConfig struct {
+	LatencyMs          int     `json:"latencyMs"`
+	DeclineRate        float64 `json:"declineRate"`
+	DeclineAmountAbove int     `json:"declineAmountAbove"`
+	TimeoutRate        float64 `json:"timeoutRate"`
+	TimeoutMs          int     `json:"timeoutMs"`
+}
+
+type fakeProviderAccount struct {
+	charged  int
+	refunded int
+}
+
+// In-memory provider for local development. Timed out operations are not applied.
+// Accounts lost on restart are restored from the persisted payments.
+type FakePaymentProvider struct {
+	config     FakePaymentProviderConfig
+	paymentDAO database.IPaymentDAO
+
+	mutex    sync.Mutex
+	accounts map[string]*fakeProviderAccount
+}
+
+func NewFakePaymentProvider(config FakePaymentProviderConfig, paymentDAO database.IPaymentDAO) IPaymentProvider {
+	return &FakePaymentProvider{
+		config:     config,
+		paymentDAO: paymentDAO,
+		accounts:   map[string]*fakeProviderAccount{},
+	}
+}
+
+// Must be called with the mutex held.
+func (provider *FakePaymentProvider) findAccount(paymentUid string) (account *fakeProviderAccount, found bool) {
+	account, found = provider.accounts[paymentUid]
+
+	if found {
+		return account, true
+	}
+
+	paymentsLst, err := provider.paymentDAO.GetByAttribute(`payment_uid`, paymentUid)
+
+	if err != nil || paymentsLst.Len() == 0 {
+		return nil, false
+	}
+
+	payment := paymentsLst.Front().Value.(models.Payment)
+
+	switch payment.Status {
+	case models.PaymentStatusCaptured, models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded:
+		account = &fakeProviderAccount{charged: payment.Price, refunded: payment.RefundedAmount}
+		provider.accounts[paymentUid] = account
+
+		return account, true
+	}
+
+	return nil, false
+}
+
+func (provider *FakePaymentProvider) simulateNetwork() error {
+	if provider.config.TimeoutRate > 0 && rand.Float64() < provider.config.TimeoutRate {
+		time.Sleep(time.Duration(provider.config.TimeoutMs) * time.Millisecond)
+		return serverrors.ErrProviderTimeout
+	}
+
+	time.Sleep(time.Duration(provider.config.LatencyMs) * time.Millisecond)
+
+	return nil
+}
+
+func (provider *FakePaymentProvider) Charge(paymentUid string, amount int) error {
+	err := provider.simulateNetwork()
+
+	if err != nil {
+		log.Println("[ERROR] FakePaymentProvider.Charge. Simulated timeout for payment", paymentUid)
+		return err
+	}
+
+	if provider.config.DeclineAmountAbove > 0 && amount > provider.config.DeclineAmountAbove ||
+		provider.config.DeclineRate > 0 && rand.Float64() < provider.config.DeclineRate {
+		log.Println("[ERROR] FakePaymentProvider.Charge. Simulated decline for payment", paymentUid)
+		return serverrors.ErrPaymentDeclined
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	account, found := provider.findAccount(paymentUid)
+
+	if !found {
+		account = &fakeProviderAccount{}
+		provider.accounts[paymentUid] = account
+	}
+
+	account.charged += amount
+
+	return nil
+}
+
+func (provider *FakePaymentProvider) Refund(paymentUid string, amount int) error {
+	err := provider.simulateNetwork()
+
+	if err != nil {
+		log.Println("[ERROR] FakePaymentProvider.Refund. Simulated timeout for payment", paymentUid)
+		return err
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	account, found := provider.findAccount(paymentUid)
+
+	if !found {
+		log.Println("[ERROR] FakePaymentProvider.Refund. Payment was never charged:", paymentUid)
+		return serverrors.ErrPaymentNotFound
+	}
+
+	if account.refunded+amount > account.charged {
+		log.Println("[ERROR] FakePaymentProvider.Refund. Refund exceeds charged amount for payment", paymentUid)
+		return serverrors.ErrInvalidPaymentAmount
+	}
+
+	account.refunded += amount
+
+	return nil
+}
+
+func (provider *FakePaymentProvider) Status(paymentUid string) (string, error) {
+	err := provider.simulateNetwork()
+
+	if err != nil {
+		log.Println("[ERROR] FakePaymentProvider.Status. Simulated timeout for payment", paymentUid)
+		return ``, err
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	account, found := provider.findAccount(paymentUid)
+
+	if !found {
+		return models.ProviderStatusNotCharged, nil
+	}
+
+	if account.refunded == 0 {
+		return models.PaymentStatusCaptured, nil
+	} else if account.refunded < account.charged {
+		return models.PaymentStatusPartiallyRefunded, nil
+	}
+
+	return models.PaymentStatusRefunded, nil
+}
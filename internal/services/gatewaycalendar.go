@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+type calendarFeedClaims struct {
+	Username string `json:"username"`
+	Version  int    `json:"version"`
+}
+
+func (service *GatewayService) performCalendarFeedRequest(
+	method string,
+	username string,
+) (feed models.CalendarFeed, err error) {
+	req, err := http.NewRequest(
+		method,
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/calendar-feeds/%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			url.PathEscape(username),
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performCalendarFeedRequest. Error while creating new request:", err)
+		return feed, serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performCalendarFeedRequest. Error while sending request:", err)
+		return feed, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] GatewayService.performCalendarFeedRequest. Reservation service returned status", res.StatusCode)
+		return feed, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performCalendarFeedRequest. Error while reading response:", err)
+		return feed, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &feed)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performCalendarFeedRequest. Error while parsing JSON response body:", err)
+		return feed, serverrors.ErrResponseParse
+	}
+
+	return feed, nil
+}
+
+func (service *GatewayService) signCalendarFeedToken(feed *models.CalendarFeed) (token string, err error) {
+	claimsJSON, err := json.Marshal(calendarFeedClaims{Username: feed.Username, Version: feed.Version})
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.signCalendarFeedToken. Cannot convert claims into JSON format:", err)
+		return token, err
+	}
+
+	return signToken(service.feedSecret, claimsJSON), nil
+}
+
+// Feed tokens never expire, so calendar apps can keep polling the feed. Each
+// token carries the user's feed version, so a reset revokes only that user's
+// tokens.
+func (service *GatewayService) ReadCalendarFeedToken(username string) (token string, err error) {
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayService.ReadCalendarFeedToken. Invalid username")
+		return token, serverrors.ErrInvalidUsername
+	}
+
+	feed, err := service.performCalendarFeedRequest(`GET`, username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadCalendarFeedToken. performCalendarFeedRequest returned error:", err)
+		return token, err
+	}
+
+	return service.signCalendarFeedToken(&feed)
+}
+
+func (service *GatewayService) ResetCalendarFeedToken(username string) (token string, err error) {
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayService.ResetCalendarFeedToken. Invalid username")
+		return token, serverrors.ErrInvalidUsername
+	}
+
+	feed, err := service.performCalendarFeedRequest(`POST`, username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ResetCalendarFeedToken. performCalendarFeedRequest returned error:", err)
+		return token, err
+	}
+
+	return service.signCalendarFeedToken(&feed)
+}
+
+func (service *GatewayService) readCalendarFeedUsername(token string) (username string, err error) {
+	payload, err := verifyToken(service.feedSecret, token)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.readCalendarFeedUsername. verifyToken returned error:", err)
+		return username, err
+	}
+
+	var claims calendarFeedClaims
+	err = json.Unmarshal(payload, &claims)
+
+	if err != nil || strings.Trim(claims.Username, ` `) == `` {
+		log.Println("[ERROR] GatewayService.readCalendarFeedUsername. Invalid token claims")
+		return username, serverrors.ErrInvalidToken
+	}
+
+	feed, err := service.performCalendarFeedRequest(`GET`, claims.Username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.readCalendarFeedUsername. performCalendarFeedRequest returned error:", err)
+		return username, err
+	}
+
+	if feed.Version != claims.Version {
+		log.Println("[ERROR] GatewayService.readCalendarFeedUsername. Token is revoked")
+		return username, serverrors.ErrInvalidToken
+	}
+
+	return claims.Username, nil
+}
+
+// A feed token identifies the user on its own, so calendar apps which cannot
+// send the X-User-Name header subscribe with the token only.
+func (service *GatewayService) ReadUserCalendar(username string, feedToken string) (calendar string, err error) {
+	if feedToken != `` {
+		username, err = service.readCalendarFeedUsername(feedToken)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.ReadUserCalendar. readCalendarFeedUsername returned error:", err)
+			return calendar, err
+		}
+	}
+
+	reservations, err := service.ReadUserReservations(username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadUserCalendar. ReadUserReservations returned error:", err)
+		return calendar, err
+	}
+
+	return models.ReservsToICalendar(reservations, time.Now()), nil
+}
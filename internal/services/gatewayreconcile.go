@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/agarmirus/ds-lab02/internal/models"
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+// Bookings and cancellations change several services one after another, so
+// records changed within the grace period are not checked yet.
+const reconcileGracePeriod = 5 * time.Minute
+
+// The services are not read at a single point in time, so every record changed
+// during the grace period before the start is skipped, and each entity is read
+// again before it is fixed.
+func (service *GatewayService) Reconcile(
+	authorization string,
+	applyFixes bool,
+) (report models.ReconcileReport, err error) {
+	startedAt := time.Now()
+	report.StartedAt = startedAt.UTC().Format(time.RFC3339)
+	report.ApplyFixes = applyFixes
+
+	payments, err := performListGetRequest[models.Payment](
+		fmt.Sprintf("http://%s:%d/api/v1/payment", service.paymentServiceHost, service.paymentServicePort),
+		authorization,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.Reconcile. Error while reading payments:", err)
+		return report, err
+	}
+
+	reservations, err := performListGetRequest[models.Reservation](
+		fmt.Sprintf("http://%s:%d/api/v1/admin/reservations", service.reservServiceHost, service.reservServicePort),
+		authorization,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.Reconcile. Error while reading reservations:", err)
+		return report, err
+	}
+
+	loyalties, err := performListGetRequest[models.Loyalty](
+		fmt.Sprintf("http://%s:%d/api/v1/loyalties", service.loyaltyServiceHost, service.loyaltyServicePort),
+		authorization,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.Reconcile. Error while reading loyalties:", err)
+		return report, err
+	}
+
+	report.ReservationsChecked = len(reservations)
+	report.PaymentsChecked = len(payments)
+	report.LoyaltiesChecked = len(loyalties)
+	report.Issues = models.FindReconcileIssues(reservations, payments, loyalties, startedAt.Add(-reconcileGracePeriod))
+
+	if !applyFixes {
+		return report, nil
+	}
+
+	for i := range report.Issues {
+		issue := &report.Issues[i]
+
+		if !issue.Fixable {
+			continue
+		}
+
+		err = service.fixReconcileIssue(issue, authorization)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.Reconcile. Cannot fix issue:", issue.Kind, err)
+			issue.FixError = err.Error()
+		} else {
+			issue.Fixed = true
+		}
+	}
+
+	return report, nil
+}
+
+func (service *GatewayService) fixMissingPayment(issue *models.ReconcileIssue) (err error) {
+	reservation, err := service.performReservGetRequest(issue.ReservationUid)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = service.performPaymentByUidGetRequest(reservation.PaymentUid)
+
+	if err == nil {
+		return serverrors.ErrReconcileIssueChanged
+	}
+
+	if !errors.Is(err, serverrors.ErrEntityNotFound) {
+		return err
+	}
+
+	if !models.CanChangeReservStatus(reservation.Status, models.ReservStatusCanceled) {
+		return serverrors.ErrReconcileIssueChanged
+	}
+
+	reservation.Status = models.ReservStatusCanceled
+
+	return service.performReservPutRequest(&reservation, false)
+}
+
+func (service *GatewayService) fixPaymentMismatch(issue *models.ReconcileIssue) (err error) {
+	reservation, err := service.performReservGetRequest(issue.ReservationUid)
+
+	if err != nil {
+		return err
+	}
+
+	payment, err := service.performPaymentByUidGetRequest(issue.PaymentUid)
+
+	if err != nil {
+		return err
+	}
+
+	if reservation.Status != models.ReservStatusCanceled || payment.Status != models.PaymentStatusAuthorized {
+		return serverrors.ErrReconcileIssueChanged
+	}
+
+	_, err = service.performPaymentOperationRequest(payment.Uid, `void`, 0, false)
+
+	return err
+}
+
+func (service *GatewayService) fixOrphanedPayment(issue *models.ReconcileIssue) (err error) {
+	payment, err := service.performPaymentByUidGetRequest(issue.PaymentUid)
+
+	if err != nil {
+		return err
+	}
+
+	if payment.ReservationUid != `` {
+		reservation, err := service.performReservGetRequest(payment.ReservationUid)
+
+		if err == nil && reservation.PaymentUid == payment.Uid {
+			return serverrors.ErrReconcileIssueChanged
+		}
+
+		if err != nil && !errors.Is(err, serverrors.ErrEntityNotFound) {
+			return err
+		}
+	}
+
+	switch payment.Status {
+	case models.PaymentStatusAuthorized:
+		_, err = service.performPaymentOperationRequest(payment.Uid, `void`, 0, false)
+	case models.PaymentStatusCaptured, models.PaymentStatusPartiallyRefunded:
+		_, err = service.performPaymentOperationRequest(payment.Uid, `refund`, 0, false)
+	default:
+		err = serverrors.ErrReconcileIssueChanged
+	}
+
+	return err
+}
+
+// Only the count is corrected: events for the missing reservations would be
+// stamped with the current time and count towards the rolling window.
+func (service *GatewayService) fixLoyaltyMismatch(issue *models.ReconcileIssue, authorization string) (err error) {
+	err = service.performLoyaltyCountPutRequest(
+		issue.Username,
+		&models.LoyaltyCountCorrection{PreviousCount: issue.Actual, ReservationCount: issue.Expected},
+		authorization,
+	)
+
+	if errors.Is(err, serverrors.ErrLoyaltyCountChanged) {
+		return serverrors.ErrReconcileIssueChanged
+	}
+
+	return err
+}
+
+// Every entity is read again, and the fix is skipped when the discrepancy is
+// gone, e.g. because a booking was completed after the check.
+func (service *GatewayService) fixReconcileIssue(issue *models.ReconcileIssue, authorization string) (err error) {
+	switch issue.Kind {
+	case models.ReconcileIssueMissingPayment:
+		err = service.fixMissingPayment(issue)
+	case models.ReconcileIssuePaymentMismatch:
+		err = service.fixPaymentMismatch(issue)
+	case models.ReconcileIssueOrphanedPayment:
+		err = service.fixOrphanedPayment(issue)
+	case models.ReconcileIssueLoyaltyMismatch:
+		err = service.fixLoyaltyMismatch(issue, authorization)
+	}
+
+	return err
+}
+
+func (service *GatewayService) runReconciliation(
+	interval time.Duration,
+	authorization string,
+	applyFixes bool,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		report, err := service.Reconcile(authorization, applyFixes)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.runReconciliation. Reconcile returned error:", err)
+			continue
+		}
+
+		reportJSON, err := json.Marshal(report)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.runReconciliation. Cannot convert report into JSON format:", err)
+			continue
+		}
+
+		log.Println("[INFO] GatewayService.runReconciliation. Report:", string(reportJSON))
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+func performListGetRequest[T any](
+	url string,
+	authorization string,
+) (items []T, err error) {
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		log.Println("[ERROR] performListGetRequest. Error while creating new request:", err)
+		return items, serverrors.ErrNewRequestForming
+	}
+
+	if authorization != `` {
+		req.Header.Set(`Authorization`, authorization)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] performListGetRequest. Error while sending request:", err)
+		return items, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		log.Println("[ERROR] performListGetRequest. Unauthorized:", url)
+		return items, serverrors.ErrUnauthorized
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] performListGetRequest. Service returned status", res.StatusCode, "for", url)
+		return items, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] performListGetRequest. Error while reading response:", err)
+		return items, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &items)
+
+	if err != nil {
+		log.Println("[ERROR] performListGetRequest. Error while parsing JSON response body:", err)
+		return items, serverrors.ErrResponseParse
+	}
+
+	return items, nil
+}
+
+// A 400 response is reported as invalidErr, so callers can map it like their own validation errors.
+func performAdminListRequest[T any](
+	method string,
+	url string,
+	authorization string,
+	items []T,
+	invalidErr error,
+) (resItems []T, err error) {
+	itemsJSON, err := json.Marshal(items)
+
+	if err != nil {
+		log.Println("[ERROR] performAdminListRequest. Cannot create JSON object for request body:", err)
+		return resItems, serverrors.ErrJSONParse
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(itemsJSON))
+
+	if err != nil {
+		log.Println("[ERROR] performAdminListRequest. Error while creating new request:", err)
+		return resItems, serverrors.ErrNewRequestForming
+	}
+
+	req.Header.Set(`Authorization`, authorization)
+	req.Header.Set(`Content-Type`, `application/json`)
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] performAdminListRequest. Error while sending request:", err)
+		return resItems, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		log.Println("[ERROR] performAdminListRequest. Unauthorized:", url)
+		return resItems, serverrors.ErrUnauthorized
+	case http.StatusBadRequest:
+		log.Println("[ERROR] performAdminListRequest. Service rejected request body for", url)
+		return resItems, invalidErr
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] performAdminListRequest. Service returned status", res.StatusCode, "for", url)
+		return resItems, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] performAdminListRequest. Error while reading response:", err)
+		return resItems, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &resItems)
+
+	if err != nil {
+		log.Println("[ERROR] performAdminListRequest. Error while parsing JSON response body:", err)
+		return resItems, serverrors.ErrResponseParse
+	}
+
+	return resItems, nil
+}
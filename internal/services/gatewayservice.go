@@ -9,8 +9,8 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -37,8 +37,15 @@ type GatewayService struct {
 	reservsResCb     *gobreaker.CircuitBreaker[[]models.ReservationResponse]
 	userInfoResCb    *gobreaker.CircuitBreaker[models.UserInfoResponse]
 	pagResCb         *gobreaker.CircuitBreaker[models.PagiationResponse]
+	availabilityCb   *gobreaker.CircuitBreaker[models.HotelAvailabilityResponse]
 
 	reQueue chan *http.Request
+
+	quoteSecret   string
+	quoteTtl      time.Duration
+	quoteMaxDrift int
+
+	feedSecret string
 }
 
 func initCb[T any](name string, cbMaxFailsCount int) *gobreaker.CircuitBreaker[T] {
@@ -59,6 +66,17 @@ func (service *GatewayService) resetRequests() {
 	for {
 		req := <-service.reQueue
 
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.resetRequests. Cannot rewind request body, dropping request:", req.Method, req.URL, err)
+				continue
+			}
+
+			req.Body = body
+		}
+
 		_, err := http.DefaultClient.Do(req)
 
 		if err != nil {
@@ -76,6 +94,13 @@ func NewGatewayService(
 	loyaltyServicePort int,
 	cbMaxFailsCount int,
 	maxResetQueueSize int,
+	quoteSecret string,
+	quoteTtl time.Duration,
+	quoteMaxDrift int,
+	feedSecret string,
+	reconcileInterval time.Duration,
+	reconcileAuthorization string,
+	reconcileApplyFixes bool,
 ) IGatewayService {
 	service := &GatewayService{
 		reservServiceHost,
@@ -89,66 +114,104 @@ func NewGatewayService(
 		initCb[[]models.ReservationResponse](`reservsResCb`, cbMaxFailsCount),
 		initCb[models.UserInfoResponse](`userInfoResCb`, cbMaxFailsCount),
 		initCb[models.PagiationResponse](`pagResCb`, cbMaxFailsCount),
+		initCb[models.HotelAvailabilityResponse](`availabilityCb`, cbMaxFailsCount),
 		make(chan *http.Request, maxResetQueueSize),
+		quoteSecret,
+		quoteTtl,
+		quoteMaxDrift,
+		feedSecret,
 	}
 
 	go service.resetRequests()
 
+	if reconcileInterval > 0 {
+		go service.runReconciliation(reconcileInterval, reconcileAuthorization, reconcileApplyFixes)
+	}
+
 	return service
 }
 
+// The reservation service explains a rejected filter in its response body; a
+// report about the cursor alone is an invalid cursor.
+func readHotelsValidationError(resBody []byte, validErrRes *models.ValidationErrorResponse) error {
+	err := json.Unmarshal(resBody, validErrRes)
+
+	if err != nil || len(validErrRes.Errors) == 0 {
+		log.Println("[ERROR] GatewayService.readHotelsValidationError. Response body has no validation errors:", err)
+		validErrRes.Message = serverrors.ErrInvalidHotelFilter.Error()
+		return serverrors.ErrInvalidHotelFilter
+	}
+
+	for _, errDiscription := range validErrRes.Errors {
+		if errDiscription.Field != `cursor` {
+			return serverrors.ErrInvalidHotelFilter
+		}
+	}
+
+	return serverrors.ErrInvalidCursor
+}
+
 func (service *GatewayService) performAllHotelsGetRequest(
+	filter *models.HotelFilter,
+	cursor string,
 	page int,
 	pageSize int,
-) (hotelsSlice []models.Hotel, err error) {
-	hotelsSlice = make([]models.Hotel, 0)
+) (hotelsPage models.HotelsPage, validErrRes models.ValidationErrorResponse, err error) {
+	queryValues := url.Values{}
+	queryValues.Set(`size`, strconv.Itoa(pageSize))
+	models.HotelFilterToValues(queryValues, filter)
+
+	if page > 0 {
+		queryValues.Set(`page`, strconv.Itoa(page))
+	} else {
+		queryValues.Set(`cursor`, cursor)
+	}
 
 	req, err := http.NewRequest(
 		"GET",
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/hotels?page=%d&size=%d",
+			"http://%s:%d/api/v1/hotels?%s",
 			service.reservServiceHost,
 			service.reservServicePort,
-			page,
-			pageSize,
+			queryValues.Encode(),
 		),
 		nil,
 	)
 
 	if err != nil {
 		log.Println("[ERROR] GatewayService.performAllHotelsGetRequest. Error while creating new HTTP-request: ", err)
-		return hotelsSlice, serverrors.ErrNewRequestForming
+		return hotelsPage, validErrRes, serverrors.ErrNewRequestForming
 	}
 
 	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
 		log.Println("[ERROR] GatewayService.performAllHotelsGetRequest. Error while sending request:", err)
-		return hotelsSlice, serverrors.ErrRequestSend
+		return hotelsPage, validErrRes, serverrors.ErrRequestSend
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusNotFound {
-		log.Println("[INFO] GatewayService.performAllHotelsGetRequest. Empty hotels result")
-		return make([]models.Hotel, 0), nil
-	}
-
 	resBody, err := io.ReadAll(res.Body)
 
 	if err != nil {
 		log.Println("[ERROR] GatewayService.performAllHotelsGetRequest. Error while reading response:", err)
-		return hotelsSlice, serverrors.ErrResponseRead
+		return hotelsPage, validErrRes, serverrors.ErrResponseRead
+	}
+
+	if res.StatusCode == http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performAllHotelsGetRequest. Invalid hotels filter")
+		return hotelsPage, validErrRes, readHotelsValidationError(resBody, &validErrRes)
 	}
 
-	err = json.Unmarshal(resBody, &hotelsSlice)
+	err = json.Unmarshal(resBody, &hotelsPage)
 
 	if err != nil {
 		log.Println("[ERROR] GatewayService.performAllHotelsGetRequest. Error while parsing JSON response body:", err)
-		return hotelsSlice, serverrors.ErrResponseParse
+		return hotelsPage, validErrRes, serverrors.ErrResponseParse
 	}
 
-	return hotelsSlice, nil
+	return hotelsPage, validErrRes, nil
 }
 
 func (service *GatewayService) performUserReservsGetRequest(
@@ -393,385 +456,1556 @@ func (service *GatewayService) performHotelByUidGetRequest(
 	return hotel, nil
 }
 
-func (service *GatewayService) performPaymentPostRequest(
-	price int,
-) (payment models.Payment, err error) {
+func (service *GatewayService) performHotelAvailabilityGetRequest(
+	hotelUid string,
+	from string,
+	to string,
+) (availabilityRes models.HotelAvailabilityResponse, err error) {
+	queryValues := url.Values{}
+	queryValues.Set(`from`, from)
+	queryValues.Set(`to`, to)
+
 	req, err := http.NewRequest(
-		"POST",
+		"GET",
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/payment",
-			service.paymentServiceHost,
-			service.paymentServicePort,
+			"http://%s:%d/api/v1/hotels/%s/availability?%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			hotelUid,
+			queryValues.Encode(),
 		),
 		nil,
 	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while creating new request:", err)
-		return payment, serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performHotelAvailabilityGetRequest. Error while creating new request:", err)
+		return availabilityRes, serverrors.ErrNewRequestForming
 	}
 
-	req.Header.Add(`Price`, strconv.Itoa(price))
 	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while sending request:", err)
-		service.reQueue <- req
-		return payment, serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.performHotelAvailabilityGetRequest. Error while sending request:", err)
+		return availabilityRes, serverrors.ErrRequestSend
 	}
 
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performHotelAvailabilityGetRequest. Hotel not found")
+		return availabilityRes, serverrors.ErrEntityNotFound
+	}
+
+	if res.StatusCode == http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performHotelAvailabilityGetRequest. Invalid availability range")
+		return availabilityRes, serverrors.ErrInvalidAvailabilityRange
+	}
+
 	resBody, err := io.ReadAll(res.Body)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while reading response:", err)
-		return payment, serverrors.ErrResponseRead
+		log.Println("[ERROR] GatewayService.performHotelAvailabilityGetRequest. Error while reading response:", err)
+		return availabilityRes, serverrors.ErrResponseRead
 	}
 
-	err = json.Unmarshal(resBody, &payment)
+	err = json.Unmarshal(resBody, &availabilityRes)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while parsing JSON response body:", err)
-		return payment, serverrors.ErrResponseParse
+		log.Println("[ERROR] GatewayService.performHotelAvailabilityGetRequest. Error while parsing JSON response body:", err)
+		return availabilityRes, serverrors.ErrResponseParse
 	}
 
-	return payment, nil
+	return availabilityRes, nil
 }
 
-func (service *GatewayService) performLoyaltyPutRequest(
-	loyalty *models.Loyalty,
-) (err error) {
-	loyaltyJSON, err := json.Marshal(loyalty)
+func (service *GatewayService) performHotelTaxRulesGetRequest(hotelUid string) (rules []models.TaxRule, err error) {
+	rules, err = performListGetRequest[models.TaxRule](
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/hotels/%s/tax-rules",
+			service.reservServiceHost,
+			service.reservServicePort,
+			hotelUid,
+		),
+		``,
+	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyPutRequest. Cannot create JSON object for request body:", err)
-		return serverrors.ErrJSONParse
+		log.Println("[ERROR] GatewayService.performHotelTaxRulesGetRequest. performListGetRequest returned error:", err)
 	}
 
+	return rules, err
+}
+
+func (service *GatewayService) performHotelPricesGetRequest(
+	hotelUid string,
+	from string,
+	to string,
+) (priceRes models.NightlyPriceResponse, err error) {
+	queryValues := url.Values{}
+	queryValues.Set(`from`, from)
+	queryValues.Set(`to`, to)
+
 	req, err := http.NewRequest(
-		"PUT",
+		"GET",
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/loyalty/%d",
-			service.loyaltyServiceHost,
-			service.loyaltyServicePort,
-			loyalty.Id,
+			"http://%s:%d/api/v1/hotels/%s/prices?%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			hotelUid,
+			queryValues.Encode(),
 		),
-		bytes.NewBuffer(loyaltyJSON),
+		nil,
 	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyPutRequest. Error while creating new request:", err)
-		return serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Error while creating new request:", err)
+		return priceRes, serverrors.ErrNewRequestForming
 	}
 
-	_, err = http.DefaultClient.Do(req)
+	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyPutRequest. Error while sending request:", err)
-		service.reQueue <- req
-		return serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Error while sending request:", err)
+		return priceRes, serverrors.ErrRequestSend
 	}
 
-	return nil
-}
+	defer res.Body.Close()
 
-func (service *GatewayService) performLoyaltyDecreasePatchRequest(
-	username string,
-) error {
-	req, err := http.NewRequest(
-		"PATCH",
-		fmt.Sprintf(
-			"http://%s:%d/api/v1/loyalty",
-			service.loyaltyServiceHost,
-			service.loyaltyServicePort,
-		),
-		nil,
-	)
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Hotel not found")
+		return priceRes, serverrors.ErrEntityNotFound
+	case http.StatusBadRequest:
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Invalid dates range")
+		return priceRes, serverrors.ErrInvalidReservDates
+	case http.StatusUnprocessableEntity:
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Minimum stay is not met")
+		return priceRes, serverrors.ErrMinStayNotMet
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Reservation service returned status", res.StatusCode)
+		return priceRes, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while creating new request:", err)
-		return serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Error while reading response:", err)
+		return priceRes, serverrors.ErrResponseRead
 	}
 
-	req.Header.Add(`X-User-Name`, username)
-	req.Header.Add(`Delta`, strconv.Itoa(-1))
-	_, err = http.DefaultClient.Do(req)
+	err = json.Unmarshal(resBody, &priceRes)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while sending request:", err)
-		service.reQueue <- req
-		return serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.performHotelPricesGetRequest. Error while parsing JSON response body:", err)
+		return priceRes, serverrors.ErrResponseParse
 	}
 
-	return nil
+	return priceRes, nil
 }
 
-func (service *GatewayService) performReservationPostRequest(
-	username string,
-	paymentUid string,
-	hotelId int,
-	status string,
-	startDate string,
-	endDate string,
-) (reservation models.Reservation, err error) {
-	var newReservation models.Reservation
-	newReservation.Username = username
-	newReservation.PaymentUid = paymentUid
-	newReservation.HotelId = hotelId
-	newReservation.Status = status
-	newReservation.StartDate = startDate
-	newReservation.EndDate = endDate
-	newReservation.Uid = uuid.New().String()
+func (service *GatewayService) performAdminHotelRequest(
+	method string,
+	hotelUid string,
+	authorization string,
+	hotel *models.Hotel,
+) (resHotel models.Hotel, err error) {
+	var reqBody io.Reader
 
-	newReservJSON, err := json.Marshal(newReservation)
+	if hotel != nil {
+		hotelJSON, err := json.Marshal(hotel)
 
-	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservationPostRequest. Cannot create JSON object for request body:", err)
-		return reservation, serverrors.ErrJSONParse
+		if err != nil {
+			log.Println("[ERROR] GatewayService.performAdminHotelRequest. Cannot create JSON object for request body:", err)
+			return resHotel, serverrors.ErrJSONParse
+		}
+
+		reqBody = bytes.NewBuffer(hotelJSON)
 	}
 
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf(
-			"http://%s:%d/api/v1/reservations",
-			service.reservServiceHost,
-			service.reservServicePort,
-		),
-		bytes.NewBuffer(newReservJSON),
+	reqUrl := fmt.Sprintf(
+		"http://%s:%d/api/v1/admin/hotels",
+		service.reservServiceHost,
+		service.reservServicePort,
 	)
 
+	if hotelUid != `` {
+		reqUrl += `/` + hotelUid
+	}
+
+	req, err := http.NewRequest(method, reqUrl, reqBody)
+
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while creating new request:", err)
-		return reservation, serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Error while creating new request:", err)
+		return resHotel, serverrors.ErrNewRequestForming
 	}
 
+	req.Header.Set(`Authorization`, authorization)
+
 	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while sending request:", err)
-		service.reQueue <- req
-		return reservation, serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Error while sending request:", err)
+		return resHotel, serverrors.ErrRequestSend
 	}
 
 	defer res.Body.Close()
 
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Unauthorized")
+		return resHotel, serverrors.ErrUnauthorized
+	case http.StatusBadRequest:
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Invalid hotel data")
+		return resHotel, serverrors.ErrInvalidHotel
+	case http.StatusNotFound:
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Hotel not found")
+		return resHotel, serverrors.ErrEntityNotFound
+	case http.StatusConflict:
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Hotel has active reservations")
+		return resHotel, serverrors.ErrHotelHasActiveReservs
+	case http.StatusNoContent:
+		return resHotel, nil
+	}
+
 	resBody, err := io.ReadAll(res.Body)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while reading response:", err)
-		return reservation, serverrors.ErrResponseRead
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Error while reading response:", err)
+		return resHotel, serverrors.ErrResponseRead
 	}
 
-	err = json.Unmarshal(resBody, &reservation)
+	err = json.Unmarshal(resBody, &resHotel)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while parsing JSON response body:", err)
-		return reservation, serverrors.ErrResponseParse
+		log.Println("[ERROR] GatewayService.performAdminHotelRequest. Error while parsing JSON response body:", err)
+		return resHotel, serverrors.ErrResponseParse
 	}
 
-	return reservation, nil
+	return resHotel, nil
 }
 
-func (service *GatewayService) performReservGetRequest(
-	reservUid string,
-) (reserv models.Reservation, err error) {
+func (service *GatewayService) performAdminCancelPolicyRequest(
+	method string,
+	hotelUid string,
+	authorization string,
+	policy *models.CancellationPolicy,
+) (resPolicy models.CancellationPolicy, err error) {
+	var reqBody io.Reader
+
+	if policy != nil {
+		policyJSON, err := json.Marshal(policy)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Cannot create JSON object for request body:", err)
+			return resPolicy, serverrors.ErrJSONParse
+		}
+
+		reqBody = bytes.NewBuffer(policyJSON)
+	}
+
 	req, err := http.NewRequest(
-		"GET",
+		method,
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/reservations/%s",
+			"http://%s:%d/api/v1/admin/hotels/%s/cancellation-policy",
 			service.reservServiceHost,
 			service.reservServicePort,
-			reservUid,
+			hotelUid,
 		),
-		nil,
+		reqBody,
 	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while creating new request:", err)
-		return reserv, serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Error while creating new request:", err)
+		return resPolicy, serverrors.ErrNewRequestForming
 	}
 
+	req.Header.Set(`Authorization`, authorization)
+
 	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while sending request:", err)
-		return reserv, serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Error while sending request:", err)
+		return resPolicy, serverrors.ErrRequestSend
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusNotFound {
-		log.Println("[ERROR] GatewayService.performReservGetRequest. Reservation not found")
-		return reserv, serverrors.ErrEntityNotFound
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Unauthorized")
+		return resPolicy, serverrors.ErrUnauthorized
+	case http.StatusBadRequest:
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Invalid cancellation policy")
+		return resPolicy, serverrors.ErrInvalidCancelPolicy
+	case http.StatusNotFound:
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Hotel not found")
+		return resPolicy, serverrors.ErrEntityNotFound
+	case http.StatusNoContent:
+		return resPolicy, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Reservation service returned status", res.StatusCode)
+		return resPolicy, serverrors.ErrServiceResponse
 	}
 
 	resBody, err := io.ReadAll(res.Body)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while reading response:", err)
-		return reserv, serverrors.ErrResponseRead
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Error while reading response:", err)
+		return resPolicy, serverrors.ErrResponseRead
 	}
 
-	err = json.Unmarshal(resBody, &reserv)
+	err = json.Unmarshal(resBody, &resPolicy)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while parsing JSON response body:", err)
-		return reserv, serverrors.ErrResponseParse
+		log.Println("[ERROR] GatewayService.performAdminCancelPolicyRequest. Error while parsing JSON response body:", err)
+		return resPolicy, serverrors.ErrResponseParse
 	}
 
-	return reserv, nil
+	return resPolicy, nil
 }
 
-func (service *GatewayService) performLoyaltyByUsernameGetRequest(
-	username string,
-) (loyalty models.Loyalty, err error) {
+func (service *GatewayService) performAdminRateRulesRequest(
+	method string,
+	hotelUid string,
+	authorization string,
+	rules []models.RateRule,
+) (resRules []models.RateRule, err error) {
+	var reqBody io.Reader
+
+	if rules != nil {
+		rulesJSON, err := json.Marshal(rules)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Cannot create JSON object for request body:", err)
+			return resRules, serverrors.ErrJSONParse
+		}
+
+		reqBody = bytes.NewBuffer(rulesJSON)
+	}
+
 	req, err := http.NewRequest(
-		"GET",
+		method,
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/loyalty",
-			service.loyaltyServiceHost,
-			service.loyaltyServicePort,
+			"http://%s:%d/api/v1/admin/hotels/%s/rate-rules",
+			service.reservServiceHost,
+			service.reservServicePort,
+			hotelUid,
 		),
-		nil,
+		reqBody,
 	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while creating new request:", err)
-		return loyalty, serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Error while creating new request:", err)
+		return resRules, serverrors.ErrNewRequestForming
 	}
 
-	req.Header.Add(`X-User-Name`, username)
+	req.Header.Set(`Authorization`, authorization)
+
 	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while sending request:", err)
-		return loyalty, serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Error while sending request:", err)
+		return resRules, serverrors.ErrRequestSend
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusNotFound {
-		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Loyalty not found")
-		return loyalty, serverrors.ErrEntityNotFound
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Unauthorized")
+		return resRules, serverrors.ErrUnauthorized
+	case http.StatusBadRequest:
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Invalid rate rules")
+		return resRules, serverrors.ErrInvalidRateRule
+	case http.StatusNotFound:
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Hotel not found")
+		return resRules, serverrors.ErrEntityNotFound
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Reservation service returned status", res.StatusCode)
+		return resRules, serverrors.ErrServiceResponse
 	}
 
 	resBody, err := io.ReadAll(res.Body)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while reading response:", err)
-		return loyalty, serverrors.ErrResponseRead
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Error while reading response:", err)
+		return resRules, serverrors.ErrResponseRead
 	}
 
-	err = json.Unmarshal(resBody, &loyalty)
+	err = json.Unmarshal(resBody, &resRules)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while parsing JSON response body:", err)
-		return loyalty, serverrors.ErrResponseParse
+		log.Println("[ERROR] GatewayService.performAdminRateRulesRequest. Error while parsing JSON response body:", err)
+		return resRules, serverrors.ErrResponseParse
 	}
 
-	return loyalty, nil
+	return resRules, nil
 }
 
-func (service *GatewayService) performReservPutRequest(
-	reservation *models.Reservation,
-) (err error) {
-	reservJSON, err := json.Marshal(reservation)
+// A failed request is not retried in the background, as the booking it was sent
+// for is abandoned and a late authorization would hold the amount for nothing.
+func (service *GatewayService) performPaymentPostRequest(
+	crPaymentReq *models.CreatePaymentRequest,
+) (payment models.Payment, err error) {
+	crPaymentReqJSON, err := json.Marshal(crPaymentReq)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservPutRequest. Cannot create JSON object for request body:", err)
-		return serverrors.ErrJSONParse
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Cannot create JSON object for request body:", err)
+		return payment, serverrors.ErrJSONParse
 	}
 
 	req, err := http.NewRequest(
-		"PUT",
+		"POST",
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/reservations/%s",
-			service.reservServiceHost,
-			service.reservServicePort,
-			reservation.Uid,
+			"http://%s:%d/api/v1/payment",
+			service.paymentServiceHost,
+			service.paymentServicePort,
 		),
-		bytes.NewBuffer(reservJSON),
+		bytes.NewBuffer(crPaymentReqJSON),
 	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservPutRequest. Error while creating new request:", err)
-		return serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while creating new request:", err)
+		return payment, serverrors.ErrNewRequestForming
 	}
 
-	_, err = http.DefaultClient.Do(req)
+	req.Header.Set(`Content-Type`, `application/json`)
+	res, err := http.DefaultClient.Do(req)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performReservPutRequest. Error while sending request:", err)
-		service.reQueue <- req
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while sending request:", err)
+		return payment, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusBadRequest:
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Invalid payment request")
+		return payment, serverrors.ErrInvalidCrPaymentReq
+	case http.StatusConflict:
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Idempotency key was reused")
+		return payment, serverrors.ErrIdempotencyKeyReused
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Payment service returned status", res.StatusCode)
+		return payment, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while reading response:", err)
+		return payment, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &payment)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentPostRequest. Error while parsing JSON response body:", err)
+		return payment, serverrors.ErrResponseParse
+	}
+
+	return payment, nil
+}
+
+func (service *GatewayService) performLoyaltyPutRequest(
+	loyalty *models.Loyalty,
+) (err error) {
+	loyaltyJSON, err := json.Marshal(loyalty)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyPutRequest. Cannot create JSON object for request body:", err)
+		return serverrors.ErrJSONParse
+	}
+
+	req, err := http.NewRequest(
+		"PUT",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/loyalty/%d",
+			service.loyaltyServiceHost,
+			service.loyaltyServicePort,
+			loyalty.Id,
+		),
+		bytes.NewBuffer(loyaltyJSON),
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyPutRequest. Error while creating new request:", err)
+		return serverrors.ErrNewRequestForming
+	}
+
+	_, err = http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyPutRequest. Error while sending request:", err)
+		service.reQueue <- req
+		return serverrors.ErrRequestSend
+	}
+
+	return nil
+}
+
+func (service *GatewayService) performLoyaltyCountPatchRequest(
+	username string,
+	reservationUid string,
+	delta int,
+) error {
+	req, err := http.NewRequest(
+		"PATCH",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/loyalty",
+			service.loyaltyServiceHost,
+			service.loyaltyServicePort,
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPatchRequest. Error while creating new request:", err)
+		return serverrors.ErrNewRequestForming
+	}
+
+	req.Header.Add(`X-User-Name`, username)
+	req.Header.Add(`Reservation-Uid`, reservationUid)
+	req.Header.Add(`Delta`, strconv.Itoa(delta))
+	_, err = http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPatchRequest. Error while sending request:", err)
+		service.reQueue <- req
+		return serverrors.ErrRequestSend
+	}
+
+	return nil
+}
+
+func (service *GatewayService) performLoyaltyCountPutRequest(
+	username string,
+	correction *models.LoyaltyCountCorrection,
+	authorization string,
+) error {
+	correctionJSON, err := json.Marshal(correction)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Cannot create JSON object for request body:", err)
+		return serverrors.ErrJSONParse
+	}
+
+	req, err := http.NewRequest(
+		"PUT",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/loyalties/%s/reservation-count",
+			service.loyaltyServiceHost,
+			service.loyaltyServicePort,
+			url.PathEscape(username),
+		),
+		bytes.NewBuffer(correctionJSON),
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Error while creating new request:", err)
+		return serverrors.ErrNewRequestForming
+	}
+
+	req.Header.Set(`Authorization`, authorization)
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Error while sending request:", err)
+		return serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Unauthorized")
+		return serverrors.ErrUnauthorized
+	case http.StatusNotFound:
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Loyalty not found")
+		return serverrors.ErrEntityNotFound
+	case http.StatusConflict:
+		log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Reservation count was changed")
+		return serverrors.ErrLoyaltyCountChanged
+	}
+
+	log.Println("[ERROR] GatewayService.performLoyaltyCountPutRequest. Service returned status", res.StatusCode)
+	return serverrors.ErrServiceResponse
+}
+
+func (service *GatewayService) performReservationPostRequest(
+	reservationUid string,
+	username string,
+	paymentUid string,
+	hotelId int,
+	status string,
+	startDate string,
+	endDate string,
+	roomTypeUid string,
+	promoCode string,
+	quote *models.PriceQuoteResponse,
+) (reservation models.Reservation, err error) {
+	var newReservation models.Reservation
+	models.QuoteToReservPricing(&newReservation, quote)
+	newReservation.PromoCode = promoCode
+	newReservation.Username = username
+	newReservation.PaymentUid = paymentUid
+	newReservation.HotelId = hotelId
+	newReservation.Status = status
+	newReservation.StartDate = startDate
+	newReservation.EndDate = endDate
+	newReservation.RoomTypeUid = roomTypeUid
+	newReservation.Uid = reservationUid
+
+	newReservJSON, err := json.Marshal(newReservation)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. Cannot create JSON object for request body:", err)
+		return reservation, serverrors.ErrJSONParse
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/reservations",
+			service.reservServiceHost,
+			service.reservServicePort,
+		),
+		bytes.NewBuffer(newReservJSON),
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while creating new request:", err)
+		return reservation, serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while sending request:", err)
+		service.reQueue <- req
+		return reservation, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusConflict {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. No rooms available")
+		return reservation, serverrors.ErrNoRoomsAvailable
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. Hotel not found or retired")
+		return reservation, serverrors.ErrHotelNotFound
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while reading response:", err)
+		return reservation, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &reservation)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservationPostRequest. Error while parsing JSON response body:", err)
+		return reservation, serverrors.ErrResponseParse
+	}
+
+	return reservation, nil
+}
+
+func (service *GatewayService) performReservGetRequest(
+	reservUid string,
+) (reserv models.Reservation, err error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/reservations/%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			reservUid,
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while creating new request:", err)
+		return reserv, serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while sending request:", err)
+		return reserv, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performReservGetRequest. Reservation not found")
+		return reserv, serverrors.ErrEntityNotFound
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while reading response:", err)
+		return reserv, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &reserv)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservGetRequest. Error while parsing JSON response body:", err)
+		return reserv, serverrors.ErrResponseParse
+	}
+
+	return reserv, nil
+}
+
+func (service *GatewayService) performLoyaltyByUsernameGetRequest(
+	username string,
+) (loyalty models.Loyalty, err error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/loyalty",
+			service.loyaltyServiceHost,
+			service.loyaltyServicePort,
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while creating new request:", err)
+		return loyalty, serverrors.ErrNewRequestForming
+	}
+
+	req.Header.Add(`X-User-Name`, username)
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while sending request:", err)
+		return loyalty, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Loyalty not found")
+		return loyalty, serverrors.ErrEntityNotFound
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while reading response:", err)
+		return loyalty, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &loyalty)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performLoyaltyByUsernameGetRequest. Error while parsing JSON response body:", err)
+		return loyalty, serverrors.ErrResponseParse
+	}
+
+	return loyalty, nil
+}
+
+func (service *GatewayService) performReservPutRequest(
+	reservation *models.Reservation,
+	queueOnFail bool,
+) (err error) {
+	reservJSON, err := json.Marshal(reservation)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservPutRequest. Cannot create JSON object for request body:", err)
+		return serverrors.ErrJSONParse
+	}
+
+	req, err := http.NewRequest(
+		"PUT",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/reservations/%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			reservation.Uid,
+		),
+		bytes.NewBuffer(reservJSON),
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservPutRequest. Error while creating new request:", err)
+		return serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performReservPutRequest. Error while sending request:", err)
+
+		if queueOnFail {
+			service.reQueue <- req
+		}
+
+		return serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performReservPutRequest. Reservation not found")
+		return serverrors.ErrEntityNotFound
+	}
+
+	if res.StatusCode == http.StatusConflict {
+		log.Println("[ERROR] GatewayService.performReservPutRequest. Reservation update conflict")
+		return service.readConflictError(res, serverrors.ErrNoRoomsAvailable)
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performReservPutRequest. Reservation service returned status", res.StatusCode)
+		return serverrors.ErrServiceResponse
+	}
+
+	return nil
+}
+
+// Operation is one of capture, void and refund. A zero amount lets the payment
+// service pick the default: the authorized price or the remaining balance.
+func (service *GatewayService) performPaymentOperationRequest(
+	paymentUid string,
+	operation string,
+	amount int,
+	queueOnFail bool,
+) (payment models.Payment, err error) {
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/payment/%s/%s",
+			service.paymentServiceHost,
+			service.paymentServicePort,
+			paymentUid,
+			operation,
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Error while creating new request:", err)
+		return payment, serverrors.ErrNewRequestForming
+	}
+
+	if amount > 0 {
+		req.Header.Add(`Amount`, strconv.Itoa(amount))
+	}
+
+	// The request may reach the payment service and still fail here, so the
+	// queued retry carries the same key and is not applied twice.
+	req.Header.Add(`Idempotency-Key`, uuid.New().String())
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Error while sending request:", err)
+
+		if queueOnFail {
+			service.reQueue <- req
+		}
+
+		return payment, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Payment not found")
+		return payment, serverrors.ErrEntityNotFound
+	}
+
+	if res.StatusCode == http.StatusConflict {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Payment operation is not allowed:", operation)
+		return payment, serverrors.ErrInvalidStatusTransition
+	}
+
+	if res.StatusCode == http.StatusPaymentRequired {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Payment is declined by provider")
+		return payment, serverrors.ErrPaymentDeclined
+	}
+
+	if res.StatusCode == http.StatusGatewayTimeout {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Payment provider timed out")
+		return payment, serverrors.ErrProviderTimeout
+	}
+
+	if res.StatusCode == http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Invalid payment amount:", amount)
+		return payment, serverrors.ErrInvalidPaymentAmount
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Payment service returned status", res.StatusCode)
+		return payment, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Error while reading response:", err)
+		return payment, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &payment)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentOperationRequest. Error while parsing JSON response body:", err)
+		return payment, serverrors.ErrResponseParse
+	}
+
+	return payment, nil
+}
+
+func (service *GatewayService) performPaymentInvoicePutRequest(
+	paymentUid string,
+) (invoice models.Invoice, err error) {
+	req, err := http.NewRequest(
+		"PUT",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/payment/%s/invoice",
+			service.paymentServiceHost,
+			service.paymentServicePort,
+			paymentUid,
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentInvoicePutRequest. Error while creating new request:", err)
+		return invoice, serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentInvoicePutRequest. Error while sending request:", err)
+		return invoice, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performPaymentInvoicePutRequest. Payment not found")
+		return invoice, serverrors.ErrEntityNotFound
+	}
+
+	if res.StatusCode != http.StatusOK {
+		log.Println("[ERROR] GatewayService.performPaymentInvoicePutRequest. Payment service returned status", res.StatusCode)
+		return invoice, serverrors.ErrServiceResponse
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentInvoicePutRequest. Error while reading response:", err)
+		return invoice, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &invoice)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentInvoicePutRequest. Error while parsing JSON response body:", err)
+		return invoice, serverrors.ErrResponseParse
+	}
+
+	return invoice, nil
+}
+
+func (service *GatewayService) performPaymentTransactionsGetRequest(
+	paymentUid string,
+) (transactions []models.PaymentTransaction, err error) {
+	transactions, err = performListGetRequest[models.PaymentTransaction](
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/payment/%s/transactions",
+			service.paymentServiceHost,
+			service.paymentServicePort,
+			paymentUid,
+		),
+		``,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPaymentTransactionsGetRequest. performListGetRequest returned error:", err)
+	}
+
+	return transactions, err
+}
+
+func (service *GatewayService) readConflictError(res *http.Response, defaultErr error) error {
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.readConflictError. Error while reading response:", err)
+		return defaultErr
+	}
+
+	var errRes models.ErrorResponse
+	err = json.Unmarshal(resBody, &errRes)
+
+	if err == nil && errRes.Message == serverrors.ErrInvalidStatusTransition.Error() {
+		return serverrors.ErrInvalidStatusTransition
+	}
+
+	if err == nil && errRes.Message == serverrors.ErrHoldExpired.Error() {
+		return serverrors.ErrHoldExpired
+	}
+
+	return defaultErr
+}
+
+func (service *GatewayService) readPromoCodeError(res *http.Response) error {
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.readPromoCodeError. Error while reading response:", err)
+		return serverrors.ErrResponseRead
+	}
+
+	var errRes models.ErrorResponse
+	err = json.Unmarshal(resBody, &errRes)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.readPromoCodeError. Error while parsing JSON response body:", err)
+		return serverrors.ErrResponseParse
+	}
+
+	return fmt.Errorf("%w: %s", serverrors.ErrPromoCodeNotApplicable, errRes.Message)
+}
+
+func (service *GatewayService) performPromoCodeGetRequest(
+	code string,
+	username string,
+	hotelUid string,
+	reservationUid string,
+) (promoCode models.PromoCode, err error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/promocodes/%s?hotelUid=%s&reservationUid=%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			url.PathEscape(code),
+			url.QueryEscape(hotelUid),
+			url.QueryEscape(reservationUid),
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeGetRequest. Error while creating new request:", err)
+		return promoCode, serverrors.ErrNewRequestForming
+	}
+
+	req.Header.Add(`X-User-Name`, username)
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeGetRequest. Error while sending request:", err)
+		return promoCode, serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performPromoCodeGetRequest. Promo code not found")
+		return promoCode, serverrors.ErrPromoCodeNotFound
+	}
+
+	if res.StatusCode == http.StatusConflict {
+		log.Println("[ERROR] GatewayService.performPromoCodeGetRequest. Promo code is not applicable")
+		return promoCode, service.readPromoCodeError(res)
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeGetRequest. Error while reading response:", err)
+		return promoCode, serverrors.ErrResponseRead
+	}
+
+	err = json.Unmarshal(resBody, &promoCode)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeGetRequest. Error while parsing JSON response body:", err)
+		return promoCode, serverrors.ErrResponseParse
+	}
+
+	return promoCode, nil
+}
+
+func (service *GatewayService) performPromoCodeUsagePostRequest(
+	code string,
+	username string,
+	reservationUid string,
+) (err error) {
+	usageJSON, err := json.Marshal(models.PromoCodeUsage{Username: username, ReservationUid: reservationUid})
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsagePostRequest. Cannot create JSON object for request body:", err)
+		return serverrors.ErrJSONParse
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/promocodes/%s/usages",
+			service.reservServiceHost,
+			service.reservServicePort,
+			url.PathEscape(code),
+		),
+		bytes.NewBuffer(usageJSON),
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsagePostRequest. Error while creating new request:", err)
+		return serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsagePostRequest. Error while sending request:", err)
+		return serverrors.ErrRequestSend
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsagePostRequest. Promo code not found")
+		return serverrors.ErrPromoCodeNotFound
+	}
+
+	if res.StatusCode == http.StatusConflict {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsagePostRequest. Promo code is not applicable")
+		return service.readPromoCodeError(res)
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsagePostRequest. Reservation service returned status", res.StatusCode)
+		return serverrors.ErrServiceResponse
+	}
+
+	return nil
+}
+
+// Releasing a usage twice is harmless, so a failed request is retried later.
+func (service *GatewayService) performPromoCodeUsageDeleteRequest(
+	code string,
+	reservationUid string,
+) (err error) {
+	req, err := http.NewRequest(
+		"DELETE",
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/promocodes/%s/usages/%s",
+			service.reservServiceHost,
+			service.reservServicePort,
+			url.PathEscape(code),
+			reservationUid,
+		),
+		nil,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsageDeleteRequest. Error while creating new request:", err)
+		return serverrors.ErrNewRequestForming
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsageDeleteRequest. Error while sending request:", err)
+		service.reQueue <- req
 		return serverrors.ErrRequestSend
 	}
 
-	return nil
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		log.Println("[ERROR] GatewayService.performPromoCodeUsageDeleteRequest. Reservation service returned status", res.StatusCode)
+		return serverrors.ErrServiceResponse
+	}
+
+	return nil
+}
+
+func hotelsPageLink(
+	filter *models.HotelFilter,
+	cursor string,
+	page int,
+	pageSize int,
+) string {
+	queryValues := url.Values{}
+	queryValues.Set(`size`, strconv.Itoa(pageSize))
+	models.HotelFilterToValues(queryValues, filter)
+
+	if page > 0 {
+		queryValues.Set(`page`, strconv.Itoa(page))
+	} else {
+		queryValues.Set(`cursor`, cursor)
+	}
+
+	return `/api/v1/hotels?` + queryValues.Encode()
+}
+
+func (service *GatewayService) ReadAllHotels(
+	filter *models.HotelFilter,
+	cursor string,
+	page int,
+	pageSize int,
+) (pagRes models.PagiationResponse, validErrRes models.ValidationErrorResponse, err error) {
+	if page < 0 || pageSize <= 0 {
+		log.Println("[ERROR] GatewayService.ReadAllHotels. Invalid parameters")
+		return pagRes, validErrRes, serverrors.ErrInvalidPagesData
+	}
+
+	pagRes, err = service.pagResCb.Execute(
+		func() (pagRes models.PagiationResponse, err error) {
+			var hotelsPage models.HotelsPage
+			hotelsPage, validErrRes, err = service.performAllHotelsGetRequest(filter, cursor, page, pageSize)
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadAllHotels. performAllHotelsGetRequest returned error:", err)
+				return pagRes, err
+			}
+
+			models.HotelsPageToPagRes(&pagRes, &hotelsPage, page, pageSize)
+
+			if page > 0 {
+				if page > 1 {
+					pagRes.Links.Prev = hotelsPageLink(filter, ``, page-1, pageSize)
+				}
+
+				if page*pageSize < hotelsPage.TotalElements {
+					pagRes.Links.Next = hotelsPageLink(filter, ``, page+1, pageSize)
+				}
+			} else {
+				if hotelsPage.PrevCursor != `` {
+					pagRes.Links.Prev = hotelsPageLink(filter, hotelsPage.PrevCursor, 0, pageSize)
+				}
+
+				if hotelsPage.NextCursor != `` {
+					pagRes.Links.Next = hotelsPageLink(filter, hotelsPage.NextCursor, 0, pageSize)
+				}
+			}
+
+			return pagRes, nil
+		},
+	)
+
+	return pagRes, validErrRes, err
+}
+
+func (service *GatewayService) ReadHotelAvailability(
+	hotelUid string,
+	from string,
+	to string,
+) (availabilityRes models.HotelAvailabilityResponse, err error) {
+	if uuid.Validate(hotelUid) != nil {
+		log.Println("[ERROR] GatewayService.ReadHotelAvailability. Invalid hotel uid")
+		return availabilityRes, serverrors.ErrInvalidHoteUid
+	}
+
+	_, err = models.ValidateAvailabilityRange(from, to)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadHotelAvailability. Invalid availability range:", err)
+		return availabilityRes, err
+	}
+
+	availabilityRes, err = service.availabilityCb.Execute(
+		func() (availabilityRes models.HotelAvailabilityResponse, err error) {
+			availabilityRes, err = service.performHotelAvailabilityGetRequest(hotelUid, from, to)
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadHotelAvailability. performHotelAvailabilityGetRequest returned error:", err)
+			}
+
+			return availabilityRes, err
+		},
+	)
+
+	return availabilityRes, err
+}
+
+func (service *GatewayService) CreateHotel(
+	authorization string,
+	hotel *models.Hotel,
+) (newHotel models.Hotel, err error) {
+	_, err = models.ValidateHotel(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.CreateHotel. Invalid hotel data:", err)
+		return newHotel, err
+	}
+
+	newHotel, err = service.performAdminHotelRequest(`POST`, ``, authorization, hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.CreateHotel. performAdminHotelRequest returned error:", err)
+	}
+
+	return newHotel, err
 }
 
-func (service *GatewayService) performPaymentPutRequest(
-	payment *models.Payment,
+func (service *GatewayService) UpdateHotel(
+	authorization string,
+	hotel *models.Hotel,
+) (updatedHotel models.Hotel, err error) {
+	if uuid.Validate(hotel.Uid) != nil {
+		log.Println("[ERROR] GatewayService.UpdateHotel. Invalid hotel uid")
+		return updatedHotel, serverrors.ErrInvalidHoteUid
+	}
+
+	_, err = models.ValidateHotel(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.UpdateHotel. Invalid hotel data:", err)
+		return updatedHotel, err
+	}
+
+	updatedHotel, err = service.performAdminHotelRequest(`PUT`, hotel.Uid, authorization, hotel)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.UpdateHotel. performAdminHotelRequest returned error:", err)
+	}
+
+	return updatedHotel, err
+}
+
+func (service *GatewayService) RetireHotel(
+	authorization string,
+	hotelUid string,
 ) (err error) {
-	paymentJSON, err := json.Marshal(payment)
+	if uuid.Validate(hotelUid) != nil {
+		log.Println("[ERROR] GatewayService.RetireHotel. Invalid hotel uid")
+		return serverrors.ErrInvalidHoteUid
+	}
+
+	_, err = service.performAdminHotelRequest(`DELETE`, hotelUid, authorization, nil)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPutRequest. Cannot create JSON object for request body:", err)
-		return serverrors.ErrJSONParse
+		log.Println("[ERROR] GatewayService.RetireHotel. performAdminHotelRequest returned error:", err)
 	}
 
-	req, err := http.NewRequest(
-		"PUT",
+	return err
+}
+
+func (service *GatewayService) UpdateCancelPolicy(
+	authorization string,
+	hotelUid string,
+	policy *models.CancellationPolicy,
+) (updatedPolicy models.CancellationPolicy, err error) {
+	if uuid.Validate(hotelUid) != nil {
+		log.Println("[ERROR] GatewayService.UpdateCancelPolicy. Invalid hotel uid")
+		return updatedPolicy, serverrors.ErrInvalidHoteUid
+	}
+
+	_, err = models.ValidateCancellationPolicy(policy)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.UpdateCancelPolicy. Invalid cancellation policy:", err)
+		return updatedPolicy, err
+	}
+
+	updatedPolicy, err = service.performAdminCancelPolicyRequest(`PUT`, hotelUid, authorization, policy)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.UpdateCancelPolicy. performAdminCancelPolicyRequest returned error:", err)
+	}
+
+	return updatedPolicy, err
+}
+
+func (service *GatewayService) DeleteCancelPolicy(
+	authorization string,
+	hotelUid string,
+) (err error) {
+	if uuid.Validate(hotelUid) != nil {
+		log.Println("[ERROR] GatewayService.DeleteCancelPolicy. Invalid hotel uid")
+		return serverrors.ErrInvalidHoteUid
+	}
+
+	_, err = service.performAdminCancelPolicyRequest(`DELETE`, hotelUid, authorization, nil)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.DeleteCancelPolicy. performAdminCancelPolicyRequest returned error:", err)
+	}
+
+	return err
+}
+
+func (service *GatewayService) ReadRateRules(
+	authorization string,
+	hotelUid string,
+) (rules []models.RateRule, err error) {
+	if uuid.Validate(hotelUid) != nil {
+		log.Println("[ERROR] GatewayService.ReadRateRules. Invalid hotel uid")
+		return rules, serverrors.ErrInvalidHoteUid
+	}
+
+	rules, err = service.performAdminRateRulesRequest(`GET`, hotelUid, authorization, nil)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadRateRules. performAdminRateRulesRequest returned error:", err)
+	}
+
+	return rules, err
+}
+
+func (service *GatewayService) ReplaceRateRules(
+	authorization string,
+	hotelUid string,
+	rules []models.RateRule,
+) (newRules []models.RateRule, err error) {
+	if uuid.Validate(hotelUid) != nil {
+		log.Println("[ERROR] GatewayService.ReplaceRateRules. Invalid hotel uid")
+		return newRules, serverrors.ErrInvalidHoteUid
+	}
+
+	_, err = models.ValidateRateRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReplaceRateRules. Invalid rate rules:", err)
+		return newRules, err
+	}
+
+	if rules == nil {
+		rules = make([]models.RateRule, 0)
+	}
+
+	newRules, err = service.performAdminRateRulesRequest(`PUT`, hotelUid, authorization, rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReplaceRateRules. performAdminRateRulesRequest returned error:", err)
+	}
+
+	return newRules, err
+}
+
+func (service *GatewayService) ReadExchangeRates() (rates []models.ExchangeRate, err error) {
+	rates, err = performListGetRequest[models.ExchangeRate](
 		fmt.Sprintf(
-			"http://%s:%d/api/v1/payment/%s",
-			service.paymentServiceHost,
-			service.paymentServicePort,
-			payment.Uid,
+			"http://%s:%d/api/v1/exchange-rates",
+			service.reservServiceHost,
+			service.reservServicePort,
 		),
-		bytes.NewBuffer(paymentJSON),
+		``,
 	)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPutRequest. Error while creating new request:", err)
-		return serverrors.ErrNewRequestForming
+		log.Println("[ERROR] GatewayService.ReadExchangeRates. performListGetRequest returned error:", err)
 	}
 
-	_, err = http.DefaultClient.Do(req)
+	return rates, err
+}
+
+func (service *GatewayService) ReplaceExchangeRates(
+	authorization string,
+	rates []models.ExchangeRate,
+) (newRates []models.ExchangeRate, err error) {
+	_, err = models.ValidateExchangeRates(rates)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.performPaymentPutRequest. Error while sending request:", err)
-		service.reQueue <- req
-		return serverrors.ErrRequestSend
+		log.Println("[ERROR] GatewayService.ReplaceExchangeRates. Invalid exchange rates:", err)
+		return newRates, err
 	}
 
-	return nil
+	if rates == nil {
+		rates = make([]models.ExchangeRate, 0)
+	}
+
+	newRates, err = performAdminListRequest(
+		`PUT`,
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/admin/exchange-rates",
+			service.reservServiceHost,
+			service.reservServicePort,
+		),
+		authorization,
+		rates,
+		serverrors.ErrInvalidExchangeRate,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReplaceExchangeRates. performAdminListRequest returned error:", err)
+	}
+
+	return newRates, err
 }
 
-func (service *GatewayService) ReadAllHotels(
-	page int,
-	pageSize int,
-) (pagRes models.PagiationResponse, err error) {
-	if page <= 0 || pageSize <= 0 {
-		log.Println("[ERROR] GatewayService.ReadAllHotels. Invalid parameters")
-		return pagRes, serverrors.ErrInvalidPagesData
+func (service *GatewayService) ReadTaxRules(authorization string) (rules []models.TaxRule, err error) {
+	rules, err = performListGetRequest[models.TaxRule](
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/admin/tax-rules",
+			service.reservServiceHost,
+			service.reservServicePort,
+		),
+		authorization,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadTaxRules. performListGetRequest returned error:", err)
 	}
 
-	pagRes, err = service.pagResCb.Execute(
-		func() (pagRes models.PagiationResponse, err error) {
-			hotelsSlice, err := service.performAllHotelsGetRequest(page, pageSize)
+	return rules, err
+}
 
-			if err != nil {
-				log.Println("[ERROR] GatewayService.ReadAllHotels. performAllHotelsGetRequest returned error:", err)
-				return pagRes, err
-			}
+func (service *GatewayService) ReplaceTaxRules(
+	authorization string,
+	rules []models.TaxRule,
+) (newRules []models.TaxRule, err error) {
+	_, err = models.ValidateTaxRules(rules)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReplaceTaxRules. Invalid tax rules:", err)
+		return newRules, err
+	}
 
-			models.HotelsSliceToPagRes(&pagRes, hotelsSlice, page, pageSize)
+	if rules == nil {
+		rules = make([]models.TaxRule, 0)
+	}
 
-			return pagRes, nil
-		},
+	newRules, err = performAdminListRequest(
+		`PUT`,
+		fmt.Sprintf(
+			"http://%s:%d/api/v1/admin/tax-rules",
+			service.reservServiceHost,
+			service.reservServicePort,
+		),
+		authorization,
+		rules,
+		serverrors.ErrInvalidTaxRule,
 	)
 
-	return pagRes, err
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReplaceTaxRules. performAdminListRequest returned error:", err)
+	}
+
+	return newRules, err
 }
 
 func (service *GatewayService) ReadUserInfo(
@@ -793,82 +2027,304 @@ func (service *GatewayService) ReadUserInfo(
 
 			hotelsMap, err := service.performReservsHotelsGetRequest(userReservsSlice)
 
-			if err != nil {
-				log.Println("[ERROR] GatewayService.ReadUserInfo. performReservsHotelsGetRequest returned error:", err)
-				return userInfoRes, err
-			}
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadUserInfo. performReservsHotelsGetRequest returned error:", err)
+				return userInfoRes, err
+			}
+
+			paymentsMap, err := service.performReservsPaymentsGetRequest(userReservsSlice)
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadUserInfo. performReservsPaymentsGetRequest returned error:", err)
+				return userInfoRes, err
+			}
+
+			loyalty, err := service.performLoyaltyByUsernameGetRequest(username)
+
+			if err != nil && !errors.Is(err, serverrors.ErrEntityNotFound) {
+				log.Println("[ERROR] GatewayService.ReadUserInfo. performLoyaltyByUsernameGetRequest returned error:", err)
+				return userInfoRes, err
+			}
+
+			models.ReservsSliceToUserInfoRes(&userInfoRes, userReservsSlice, hotelsMap, paymentsMap, &loyalty)
+
+			return userInfoRes, nil
+		},
+	)
+
+	if err != nil && errors.Is(err, serverrors.ErrRequestSend) {
+		return models.UserInfoResponse{Reservations: make([]models.ReservationResponse, 0)}, serverrors.ErrLoyaltyServiceUnavailable
+	}
+
+	return userInfoRes, err
+}
+
+func (service *GatewayService) ReadUserReservations(
+	username string,
+) (reservsResSlice []models.ReservationResponse, err error) {
+	reservsResSlice = make([]models.ReservationResponse, 0)
+
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayService.ReadUserReservations. Invalid username")
+		return reservsResSlice, serverrors.ErrInvalidUsername
+	}
+
+	reservsResSlice, err = service.reservsResCb.Execute(
+		func() (reservsResSlice []models.ReservationResponse, err error) {
+			userReservsSlice, err := service.performUserReservsGetRequest(username)
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadUserReservations. performUserReservsGetRequest returned error:", err)
+				return reservsResSlice, err
+			}
+
+			hotelsMap, err := service.performReservsHotelsGetRequest(userReservsSlice)
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadUserReservations. performReservsHotelsGetRequest returned error:", err)
+				return reservsResSlice, err
+			}
+
+			paymentsMap, err := service.performReservsPaymentsGetRequest(userReservsSlice)
+
+			if err != nil {
+				log.Println("[ERROR] GatewayService.ReadUserReservations. performReservsPaymentsGetRequest returned error:", err)
+				return reservsResSlice, err
+			}
+
+			models.ReservsSliceToReservRes(&reservsResSlice, userReservsSlice, hotelsMap, paymentsMap)
+
+			return reservsResSlice, nil
+		},
+	)
+
+	return reservsResSlice, err
+}
+
+type priceQuoteClaims struct {
+	Username         string                   `json:"username"`
+	HotelUid         string                   `json:"hotelUid"`
+	StartDate        string                   `json:"startDate"`
+	EndDate          string                   `json:"endDate"`
+	PromoCode        string                   `json:"promoCode,omitempty"`
+	Guests           int                      `json:"guests"`
+	Subtotal         int                      `json:"subtotal"`
+	TotalPrice       int                      `json:"totalPrice"`
+	Currency         string                   `json:"currency,omitempty"`
+	AppliedDiscounts []models.AppliedDiscount `json:"appliedDiscounts"`
+	Taxes            []models.AppliedTax      `json:"taxes"`
+	ExpiresAt        int64                    `json:"expiresAt"`
+}
+
+// The promo code usage recorded for reservationUid, if any, does not count
+// against the promo code caps.
+func (service *GatewayService) priceReservation(
+	username string,
+	reservationUid string,
+	crReservReq *models.CreateReservationRequest,
+) (hotel models.Hotel, promoCode *models.PromoCode, quote models.PriceQuoteResponse, err error) {
+	hotel, err = service.performHotelByUidGetRequest(crReservReq.HotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.priceReservation. performHotelByUidGetRequest returned error:", err)
+
+		if errors.Is(err, serverrors.ErrEntityNotFound) {
+			return hotel, promoCode, quote, serverrors.ErrHotelNotFound
+		}
+
+		return hotel, promoCode, quote, err
+	}
+
+	if hotel.Retired {
+		log.Println("[ERROR] GatewayService.priceReservation. Hotel is retired")
+		return hotel, promoCode, quote, serverrors.ErrHotelNotFound
+	}
+
+	loyalty, err := service.performLoyaltyByUsernameGetRequest(username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.priceReservation. performLoyaltyByUsernameGetRequest returned error:", err)
+
+		if errors.Is(err, serverrors.ErrRequestSend) {
+			return hotel, promoCode, quote, serverrors.ErrLoyaltyServiceUnavailable
+		}
+
+		return hotel, promoCode, quote, err
+	}
+
+	if crReservReq.PromoCode != `` {
+		desiredPromoCode, err := service.performPromoCodeGetRequest(crReservReq.PromoCode, username, hotel.Uid, reservationUid)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.priceReservation. performPromoCodeGetRequest returned error:", err)
+			return hotel, promoCode, quote, err
+		}
+
+		promoCode = &desiredPromoCode
+	}
+
+	priceRes, err := service.performHotelPricesGetRequest(hotel.Uid, crReservReq.StartDate, crReservReq.EndDate)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.priceReservation. performHotelPricesGetRequest returned error:", err)
+		return hotel, promoCode, quote, err
+	}
+
+	taxRules, err := service.performHotelTaxRulesGetRequest(hotel.Uid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.priceReservation. performHotelTaxRulesGetRequest returned error:", err)
+		return hotel, promoCode, quote, err
+	}
+
+	log.Println("[TRACE] GatewayService.priceReservation. Loyalty discount =", loyalty.Discount)
+
+	quote.HotelUid = hotel.Uid
+	quote.StartDate = crReservReq.StartDate
+	quote.EndDate = crReservReq.EndDate
+	quote.Nights = len(priceRes.Nights)
+	quote.NightlyPrices = priceRes.Nights
+	quote.BasePrice = priceRes.TotalPrice
+
+	if quote.Nights > 0 {
+		quote.NightlyRate = (quote.BasePrice + quote.Nights/2) / quote.Nights
+	}
+	quote.Currency = models.NormalizeCurrency(hotel.Currency)
+
+	subtotal, appliedDiscounts := models.ApplyDiscounts(
+		models.NewMoney(quote.BasePrice, quote.Currency), loyalty.Discount, promoCode,
+	)
+	quote.Subtotal = subtotal.Amount
+	quote.AppliedDiscounts = appliedDiscounts
+	quote.Guests = max(crReservReq.Guests, models.DefaultGuests)
+
+	totalPrice, taxes := models.ApplyTaxes(subtotal, quote.Nights, quote.Guests, taxRules)
+	quote.TotalPrice = totalPrice.Amount
+	quote.Taxes = taxes
+
+	return hotel, promoCode, quote, nil
+}
+
+// The quoted price is charged only while it stays within the allowed drift of
+// the current price, so a forged or stale token cannot set an arbitrary price.
+// A current price below the quoted one is charged as is.
+func (service *GatewayService) applyQuoteToken(
+	username string,
+	crReservReq *models.CreateReservationRequest,
+	quote models.PriceQuoteResponse,
+) (lockedQuote models.PriceQuoteResponse, err error) {
+	payload, err := verifyToken(service.quoteSecret, crReservReq.QuoteToken)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.applyQuoteToken. verifyToken returned error:", err)
+		return lockedQuote, err
+	}
 
-			paymentsMap, err := service.performReservsPaymentsGetRequest(userReservsSlice)
+	var claims priceQuoteClaims
+	err = json.Unmarshal(payload, &claims)
 
-			if err != nil {
-				log.Println("[ERROR] GatewayService.ReadUserInfo. performReservsPaymentsGetRequest returned error:", err)
-				return userInfoRes, err
-			}
+	if err != nil {
+		log.Println("[ERROR] GatewayService.applyQuoteToken. Error while parsing quote token payload:", err)
+		return lockedQuote, serverrors.ErrInvalidToken
+	}
 
-			loyalty, err := service.performLoyaltyByUsernameGetRequest(username)
+	if time.Now().Unix() > claims.ExpiresAt {
+		log.Println("[ERROR] GatewayService.applyQuoteToken. Quote is expired")
+		return lockedQuote, serverrors.ErrQuoteExpired
+	}
 
-			if err != nil && !errors.Is(err, serverrors.ErrEntityNotFound) {
-				log.Println("[ERROR] GatewayService.ReadUserInfo. performLoyaltyByUsernameGetRequest returned error:", err)
-				return userInfoRes, err
-			}
+	if claims.Username != username ||
+		claims.HotelUid != crReservReq.HotelUid ||
+		claims.StartDate != crReservReq.StartDate ||
+		claims.EndDate != crReservReq.EndDate ||
+		claims.PromoCode != crReservReq.PromoCode ||
+		claims.Guests != quote.Guests ||
+		(claims.Currency != `` && claims.Currency != quote.Currency) {
+		log.Println("[ERROR] GatewayService.applyQuoteToken. Quote does not match reservation request")
+		return lockedQuote, serverrors.ErrQuoteMismatch
+	}
 
-			models.ReservsSliceToUserInfoRes(&userInfoRes, userReservsSlice, hotelsMap, paymentsMap, &loyalty)
+	if !models.IsWithinPriceLock(claims.TotalPrice, quote.TotalPrice, service.quoteMaxDrift) {
+		log.Println("[ERROR] GatewayService.applyQuoteToken. Quoted price", claims.TotalPrice, "is too far from current price", quote.TotalPrice)
+		return lockedQuote, serverrors.ErrQuotePriceChanged
+	}
 
-			return userInfoRes, nil
-		},
-	)
+	// The lock only protects against price increases.
+	if quote.TotalPrice <= claims.TotalPrice {
+		quote.QuoteToken = crReservReq.QuoteToken
+		quote.ExpiresAt = time.Unix(claims.ExpiresAt, 0).UTC().Format(time.RFC3339)
 
-	if err != nil && errors.Is(err, serverrors.ErrRequestSend) {
-		return models.UserInfoResponse{Reservations: make([]models.ReservationResponse, 0)}, serverrors.ErrLoyaltyServiceUnavailable
+		return quote, nil
 	}
 
-	return userInfoRes, err
+	lockedQuote = quote
+	lockedQuote.Subtotal = claims.Subtotal
+	lockedQuote.TotalPrice = claims.TotalPrice
+	lockedQuote.AppliedDiscounts = claims.AppliedDiscounts
+	lockedQuote.Taxes = claims.Taxes
+	lockedQuote.QuoteToken = crReservReq.QuoteToken
+	lockedQuote.ExpiresAt = time.Unix(claims.ExpiresAt, 0).UTC().Format(time.RFC3339)
+
+	return lockedQuote, nil
 }
 
-func (service *GatewayService) ReadUserReservations(
+func (service *GatewayService) ReadPriceQuote(
 	username string,
-) (reservsResSlice []models.ReservationResponse, err error) {
-	reservsResSlice = make([]models.ReservationResponse, 0)
-
+	crReservReq *models.CreateReservationRequest,
+) (quote models.PriceQuoteResponse, err error) {
 	if strings.Trim(username, ` `) == `` {
-		log.Println("[ERROR] GatewayService.ReadUserReservations. Invalid username")
-		return reservsResSlice, serverrors.ErrInvalidUsername
+		log.Println("[ERROR] GatewayService.ReadPriceQuote. Invalid username")
+		return quote, serverrors.ErrInvalidUsername
 	}
 
-	reservsResSlice, err = service.reservsResCb.Execute(
-		func() (reservsResSlice []models.ReservationResponse, err error) {
-			userReservsSlice, err := service.performUserReservsGetRequest(username)
-
-			if err != nil {
-				log.Println("[ERROR] GatewayService.ReadUserReservations. performUserReservsGetRequest returned error:", err)
-				return reservsResSlice, err
-			}
-
-			hotelsMap, err := service.performReservsHotelsGetRequest(userReservsSlice)
+	_, err = models.ValidateCrReservReq(crReservReq)
 
-			if err != nil {
-				log.Println("[ERROR] GatewayService.ReadUserReservations. performReservsHotelsGetRequest returned error:", err)
-				return reservsResSlice, err
-			}
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadPriceQuote. Invalid create reservation request:", err)
+		return quote, serverrors.ErrInvalidCrReservReq
+	}
 
-			paymentsMap, err := service.performReservsPaymentsGetRequest(userReservsSlice)
+	_, _, quote, err = service.priceReservation(username, ``, crReservReq)
 
-			if err != nil {
-				log.Println("[ERROR] GatewayService.ReadUserReservations. performReservsPaymentsGetRequest returned error:", err)
-				return reservsResSlice, err
-			}
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadPriceQuote. priceReservation returned error:", err)
+		return quote, err
+	}
+
+	expiresAt := time.Now().Add(service.quoteTtl)
+
+	claimsJSON, err := json.Marshal(priceQuoteClaims{
+		Username:         username,
+		HotelUid:         quote.HotelUid,
+		StartDate:        quote.StartDate,
+		EndDate:          quote.EndDate,
+		PromoCode:        crReservReq.PromoCode,
+		Guests:           quote.Guests,
+		Subtotal:         quote.Subtotal,
+		TotalPrice:       quote.TotalPrice,
+		Currency:         quote.Currency,
+		AppliedDiscounts: quote.AppliedDiscounts,
+		Taxes:            quote.Taxes,
+		ExpiresAt:        expiresAt.Unix(),
+	})
 
-			models.ReservsSliceToReservRes(&reservsResSlice, userReservsSlice, hotelsMap, paymentsMap)
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadPriceQuote. Cannot create JSON object for quote token:", err)
+		return quote, serverrors.ErrJSONParse
+	}
 
-			return reservsResSlice, nil
-		},
-	)
+	quote.QuoteToken = signToken(service.quoteSecret, claimsJSON)
+	quote.ExpiresAt = expiresAt.UTC().Format(time.RFC3339)
 
-	return reservsResSlice, err
+	return quote, nil
 }
 
+// Requests retried by the client with the same idempotency key are mapped onto
+// the same reservation and payment, so a retry returns the reservation made by
+// the first request instead of booking and charging again.
 func (service *GatewayService) CreateReservation(
 	username string,
+	idempotencyKey string,
 	crReservReq *models.CreateReservationRequest,
 ) (crReservRes models.CreateReservationResponse, err error) {
 	if strings.Trim(username, ` `) == `` {
@@ -883,37 +2339,46 @@ func (service *GatewayService) CreateReservation(
 		return crReservRes, serverrors.ErrInvalidCrReservReq
 	}
 
-	hotel, err := service.performHotelByUidGetRequest(crReservReq.HotelUid)
+	hotel, promoCode, quote, err := service.priceReservation(username, ``, crReservReq)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.CreateReservation. performHotelByUidGetRequest returned error:", err)
+		log.Println("[ERROR] GatewayService.CreateReservation. priceReservation returned error:", err)
 		return crReservRes, err
 	}
 
-	loyalty, err := service.performLoyaltyByUsernameGetRequest(username)
-
-	if err != nil {
-		log.Println("[ERROR] GatewayService.CreateReservation. performLoyaltyByUsernameGetRequest returned error:", err)
+	if crReservReq.QuoteToken != `` {
+		quote, err = service.applyQuoteToken(username, crReservReq, quote)
 
-		if errors.Is(err, serverrors.ErrRequestSend) {
-			return crReservRes, serverrors.ErrLoyaltyServiceUnavailable
+		if err != nil {
+			log.Println("[ERROR] GatewayService.CreateReservation. applyQuoteToken returned error:", err)
+			return crReservRes, err
 		}
-
-		return crReservRes, err
 	}
 
-	startDate, _ := time.Parse(time.DateOnly, crReservReq.StartDate)
-	endDate, _ := time.Parse(time.DateOnly, crReservReq.EndDate)
-	nightsCount := int(endDate.Sub(startDate).Hours() / 24)
-	price := nightsCount * hotel.Price
+	reservationUid := uuid.New().String()
 
-	log.Println("[TRACE] GatewayService.CreateReservation. Loyalty discount =", loyalty.Discount)
+	if idempotencyKey != `` {
+		reservationUid = uuid.NewSHA1(uuid.NameSpaceURL, []byte(username+"\n"+idempotencyKey)).String()
+		existingReservation, err := service.performReservGetRequest(reservationUid)
+
+		if err == nil {
+			return service.replayReservation(&existingReservation, &hotel, &quote)
+		}
 
-	if loyalty.Discount > 0 {
-		price = int(math.Round(float64(price) * (1.0 - float64(loyalty.Discount)/100.0)))
+		if !errors.Is(err, serverrors.ErrEntityNotFound) {
+			log.Println("[ERROR] GatewayService.CreateReservation. performReservGetRequest returned error:", err)
+			return crReservRes, err
+		}
 	}
 
-	payment, err := service.performPaymentPostRequest(price)
+	payment, err := service.performPaymentPostRequest(&models.CreatePaymentRequest{
+		Amount:         quote.TotalPrice,
+		Currency:       quote.Currency,
+		ReservationUid: reservationUid,
+		IdempotencyKey: `reservation:` + reservationUid,
+		Metadata:       map[string]string{`username`: username, `hotelUid`: hotel.Uid},
+		LineItems:      models.QuoteToPaymentLineItems(&quote),
+	})
 
 	if err != nil {
 		log.Println("[ERROR] GatewayService.CreateReservation. performPaymentPostRequest returned error:", err)
@@ -921,31 +2386,135 @@ func (service *GatewayService) CreateReservation(
 	}
 
 	reservation, err := service.performReservationPostRequest(
-		username, payment.Uid,
-		hotel.Id, payment.Status,
+		reservationUid, username, payment.Uid,
+		hotel.Id, models.ReservStatusPending,
 		crReservReq.StartDate, crReservReq.EndDate,
+		crReservReq.RoomTypeUid, crReservReq.PromoCode, &quote,
 	)
 
+	// Without a reservation the authorized payment is never captured, so it is
+	// voided whatever the failure was. A hold created despite the error expires.
 	if err != nil {
 		log.Println("[ERROR] GatewayService.CreateReservation. performReservationPostRequest returned error:", err)
+
+		// The payment belongs to a concurrent request with the same idempotency key.
+		if idempotencyKey != `` {
+			if _, getErr := service.performReservGetRequest(reservationUid); getErr == nil {
+				return crReservRes, serverrors.ErrIdempotencyKeyReused
+			}
+		}
+
+		service.performPaymentOperationRequest(payment.Uid, `void`, 0, true)
+
+		return crReservRes, err
+	}
+
+	if promoCode != nil {
+		err = service.performPromoCodeUsagePostRequest(promoCode.Code, username, reservation.Uid)
+
+		// An unrecorded usage would not count towards the cap, so the
+		// reservation is not made while the usage cannot be recorded.
+		if err != nil {
+			log.Println("[ERROR] GatewayService.CreateReservation. performPromoCodeUsagePostRequest returned error:", err)
+
+			reservation.Status = models.ReservStatusCanceled
+			service.performReservPutRequest(&reservation, true)
+
+			service.performPaymentOperationRequest(payment.Uid, `void`, 0, true)
+
+			return crReservRes, err
+		}
+	}
+
+	err = service.confirmReservation(&reservation, &payment)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.CreateReservation. confirmReservation returned error:", err)
+
+		if promoCode != nil {
+			service.performPromoCodeUsageDeleteRequest(promoCode.Code, reservation.Uid)
+		}
+
 		return crReservRes, err
 	}
 
-	loyalty.ReservationCount++
-	models.UpdateLoyaltyStatus(&loyalty)
+	err = service.performLoyaltyCountPatchRequest(username, reservation.Uid, 1)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.CreateReservation. performLoyaltyCountPatchRequest returned error:", err)
+
+		if !errors.Is(err, serverrors.ErrRequestSend) {
+			return crReservRes, err
+		}
+	}
+
+	models.ReservToCrReservRes(&crReservRes, &reservation, &payment, &quote, hotel.Uid)
+
+	return crReservRes, nil
+}
+
+func (service *GatewayService) replayReservation(
+	reservation *models.Reservation,
+	hotel *models.Hotel,
+	quote *models.PriceQuoteResponse,
+) (crReservRes models.CreateReservationResponse, err error) {
+	if reservation.HotelId != hotel.Id || reservation.StartDate != quote.StartDate || reservation.EndDate != quote.EndDate {
+		log.Println("[ERROR] GatewayService.replayReservation. Idempotency key was used for another reservation")
+		return crReservRes, serverrors.ErrIdempotencyKeyReused
+	}
 
-	err = service.performLoyaltyPutRequest(&loyalty)
+	payment, err := service.performPaymentByUidGetRequest(reservation.PaymentUid)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.CreateReservation. performLoyaltyPutRequest returned error:", err)
+		log.Println("[ERROR] GatewayService.replayReservation. performPaymentByUidGetRequest returned error:", err)
 		return crReservRes, err
 	}
 
-	models.ReservToCrReservRes(&crReservRes, &reservation, &payment, &loyalty, hotel.Uid)
+	log.Println("[INFO] GatewayService.replayReservation. Returning reservation", reservation.Uid)
+	models.ReservToCrReservRes(&crReservRes, reservation, &payment, quote, hotel.Uid)
 
 	return crReservRes, nil
 }
 
+// The reservation is created as a PENDING hold against an authorized payment.
+// The payment is captured next and the hold is confirmed last, so a failure at
+// any step releases the room and voids or refunds the payment.
+func (service *GatewayService) confirmReservation(
+	reservation *models.Reservation,
+	payment *models.Payment,
+) (err error) {
+	capturedPayment, err := service.performPaymentOperationRequest(payment.Uid, `capture`, 0, false)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.confirmReservation. Error while capturing payment:", err)
+
+		reservation.Status = models.ReservStatusCanceled
+		service.performReservPutRequest(reservation, true)
+
+		service.performPaymentOperationRequest(payment.Uid, `void`, 0, true)
+
+		return err
+	}
+
+	*payment = capturedPayment
+
+	reservation.Status = models.ReservStatusPaid
+	err = service.performReservPutRequest(reservation, false)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.confirmReservation. Error while confirming reservation hold:", err)
+
+		reservation.Status = models.ReservStatusCanceled
+		service.performReservPutRequest(reservation, true)
+
+		service.performPaymentOperationRequest(payment.Uid, `refund`, 0, true)
+
+		return err
+	}
+
+	return nil
+}
+
 func (service *GatewayService) ReadReservation(
 	reservUid string,
 	username string,
@@ -997,6 +2566,83 @@ func (service *GatewayService) ReadReservation(
 	return reservRes, err
 }
 
+// The loyalty status is informational, so the receipt is still issued while
+// the loyalty service is unavailable.
+func (service *GatewayService) ReadReservationReceipt(
+	reservUid string,
+	username string,
+) (receipt models.Receipt, err error) {
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. Invalid username")
+		return receipt, serverrors.ErrInvalidUsername
+	}
+
+	if uuid.Validate(reservUid) != nil {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. Invalid reservation uid")
+		return receipt, serverrors.ErrInvalidReservUid
+	}
+
+	reservation, err := service.performReservGetRequest(reservUid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. performReservGetRequest returned error:", err)
+		return receipt, err
+	}
+
+	if reservation.Username != username {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. Reservation belongs to another user")
+		return receipt, serverrors.ErrEntityNotFound
+	}
+
+	hotel, err := service.performHotelByIdGetRequest(reservation.HotelId)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. performHotelByIdGetRequest returned error:", err)
+		return receipt, err
+	}
+
+	payment, err := service.performPaymentByUidGetRequest(reservation.PaymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. performPaymentByUidGetRequest returned error:", err)
+		return receipt, err
+	}
+
+	transactions, err := service.performPaymentTransactionsGetRequest(payment.Uid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. performPaymentTransactionsGetRequest returned error:", err)
+		return receipt, err
+	}
+
+	// The invoice is issued on capture; the PUT only returns it, or numbers
+	// payments captured before invoices were issued. Uncharged holds get a
+	// pro-forma receipt without a number.
+	var invoicePtr *models.Invoice
+
+	if models.IsPaymentCharged(payment.Status) {
+		invoice, err := service.performPaymentInvoicePutRequest(payment.Uid)
+
+		if err != nil {
+			log.Println("[ERROR] GatewayService.ReadReservationReceipt. performPaymentInvoicePutRequest returned error:", err)
+			return receipt, err
+		}
+
+		invoicePtr = &invoice
+	}
+
+	var loyaltyPtr *models.Loyalty
+	loyalty, err := service.performLoyaltyByUsernameGetRequest(username)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ReadReservationReceipt. performLoyaltyByUsernameGetRequest returned error:", err)
+	} else {
+		loyaltyPtr = &loyalty
+	}
+
+	return models.BuildReceipt(&reservation, &hotel, &payment, transactions, invoicePtr, loyaltyPtr), nil
+}
+
 func (service *GatewayService) DeleteReservation(
 	reservUid string,
 	username string,
@@ -1018,11 +2664,15 @@ func (service *GatewayService) DeleteReservation(
 		return err
 	}
 
-	reservation.Status = `CANCELED`
-	err = service.performReservPutRequest(&reservation)
+	if !models.CanChangeReservStatus(reservation.Status, models.ReservStatusCanceled) {
+		log.Println("[ERROR] GatewayService.DeleteReservation. Reservation cannot be canceled in status", reservation.Status)
+		return serverrors.ErrInvalidStatusTransition
+	}
+
+	hotel, err := service.performHotelByIdGetRequest(reservation.HotelId)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.DeleteReservation. Error while puting reservation: ", err)
+		log.Println("[ERROR] GatewayService.DeleteReservation. Error while getting hotel by id: ", err)
 		return err
 	}
 
@@ -1033,15 +2683,48 @@ func (service *GatewayService) DeleteReservation(
 		return err
 	}
 
-	payment.Status = `CANCELED`
-	err = service.performPaymentPutRequest(&payment)
+	switch payment.Status {
+	case models.PaymentStatusAuthorized:
+		_, err = service.performPaymentOperationRequest(payment.Uid, `void`, 0, true)
+	case models.PaymentStatusCaptured, models.PaymentStatusPartiallyRefunded:
+		refund := models.ComputeCancellationRefund(
+			hotel.CancellationPolicy,
+			payment.Price-payment.RefundedAmount,
+			reservation.StartDate,
+			time.Now(),
+		)
+
+		if refund > 0 {
+			_, err = service.performPaymentOperationRequest(payment.Uid, `refund`, refund, true)
+		}
+	}
+
+	// A release which could not be sent is queued and retried, so the
+	// reservation is canceled anyway; any other failure leaves it as it was.
+	if err != nil {
+		log.Println("[ERROR] GatewayService.DeleteReservation. Error while releasing payment: ", err)
+
+		if !errors.Is(err, serverrors.ErrRequestSend) {
+			return err
+		}
+	}
+
+	// Only paid reservations were counted by the loyalty service, a hold was not.
+	wasPaid := reservation.Status == models.ReservStatusPaid
+
+	reservation.Status = models.ReservStatusCanceled
+	err = service.performReservPutRequest(&reservation, true)
 
 	if err != nil {
-		log.Println("[ERROR] GatewayService.DeleteReservation. Error while puting payment: ", err)
+		log.Println("[ERROR] GatewayService.DeleteReservation. Error while puting reservation: ", err)
 		return err
 	}
 
-	err = service.performLoyaltyDecreasePatchRequest(username)
+	if !wasPaid {
+		return nil
+	}
+
+	err = service.performLoyaltyCountPatchRequest(username, reservation.Uid, -1)
 
 	if err != nil {
 		log.Println("[ERROR] GatewayService.DeleteReservation. Error while puting loyalty: ", err)
@@ -1054,6 +2737,134 @@ func (service *GatewayService) DeleteReservation(
 	return nil
 }
 
+func (service *GatewayService) ChangeReservationDates(
+	reservUid string,
+	username string,
+	changeReservReq *models.ChangeReservationRequest,
+) (changeReservRes models.ChangeReservationResponse, err error) {
+	if strings.Trim(username, ` `) == `` {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. Invalid username")
+		return changeReservRes, serverrors.ErrInvalidUsername
+	}
+
+	if uuid.Validate(reservUid) != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. Invalid reservation uid")
+		return changeReservRes, serverrors.ErrInvalidReservUid
+	}
+
+	_, err = models.ValidateChangeReservReq(changeReservReq)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. Invalid change reservation request:", err)
+		return changeReservRes, err
+	}
+
+	reservation, err := service.performReservGetRequest(reservUid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. performReservGetRequest returned error:", err)
+		return changeReservRes, err
+	}
+
+	if reservation.Username != username {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. Reservation belongs to another user")
+		return changeReservRes, serverrors.ErrReservNotFound
+	}
+
+	if reservation.Status != models.ReservStatusPaid {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. Reservation cannot be changed in status", reservation.Status)
+		return changeReservRes, serverrors.ErrInvalidStatusTransition
+	}
+
+	hotel, err := service.performHotelByIdGetRequest(reservation.HotelId)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. performHotelByIdGetRequest returned error:", err)
+		return changeReservRes, err
+	}
+
+	crReservReq := models.CreateReservationRequest{
+		HotelUid:  hotel.Uid,
+		StartDate: changeReservReq.StartDate,
+		EndDate:   changeReservReq.EndDate,
+		PromoCode: reservation.PromoCode,
+		Guests:    reservation.Guests,
+	}
+
+	_, _, quote, err := service.priceReservation(username, reservation.Uid, &crReservReq)
+
+	// A promo code that no longer applies to the new dates is released and the
+	// reservation is repriced without it.
+	promoCodeReleased := false
+
+	if crReservReq.PromoCode != `` &&
+		(errors.Is(err, serverrors.ErrPromoCodeNotFound) || errors.Is(err, serverrors.ErrPromoCodeNotApplicable)) {
+		log.Println("[INFO] GatewayService.ChangeReservationDates. Promo code is not applicable to the new dates:", err)
+
+		crReservReq.PromoCode = ``
+		promoCodeReleased = true
+		_, _, quote, err = service.priceReservation(username, reservation.Uid, &crReservReq)
+	}
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. priceReservation returned error:", err)
+		return changeReservRes, err
+	}
+
+	payment, err := service.performPaymentByUidGetRequest(reservation.PaymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. performPaymentByUidGetRequest returned error:", err)
+		return changeReservRes, err
+	}
+
+	changedReservation := reservation
+	changedReservation.StartDate = changeReservReq.StartDate
+	changedReservation.EndDate = changeReservReq.EndDate
+	changedReservation.PromoCode = crReservReq.PromoCode
+	models.QuoteToReservPricing(&changedReservation, &quote)
+
+	err = service.performReservPutRequest(&changedReservation, false)
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. performReservPutRequest returned error:", err)
+		return changeReservRes, err
+	}
+
+	paidAmount := payment.Price - payment.RefundedAmount
+	priceDifference := quote.TotalPrice - paidAmount
+	changedPayment := payment
+
+	if priceDifference > 0 {
+		changedPayment, err = service.performPaymentOperationRequest(payment.Uid, `capture`, priceDifference, false)
+	} else if priceDifference < 0 {
+		changedPayment, err = service.performPaymentOperationRequest(payment.Uid, `refund`, -priceDifference, false)
+	}
+
+	if err != nil {
+		log.Println("[ERROR] GatewayService.ChangeReservationDates. performPaymentOperationRequest returned error:", err)
+
+		rollbackErr := service.performReservPutRequest(&reservation, true)
+
+		if rollbackErr != nil {
+			log.Println("[ERROR] GatewayService.ChangeReservationDates. Reservation rollback failed:", rollbackErr)
+		}
+
+		return changeReservRes, err
+	}
+
+	if promoCodeReleased {
+		service.performPromoCodeUsageDeleteRequest(reservation.PromoCode, reservation.Uid)
+	}
+
+	models.ReservToReservRes(&changeReservRes.ReservationResponse, &changedReservation, &hotel, &changedPayment)
+	changeReservRes.PreviousPrice = paidAmount
+	changeReservRes.PriceDifference = priceDifference
+	changeReservRes.PromoCodeReleased = promoCodeReleased
+
+	return changeReservRes, nil
+}
+
 func (service *GatewayService) ReadUserLoyalty(
 	username string,
 ) (loyaltyInfoRes models.LoyaltyInfoResponse, err error) {
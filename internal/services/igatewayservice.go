@@ -5,11 +5,30 @@ import (
 )
 
 type IGatewayService interface {
-	ReadAllHotels(int, int) (models.PagiationResponse, error)
+	ReadAllHotels(*models.HotelFilter, string, int, int) (models.PagiationResponse, models.ValidationErrorResponse, error)
+	ReadHotelAvailability(string, string, string) (models.HotelAvailabilityResponse, error)
+	CreateHotel(string, *models.Hotel) (models.Hotel, error)
+	UpdateHotel(string, *models.Hotel) (models.Hotel, error)
+	RetireHotel(string, string) error
+	UpdateCancelPolicy(string, string, *models.CancellationPolicy) (models.CancellationPolicy, error)
+	DeleteCancelPolicy(string, string) error
+	ReadRateRules(string, string) ([]models.RateRule, error)
+	ReplaceRateRules(string, string, []models.RateRule) ([]models.RateRule, error)
+	ReadExchangeRates() ([]models.ExchangeRate, error)
+	ReplaceExchangeRates(string, []models.ExchangeRate) ([]models.ExchangeRate, error)
+	ReadTaxRules(string) ([]models.TaxRule, error)
+	ReplaceTaxRules(string, []models.TaxRule) ([]models.TaxRule, error)
 	ReadUserInfo(string) (models.UserInfoResponse, error)
 	ReadUserReservations(string) ([]models.ReservationResponse, error)
-	CreateReservation(string, *models.CreateReservationRequest) (models.CreateReservationResponse, error)
+	ReadCalendarFeedToken(string) (string, error)
+	ResetCalendarFeedToken(string) (string, error)
+	ReadUserCalendar(string, string) (string, error)
+	CreateReservation(string, string, *models.CreateReservationRequest) (models.CreateReservationResponse, error)
+	ReadPriceQuote(string, *models.CreateReservationRequest) (models.PriceQuoteResponse, error)
 	ReadReservation(string, string) (models.ReservationResponse, error)
+	ReadReservationReceipt(string, string) (models.Receipt, error)
+	ChangeReservationDates(string, string, *models.ChangeReservationRequest) (models.ChangeReservationResponse, error)
 	DeleteReservation(string, string) error
 	ReadUserLoyalty(string) (models.LoyaltyInfoResponse, error)
+	Reconcile(string, bool) (models.ReconcileReport, error)
 }
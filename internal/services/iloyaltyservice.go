@@ -4,6 +4,9 @@ import "github.com/agarmirus/ds-lab02/internal/models"
 
 type ILoyaltyService interface {
 	ReadLoyaltyByUsername(string) (models.Loyalty, error)
+	ReadAllLoyalties() ([]models.Loyalty, error)
 	UpdateLoyaltyById(*models.Loyalty) (models.Loyalty, error)
-	ChangeLoyaltyCountByUsername(string, int) error
+	ChangeLoyaltyCountByUsername(string, string, int) error
+	CorrectLoyaltyCount(string, *models.LoyaltyCountCorrection) (models.Loyalty, error)
+	ReviewLoyaltyTiers(bool) ([]models.LoyaltyTierChange, error)
 }
@@ -0,0 +1,9 @@
+package services
+
+// Payment service provider which actually moves the money. Amounts are in the
+// same units as payment prices.
+type IPaymentProvider interface {
+	Charge(string, int) error
+	Refund(string, int) error
+	Status(string) (string, error)
+}
@@ -4,6 +4,16 @@ import "github.com/agarmirus/ds-lab02/internal/models"
 
 type IPaymentService interface {
 	ReadPaymentByUid(string) (models.Payment, error)
-	UpdatePaymentByUid(*models.Payment) (models.Payment, error)
+	ReadAllPayments() ([]models.Payment, error)
+	ReadPaymentTransactions(string) ([]models.PaymentTransaction, error)
+	ReadInvoice(string) (models.Invoice, error)
+	IssueInvoice(string) (models.Invoice, error)
 	CreatePayment(*models.Payment) (models.Payment, error)
+	CapturePayment(string, int, string) (models.Payment, error)
+	VoidPayment(string, string) (models.Payment, error)
+	RefundPayment(string, int, string) (models.Payment, error)
+	ReadProviderStatus(string) (string, error)
+	ReadLedgerEntries(string) ([]models.LedgerEntry, error)
+	ReadLedgerBalances(string) ([]models.LedgerBalance, error)
+	CheckLedger() (models.LedgerCheckResponse, error)
 }
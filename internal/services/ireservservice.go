@@ -7,11 +7,33 @@ import (
 )
 
 type IReservationService interface {
-	ReadPaginatedHotels(int, int) (list.List, error)
+	ReadPaginatedHotels(*models.HotelFilter, int, int) (models.HotelsPage, error)
+	ReadHotelsAfterCursor(*models.HotelFilter, string, int) (models.HotelsPage, error)
 	ReadHotelById(int) (models.Hotel, error)
 	ReadHotelByUid(string) (models.Hotel, error)
+	ReadHotelAvailability(string, string, string) (models.HotelAvailabilityResponse, error)
+	CreateHotel(*models.Hotel) (models.Hotel, error)
+	UpdateHotel(*models.Hotel) (models.Hotel, error)
+	RetireHotel(string) error
+	UpdateCancelPolicy(string, *models.CancellationPolicy) (models.CancellationPolicy, error)
+	DeleteCancelPolicy(string) error
+	ReadRateRules(string) ([]models.RateRule, error)
+	ReplaceRateRules(string, []models.RateRule) ([]models.RateRule, error)
+	ReadExchangeRates() ([]models.ExchangeRate, error)
+	ReplaceExchangeRates([]models.ExchangeRate) ([]models.ExchangeRate, error)
+	ReadTaxRules() ([]models.TaxRule, error)
+	ReplaceTaxRules([]models.TaxRule) ([]models.TaxRule, error)
+	ReadHotelTaxRules(string) ([]models.TaxRule, error)
+	ReadNightlyPrices(string, string, string) (models.NightlyPriceResponse, error)
 	ReadReservsByUsername(string) (list.List, error)
+	ReadAllReservs() (list.List, error)
 	ReadReservByUid(string) (models.Reservation, error)
 	UpdateReservByUid(*models.Reservation) (models.Reservation, error)
 	CreateReserv(*models.Reservation) (models.Reservation, error)
+	CreatePromoCode(*models.PromoCode) (models.PromoCode, error)
+	ReadApplicablePromoCode(string, string, string, string) (models.PromoCode, error)
+	RedeemPromoCode(*models.PromoCodeUsage) (models.PromoCodeUsage, error)
+	ReleasePromoCode(string) error
+	ReadCalendarFeed(string) (models.CalendarFeed, error)
+	RotateCalendarFeed(string) (models.CalendarFeed, error)
 }
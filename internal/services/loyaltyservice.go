@@ -2,6 +2,9 @@ package services
 
 import (
 	"log"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/agarmirus/ds-lab02/internal/database"
 	"github.com/agarmirus/ds-lab02/internal/models"
@@ -9,13 +12,70 @@ import (
 )
 
 type LoyaltyService struct {
-	loyaltyDAO database.IDAO[models.Loyalty]
+	loyaltyDAO database.ILoyaltyDAO
+	eventsDAO  database.IDAO[models.LoyaltyEvent]
+
+	tierWindowDays int
 }
 
 func NewLoyaltyService(
-	loyaltyDAO database.IDAO[models.Loyalty],
+	loyaltyDAO database.ILoyaltyDAO,
+	eventsDAO database.IDAO[models.LoyaltyEvent],
+	tierWindowDays int,
+	tierJobInterval time.Duration,
+	tierJobDryRun bool,
 ) ILoyaltyService {
-	return &LoyaltyService{loyaltyDAO}
+	service := &LoyaltyService{loyaltyDAO, eventsDAO, tierWindowDays}
+
+	if tierWindowDays > 0 && tierJobInterval > 0 {
+		go service.runTierExpirationJob(tierJobInterval, tierJobDryRun)
+	}
+
+	return service
+}
+
+func (service *LoyaltyService) runTierExpirationJob(interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		changes, err := service.ReviewLoyaltyTiers(dryRun)
+
+		if err != nil {
+			log.Println("[ERROR] LoyaltyService.runTierExpirationJob. ReviewLoyaltyTiers returned error:", err)
+			continue
+		}
+
+		for _, change := range changes {
+			log.Printf(
+				"[INFO] LoyaltyService.runTierExpirationJob. dryRun=%t user=%s status %s -> %s (qualifying reservations: %d)\n",
+				dryRun, change.Username, change.OldStatus, change.NewStatus, change.QualifyingCount,
+			)
+		}
+	}
+}
+
+func (service *LoyaltyService) readQualifyingCount(loyalty *models.Loyalty) (count int, err error) {
+	if service.tierWindowDays <= 0 {
+		return loyalty.ReservationCount, nil
+	}
+
+	eventsLst, err := service.eventsDAO.GetByAttribute(`username`, loyalty.Username)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyService.readQualifyingCount. eventsDAO.GetByAttribute returned error:", err)
+		return count, err
+	}
+
+	windowStart := time.Now().AddDate(0, 0, -service.tierWindowDays)
+
+	for eventsLstEl := eventsLst.Front(); eventsLstEl != nil; eventsLstEl = eventsLstEl.Next() {
+		if eventsLstEl.Value.(models.LoyaltyEvent).CreatedAt.After(windowStart) {
+			count++
+		}
+	}
+
+	return count, nil
 }
 
 func (service *LoyaltyService) ReadLoyaltyByUsername(username string) (loyalty models.Loyalty, err error) {
@@ -34,6 +94,23 @@ func (service *LoyaltyService) ReadLoyaltyByUsername(username string) (loyalty m
 	return loyaltiesLst.Front().Value.(models.Loyalty), nil
 }
 
+func (service *LoyaltyService) ReadAllLoyalties() (loyalties []models.Loyalty, err error) {
+	loyaltiesLst, err := service.loyaltyDAO.Get()
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyService.ReadAllLoyalties. loyaltyDAO.Get returned error:", err)
+		return loyalties, err
+	}
+
+	loyalties = []models.Loyalty{}
+
+	for e := loyaltiesLst.Front(); e != nil; e = e.Next() {
+		loyalties = append(loyalties, e.Value.(models.Loyalty))
+	}
+
+	return loyalties, nil
+}
+
 func (service *LoyaltyService) UpdateLoyaltyById(loyalty *models.Loyalty) (updatedLoyalty models.Loyalty, err error) {
 	updatedLoyalty, err = service.loyaltyDAO.Update(loyalty)
 
@@ -44,29 +121,104 @@ func (service *LoyaltyService) UpdateLoyaltyById(loyalty *models.Loyalty) (updat
 	return updatedLoyalty, err
 }
 
-func (service *LoyaltyService) ChangeLoyaltyCountByUsername(username string, delta int) error {
-	loyaltiesLst, err := service.loyaltyDAO.GetByAttribute(`username`, username)
+// Each reservation is counted once: the count changes together with its event,
+// which keeps the booking date for the rolling window. Reservations booked
+// before events were linked have no event and are corrected by reconciliation.
+func (service *LoyaltyService) ChangeLoyaltyCountByUsername(username string, reservationUid string, delta int) error {
+	if uuid.Validate(reservationUid) != nil {
+		log.Println("[ERROR] LoyaltyService.ChangeLoyaltyCountByUsername. Invalid reservation uid")
+		return serverrors.ErrInvalidReservUid
+	}
+
+	if delta != 1 && delta != -1 {
+		log.Println("[ERROR] LoyaltyService.ChangeLoyaltyCountByUsername. Invalid delta:", delta)
+		return serverrors.ErrInvalidLoyaltyCount
+	}
+
+	_, err := service.loyaltyDAO.ChangeReservationCount(
+		&models.LoyaltyEvent{Username: username, ReservationUid: reservationUid},
+		delta,
+		service.tierWindowDays,
+	)
 
 	if err != nil {
-		log.Println("[ERROR] LoyaltyService.ChangeLoyaltyCountByUsername. loyaltyDAO.GetByAttribute returned error:", err)
-		return err
+		log.Println("[ERROR] LoyaltyService.ChangeLoyaltyCountByUsername. loyaltyDAO.ChangeReservationCount returned error:", err)
 	}
 
-	if loyaltiesLst.Len() == 0 {
-		log.Println("[ERROR] LoyaltyService.ChangeLoyaltyCountByUsername. Entity not found")
-		return serverrors.ErrEntityNotFound
+	return err
+}
+
+// Reconciliation replaces the count without adding or removing events, so the
+// rolling window keeps the real booking dates.
+func (service *LoyaltyService) CorrectLoyaltyCount(
+	username string,
+	correction *models.LoyaltyCountCorrection,
+) (loyalty models.Loyalty, err error) {
+	if correction.ReservationCount < 0 {
+		log.Println("[ERROR] LoyaltyService.CorrectLoyaltyCount. Invalid reservation count")
+		return loyalty, serverrors.ErrInvalidLoyaltyCount
+	}
+
+	loyalty, err = service.loyaltyDAO.CorrectReservationCount(username, correction, service.tierWindowDays)
+
+	if err != nil {
+		log.Println("[ERROR] LoyaltyService.CorrectLoyaltyCount. loyaltyDAO.CorrectReservationCount returned error:", err)
 	}
 
-	desiredLoyalty := loyaltiesLst.Front().Value.(models.Loyalty)
+	return loyalty, err
+}
+
+func (service *LoyaltyService) ReviewLoyaltyTiers(dryRun bool) (changes []models.LoyaltyTierChange, err error) {
+	changes = make([]models.LoyaltyTierChange, 0)
 
-	desiredLoyalty.ReservationCount += delta
-	models.UpdateLoyaltyStatus(&desiredLoyalty)
+	if service.tierWindowDays <= 0 {
+		log.Println("[INFO] LoyaltyService.ReviewLoyaltyTiers. Rolling window is disabled")
+		return changes, nil
+	}
 
-	_, err = service.loyaltyDAO.Update(&desiredLoyalty)
+	loyaltiesLst, err := service.loyaltyDAO.Get()
 
 	if err != nil {
-		log.Println("[ERROR] LoyaltyService.Update. loyaltyDAO.Update returned error:", err)
+		log.Println("[ERROR] LoyaltyService.ReviewLoyaltyTiers. loyaltyDAO.Get returned error:", err)
+		return changes, err
 	}
 
-	return err
+	for loyaltiesLstEl := loyaltiesLst.Front(); loyaltiesLstEl != nil; loyaltiesLstEl = loyaltiesLstEl.Next() {
+		loyalty := loyaltiesLstEl.Value.(models.Loyalty)
+
+		qualifyingCount, err := service.readQualifyingCount(&loyalty)
+
+		if err != nil {
+			log.Println("[ERROR] LoyaltyService.ReviewLoyaltyTiers. readQualifyingCount returned error:", err)
+			return changes, err
+		}
+
+		updatedLoyalty := loyalty
+		models.UpdateLoyaltyStatusByCount(&updatedLoyalty, qualifyingCount)
+
+		if updatedLoyalty.Discount >= loyalty.Discount {
+			continue
+		}
+
+		if !dryRun {
+			_, err = service.loyaltyDAO.Update(&updatedLoyalty)
+
+			if err != nil {
+				log.Println("[ERROR] LoyaltyService.ReviewLoyaltyTiers. loyaltyDAO.Update returned error:", err)
+				return changes, err
+			}
+		}
+
+		changes = append(changes, models.LoyaltyTierChange{
+			Username:         loyalty.Username,
+			ReservationCount: loyalty.ReservationCount,
+			QualifyingCount:  qualifyingCount,
+			OldStatus:        loyalty.Status,
+			NewStatus:        updatedLoyalty.Status,
+			OldDiscount:      loyalty.Discount,
+			NewDiscount:      updatedLoyalty.Discount,
+		})
+	}
+
+	return changes, nil
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"log"
 
 	"github.com/agarmirus/ds-lab02/internal/database"
@@ -9,13 +10,20 @@ import (
 )
 
 type PaymentService struct {
-	paymentDAO database.IDAO[models.Payment]
+	paymentDAO database.IPaymentDAO
+	ledgerDAO  database.ILedgerDAO
+	provider   IPaymentProvider
+
+	platformFeePercent int
 }
 
 func NewPaymentService(
-	paymentDAO database.IDAO[models.Payment],
+	paymentDAO database.IPaymentDAO,
+	ledgerDAO database.ILedgerDAO,
+	provider IPaymentProvider,
+	platformFeePercent int,
 ) IPaymentService {
-	return &PaymentService{paymentDAO}
+	return &PaymentService{paymentDAO, ledgerDAO, provider, platformFeePercent}
 }
 
 func (service *PaymentService) ReadPaymentByUid(paymentUid string) (payment models.Payment, err error) {
@@ -34,22 +42,337 @@ func (service *PaymentService) ReadPaymentByUid(paymentUid string) (payment mode
 	return paymentsLst.Front().Value.(models.Payment), nil
 }
 
-func (service *PaymentService) UpdatePaymentByUid(payment *models.Payment) (newPayment models.Payment, err error) {
-	newPayment, err = service.paymentDAO.Update(payment)
+func (service *PaymentService) ReadAllPayments() (payments []models.Payment, err error) {
+	paymentsLst, err := service.paymentDAO.Get()
 
 	if err != nil {
-		log.Println("[ERROR] PaymentService.UpdatePaymentByUid. paymentDAO.Update returned error:", err)
+		log.Println("[ERROR] PaymentService.ReadAllPayments. paymentDAO.Get returned error:", err)
+		return payments, err
 	}
 
-	return newPayment, err
+	payments = []models.Payment{}
+
+	for e := paymentsLst.Front(); e != nil; e = e.Next() {
+		payments = append(payments, e.Value.(models.Payment))
+	}
+
+	return payments, nil
+}
+
+func (service *PaymentService) ReadPaymentTransactions(paymentUid string) (transactions []models.PaymentTransaction, err error) {
+	_, err = service.ReadPaymentByUid(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadPaymentTransactions. ReadPaymentByUid returned error:", err)
+		return transactions, err
+	}
+
+	transactionsLst, err := service.paymentDAO.GetTransactions(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadPaymentTransactions. paymentDAO.GetTransactions returned error:", err)
+		return transactions, err
+	}
+
+	transactions = []models.PaymentTransaction{}
+
+	for e := transactionsLst.Front(); e != nil; e = e.Next() {
+		transactions = append(transactions, e.Value.(models.PaymentTransaction))
+	}
+
+	return transactions, nil
 }
 
+func (service *PaymentService) ReadInvoice(paymentUid string) (invoice models.Invoice, err error) {
+	invoice, err = service.paymentDAO.GetInvoice(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadInvoice. paymentDAO.GetInvoice returned error:", err)
+	}
+
+	return invoice, err
+}
+
+// Issuing is idempotent: a payment which already has an invoice keeps its number.
+// Payments which were never charged get no invoice.
+func (service *PaymentService) IssueInvoice(paymentUid string) (invoice models.Invoice, err error) {
+	invoice, err = service.paymentDAO.IssueInvoice(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.IssueInvoice. paymentDAO.IssueInvoice returned error:", err)
+	}
+
+	return invoice, err
+}
+
+func (service *PaymentService) readPaymentByIdempotencyKey(key string) (payment models.Payment, found bool, err error) {
+	paymentsLst, err := service.paymentDAO.GetByAttribute(`idempotency_key`, key)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.readPaymentByIdempotencyKey. paymentDAO.GetByAttribute returned error:", err)
+		return payment, false, err
+	}
+
+	if paymentsLst.Len() == 0 {
+		return payment, false, nil
+	}
+
+	return paymentsLst.Front().Value.(models.Payment), true, nil
+}
+
+// Replaying a request with a known idempotency key returns the payment created
+// by the first one instead of authorizing the amount again.
+func (service *PaymentService) replayPayment(payment *models.Payment) (storedPayment models.Payment, found bool, err error) {
+	storedPayment, found, err = service.readPaymentByIdempotencyKey(payment.IdempotencyKey)
+
+	if err != nil || !found {
+		return storedPayment, found, err
+	}
+
+	if !models.IsSamePaymentRequest(&storedPayment, payment) {
+		log.Println("[ERROR] PaymentService.replayPayment. Idempotency key was reused:", payment.IdempotencyKey)
+		return models.Payment{}, true, serverrors.ErrIdempotencyKeyReused
+	}
+
+	log.Println("[INFO] PaymentService.replayPayment. Returning payment", storedPayment.Uid, "for idempotency key", payment.IdempotencyKey)
+
+	return storedPayment, true, nil
+}
+
+// New payments are only authorized; the amount is charged by a later capture.
 func (service *PaymentService) CreatePayment(payment *models.Payment) (newPayment models.Payment, err error) {
+	payment.Status = models.PaymentStatusAuthorized
+	payment.RefundedAmount = 0
+
+	if payment.IdempotencyKey != `` {
+		storedPayment, found, err := service.replayPayment(payment)
+
+		if err != nil || found {
+			return storedPayment, err
+		}
+	}
+
 	newPayment, err = service.paymentDAO.Create(payment)
 
 	if err != nil {
 		log.Println("[ERROR] PaymentService.CreatePayment. paymentDAO.Create returned error:", err)
+
+		// A concurrent request with the same key may have won the unique constraint.
+		if payment.IdempotencyKey != `` && errors.Is(err, serverrors.ErrEntityInsert) {
+			storedPayment, found, replayErr := service.replayPayment(payment)
+
+			if found {
+				return storedPayment, replayErr
+			}
+		}
 	}
 
 	return newPayment, err
 }
+
+// The provider is called after the transaction is checked against the payment
+// and recorded as pending, so operations which cannot be applied, including
+// concurrent ones, never reach it. When the transaction cannot be completed
+// after the provider call, the provider operation is reversed.
+func (service *PaymentService) applyTransaction(
+	paymentUid string,
+	kind string,
+	amount int,
+	idempotencyKey string,
+	providerCall func(string, int) error,
+) (updatedPayment models.Payment, err error) {
+	transaction := models.PaymentTransaction{
+		PaymentUid:     paymentUid,
+		Kind:           kind,
+		Amount:         amount,
+		IdempotencyKey: idempotencyKey,
+	}
+	providerCalled := false
+
+	beforeComplete := func(transaction *models.PaymentTransaction) error {
+		transaction.Entries = models.LedgerEntriesForTransaction(transaction, service.platformFeePercent)
+
+		if providerCall == nil {
+			return nil
+		}
+
+		err := providerCall(paymentUid, transaction.Amount)
+
+		if err != nil {
+			log.Println("[ERROR] PaymentService.applyTransaction. Payment provider returned error:", err)
+			return err
+		}
+
+		providerCalled = true
+
+		return nil
+	}
+
+	updatedPayment, err = service.paymentDAO.ApplyTransaction(paymentUid, &transaction, beforeComplete)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.applyTransaction. paymentDAO.ApplyTransaction returned error:", err)
+
+		if providerCalled {
+			service.reverseProviderCall(paymentUid, kind, transaction.Amount)
+		}
+	}
+
+	return updatedPayment, err
+}
+
+func (service *PaymentService) reverseProviderCall(paymentUid string, kind string, amount int) {
+	var err error
+
+	switch kind {
+	case models.PaymentTransactionCapture:
+		err = service.provider.Refund(paymentUid, amount)
+	case models.PaymentTransactionRefund:
+		err = service.provider.Charge(paymentUid, amount)
+	}
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.reverseProviderCall. Compensation of", kind, "failed for payment", paymentUid, ":", err)
+	}
+}
+
+func (service *PaymentService) CapturePayment(
+	paymentUid string,
+	amount int,
+	idempotencyKey string,
+) (payment models.Payment, err error) {
+	payment, err = service.applyTransaction(
+		paymentUid, models.PaymentTransactionCapture,
+		amount, idempotencyKey, service.provider.Charge,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.CapturePayment. applyTransaction returned error:", err)
+	}
+
+	return payment, err
+}
+
+// Authorizations are kept by the payment service only, so voiding one does not involve the provider.
+func (service *PaymentService) VoidPayment(paymentUid string, idempotencyKey string) (payment models.Payment, err error) {
+	payment, err = service.applyTransaction(paymentUid, models.PaymentTransactionVoid, 0, idempotencyKey, nil)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.VoidPayment. applyTransaction returned error:", err)
+	}
+
+	return payment, err
+}
+
+func (service *PaymentService) RefundPayment(
+	paymentUid string,
+	amount int,
+	idempotencyKey string,
+) (payment models.Payment, err error) {
+	payment, err = service.applyTransaction(
+		paymentUid, models.PaymentTransactionRefund,
+		amount, idempotencyKey, service.provider.Refund,
+	)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.RefundPayment. applyTransaction returned error:", err)
+	}
+
+	return payment, err
+}
+
+func (service *PaymentService) ReadProviderStatus(paymentUid string) (status string, err error) {
+	_, err = service.ReadPaymentByUid(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadProviderStatus. ReadPaymentByUid returned error:", err)
+		return status, err
+	}
+
+	status, err = service.provider.Status(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadProviderStatus. provider.Status returned error:", err)
+	}
+
+	return status, err
+}
+
+func (service *PaymentService) ReadLedgerEntries(paymentUid string) (entries []models.LedgerEntry, err error) {
+	_, err = service.ReadPaymentByUid(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadLedgerEntries. ReadPaymentByUid returned error:", err)
+		return entries, err
+	}
+
+	entriesLst, err := service.ledgerDAO.GetByAttribute(`p.payment_uid`, paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadLedgerEntries. ledgerDAO.GetByAttribute returned error:", err)
+		return entries, err
+	}
+
+	entries = []models.LedgerEntry{}
+
+	for e := entriesLst.Front(); e != nil; e = e.Next() {
+		entries = append(entries, e.Value.(models.LedgerEntry))
+	}
+
+	return entries, nil
+}
+
+// Every account is reported, including the ones without entries yet.
+func (service *PaymentService) ReadLedgerBalances(paymentUid string) (balances []models.LedgerBalance, err error) {
+	if paymentUid != `` {
+		_, err = service.ReadPaymentByUid(paymentUid)
+
+		if err != nil {
+			log.Println("[ERROR] PaymentService.ReadLedgerBalances. ReadPaymentByUid returned error:", err)
+			return balances, err
+		}
+	}
+
+	balancesLst, err := service.ledgerDAO.GetBalances(paymentUid)
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.ReadLedgerBalances. ledgerDAO.GetBalances returned error:", err)
+		return balances, err
+	}
+
+	balancesByAccount := map[string]models.LedgerBalance{}
+
+	for e := balancesLst.Front(); e != nil; e = e.Next() {
+		balance := e.Value.(models.LedgerBalance)
+		balancesByAccount[balance.Account] = balance
+	}
+
+	balances = []models.LedgerBalance{}
+
+	for _, account := range models.LedgerAccounts {
+		balance, found := balancesByAccount[account]
+
+		if !found {
+			balance = models.LedgerBalance{Account: account}
+		}
+
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
+
+func (service *PaymentService) CheckLedger() (checkRes models.LedgerCheckResponse, err error) {
+	checkRes, err = service.ledgerDAO.Check()
+
+	if err != nil {
+		log.Println("[ERROR] PaymentService.CheckLedger. ledgerDAO.Check returned error:", err)
+		return checkRes, err
+	}
+
+	if !checkRes.Balanced {
+		log.Println("[ERROR] PaymentService.CheckLedger. Ledger invariant is violated:", checkRes)
+	}
+
+	return checkRes, nil
+}
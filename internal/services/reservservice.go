@@ -3,40 +3,194 @@ package services
 import (
 	"container/list"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/agarmirus/ds-lab02/internal/database"
 	"github.com/agarmirus/ds-lab02/internal/models"
 	"github.com/agarmirus/ds-lab02/internal/serverrors"
+	"github.com/google/uuid"
 )
 
 type ReservationService struct {
-	reservsDAO database.IDAO[models.Reservation]
-	hotelsDAO  database.IDAO[models.Hotel]
+	reservsDAO    database.IReservationDAO
+	hotelsDAO     database.IHotelDAO
+	promoCodesDAO database.IDAO[models.PromoCode]
+	promoUsageDAO database.IDAO[models.PromoCodeUsage]
+	policiesDAO   database.IDAO[models.CancellationPolicy]
+	rateRulesDAO  database.IRateRuleDAO
+	ratesDAO      database.IExchangeRateDAO
+	taxRulesDAO   database.ITaxRuleDAO
+	feedsDAO      database.ICalendarFeedDAO
+
+	holdTtl time.Duration
 }
 
 func NewReservationService(
-	reservsDAO database.IDAO[models.Reservation],
-	hotelsDAO database.IDAO[models.Hotel],
+	reservsDAO database.IReservationDAO,
+	hotelsDAO database.IHotelDAO,
+	promoCodesDAO database.IDAO[models.PromoCode],
+	promoUsageDAO database.IDAO[models.PromoCodeUsage],
+	policiesDAO database.IDAO[models.CancellationPolicy],
+	rateRulesDAO database.IRateRuleDAO,
+	ratesDAO database.IExchangeRateDAO,
+	taxRulesDAO database.ITaxRuleDAO,
+	feedsDAO database.ICalendarFeedDAO,
+	holdTtl time.Duration,
+	holdSweepInterval time.Duration,
 ) IReservationService {
-	return &ReservationService{reservsDAO, hotelsDAO}
+	service := &ReservationService{
+		reservsDAO,
+		hotelsDAO,
+		promoCodesDAO,
+		promoUsageDAO,
+		policiesDAO,
+		rateRulesDAO,
+		ratesDAO,
+		taxRulesDAO,
+		feedsDAO,
+		holdTtl,
+	}
+
+	if holdSweepInterval > 0 {
+		go service.expireHolds(holdSweepInterval)
+	}
+
+	return service
+}
+
+func (service *ReservationService) expireHolds(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		expiredLst, err := service.reservsDAO.ExpireHolds()
+
+		if err != nil {
+			log.Println("[ERROR] ReservationService.expireHolds. reservsDAO.ExpireHolds returned error:", err)
+			continue
+		}
+
+		for expiredLstEl := expiredLst.Front(); expiredLstEl != nil; expiredLstEl = expiredLstEl.Next() {
+			reservUid := expiredLstEl.Value.(models.Reservation).Uid
+			log.Println("[INFO] ReservationService.expireHolds. Hold expired for reservation", reservUid)
+
+			service.ReleasePromoCode(reservUid)
+		}
+	}
+}
+
+func hotelsLstToSlice(hotelsLst *list.List) []models.Hotel {
+	hotelsSlice := make([]models.Hotel, 0)
+
+	for hotelsLstEl := hotelsLst.Front(); hotelsLstEl != nil; hotelsLstEl = hotelsLstEl.Next() {
+		hotelsSlice = append(hotelsSlice, hotelsLstEl.Value.(models.Hotel))
+	}
+
+	return hotelsSlice
 }
 
 func (service *ReservationService) ReadPaginatedHotels(
+	filter *models.HotelFilter,
 	page int,
 	pageSize int,
-) (hotelsLst list.List, err error) {
+) (hotelsPage models.HotelsPage, err error) {
 	if page <= 0 || pageSize <= 0 {
 		log.Println("[ERROR] ReservationService.ReadPaginatedHotels. Invalid page and pageSize values")
-		return hotelsLst, serverrors.ErrInvalidPagesData
+		return hotelsPage, serverrors.ErrInvalidPagesData
+	}
+
+	hotelsLst, err := service.hotelsDAO.GetFiltered(filter, page, pageSize)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadPaginatedHotels. hotelsDAO.GetFiltered returned error:", err)
+		return hotelsPage, err
 	}
 
-	hotelsLst, err = service.hotelsDAO.GetPaginated(page, pageSize)
+	hotelsPage.TotalElements, err = service.hotelsDAO.CountFiltered(filter)
 
 	if err != nil {
-		log.Println("[ERROR] ReservationService.ReadPaginatedHotels. hotelsDAO.GetPaginated returned error:", err)
+		log.Println("[ERROR] ReservationService.ReadPaginatedHotels. hotelsDAO.CountFiltered returned error:", err)
+		return hotelsPage, err
+	}
+
+	hotelsPage.Items = hotelsLstToSlice(&hotelsLst)
+	itemsCount := len(hotelsPage.Items)
+
+	if itemsCount != 0 {
+		if page > 1 {
+			hotelsPage.PrevCursor = models.EncodeHotelCursor(&hotelsPage.Items[0], filter, true)
+		}
+
+		if (page-1)*pageSize+itemsCount < hotelsPage.TotalElements {
+			hotelsPage.NextCursor = models.EncodeHotelCursor(&hotelsPage.Items[itemsCount-1], filter, false)
+		}
 	}
 
-	return hotelsLst, err
+	return hotelsPage, nil
+}
+
+func (service *ReservationService) ReadHotelsAfterCursor(
+	filter *models.HotelFilter,
+	cursorStr string,
+	pageSize int,
+) (hotelsPage models.HotelsPage, err error) {
+	if pageSize <= 0 {
+		log.Println("[ERROR] ReservationService.ReadHotelsAfterCursor. Invalid pageSize value")
+		return hotelsPage, serverrors.ErrInvalidPagesData
+	}
+
+	var cursor *models.HotelCursor
+
+	if cursorStr != `` {
+		decodedCursor, err := models.DecodeHotelCursor(cursorStr, filter)
+
+		if err != nil {
+			log.Println("[ERROR] ReservationService.ReadHotelsAfterCursor. Invalid cursor:", err)
+			return hotelsPage, err
+		}
+
+		cursor = &decodedCursor
+	}
+
+	hotelsLst, err := service.hotelsDAO.GetFilteredAfter(filter, cursor, pageSize+1)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelsAfterCursor. hotelsDAO.GetFilteredAfter returned error:", err)
+		return hotelsPage, err
+	}
+
+	hotelsPage.TotalElements, err = service.hotelsDAO.CountFiltered(filter)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelsAfterCursor. hotelsDAO.CountFiltered returned error:", err)
+		return hotelsPage, err
+	}
+
+	backward := cursor != nil && cursor.Backward
+	hasMore := hotelsLst.Len() > pageSize
+
+	if hasMore {
+		if backward {
+			hotelsLst.Remove(hotelsLst.Front())
+		} else {
+			hotelsLst.Remove(hotelsLst.Back())
+		}
+	}
+
+	hotelsPage.Items = hotelsLstToSlice(&hotelsLst)
+	itemsCount := len(hotelsPage.Items)
+
+	if itemsCount != 0 {
+		if (backward && hasMore) || (!backward && cursor != nil) {
+			hotelsPage.PrevCursor = models.EncodeHotelCursor(&hotelsPage.Items[0], filter, true)
+		}
+
+		if (!backward && hasMore) || backward {
+			hotelsPage.NextCursor = models.EncodeHotelCursor(&hotelsPage.Items[itemsCount-1], filter, false)
+		}
+	}
+
+	return hotelsPage, nil
 }
 
 func (service *ReservationService) ReadHotelById(hotelId int) (hotel models.Hotel, err error) {
@@ -45,8 +199,11 @@ func (service *ReservationService) ReadHotelById(hotelId int) (hotel models.Hote
 
 	if err != nil {
 		log.Println("[ERROR] ReservationService.ReadHotelById. hotelsDAO.GetById returned error:", err)
+		return hotel, err
 	}
 
+	err = service.attachCancelPolicy(&hotel)
+
 	return hotel, err
 }
 
@@ -63,7 +220,347 @@ func (service *ReservationService) ReadHotelByUid(hotelUid string) (hotel models
 		return hotel, serverrors.ErrEntityNotFound
 	}
 
-	return hotelsLst.Front().Value.(models.Hotel), nil
+	hotel = hotelsLst.Front().Value.(models.Hotel)
+	err = service.attachCancelPolicy(&hotel)
+
+	return hotel, err
+}
+
+func (service *ReservationService) attachCancelPolicy(hotel *models.Hotel) error {
+	policiesLst, err := service.policiesDAO.GetByAttribute(`hotel_id`, strconv.Itoa(hotel.Id))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.attachCancelPolicy. policiesDAO.GetByAttribute returned error:", err)
+		return err
+	}
+
+	if policiesLst.Len() != 0 {
+		policy := policiesLst.Front().Value.(models.CancellationPolicy)
+		hotel.CancellationPolicy = &policy
+	}
+
+	return nil
+}
+
+func (service *ReservationService) UpdateCancelPolicy(
+	hotelUid string,
+	policy *models.CancellationPolicy,
+) (updatedPolicy models.CancellationPolicy, err error) {
+	_, err = models.ValidateCancellationPolicy(policy)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.UpdateCancelPolicy. Invalid cancellation policy:", err)
+		return updatedPolicy, err
+	}
+
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.UpdateCancelPolicy. ReadHotelByUid returned error:", err)
+		return updatedPolicy, err
+	}
+
+	policy.HotelId = hotel.Id
+	updatedPolicy, err = service.policiesDAO.Update(policy)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.UpdateCancelPolicy. policiesDAO.Update returned error:", err)
+	}
+
+	return updatedPolicy, err
+}
+
+func (service *ReservationService) DeleteCancelPolicy(hotelUid string) (err error) {
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.DeleteCancelPolicy. ReadHotelByUid returned error:", err)
+		return err
+	}
+
+	err = service.policiesDAO.DeleteByAttr(`hotel_id`, strconv.Itoa(hotel.Id))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.DeleteCancelPolicy. policiesDAO.DeleteByAttr returned error:", err)
+	}
+
+	return err
+}
+
+func (service *ReservationService) ReadHotelAvailability(
+	hotelUid string,
+	from string,
+	to string,
+) (availabilityRes models.HotelAvailabilityResponse, err error) {
+	_, err = models.ValidateAvailabilityRange(from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelAvailability. Invalid availability range:", err)
+		return availabilityRes, err
+	}
+
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelAvailability. ReadHotelByUid returned error:", err)
+		return availabilityRes, err
+	}
+
+	nightsLst, err := service.hotelsDAO.GetAvailability(&hotel, from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelAvailability. hotelsDAO.GetAvailability returned error:", err)
+		return availabilityRes, err
+	}
+
+	rules, err := service.readRateRules(hotel.Id)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelAvailability. readRateRules returned error:", err)
+		return availabilityRes, err
+	}
+
+	nightlyPrices := models.NightlyRates(hotel.Price, rules, from, to)
+
+	availabilityRes.HotelUid = hotel.Uid
+	availabilityRes.From = from
+	availabilityRes.To = to
+	availabilityRes.Currency = models.NormalizeCurrency(hotel.Currency)
+	availabilityRes.Nights = make([]models.NightAvailability, 0)
+
+	for nightsLstEl := nightsLst.Front(); nightsLstEl != nil; nightsLstEl = nightsLstEl.Next() {
+		nightAvailability := nightsLstEl.Value.(models.NightAvailability)
+
+		for _, nightlyPrice := range nightlyPrices {
+			if nightlyPrice.Date == nightAvailability.Date {
+				nightAvailability.Price = nightlyPrice.Price
+				break
+			}
+		}
+
+		availabilityRes.Nights = append(availabilityRes.Nights, nightAvailability)
+	}
+
+	return availabilityRes, nil
+}
+
+func (service *ReservationService) readRateRules(hotelId int) (rules []models.RateRule, err error) {
+	rulesLst, err := service.rateRulesDAO.GetByAttribute(`hotel_id`, strconv.Itoa(hotelId))
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.readRateRules. rateRulesDAO.GetByAttribute returned error:", err)
+		return rules, err
+	}
+
+	rules = make([]models.RateRule, 0)
+
+	for rulesLstEl := rulesLst.Front(); rulesLstEl != nil; rulesLstEl = rulesLstEl.Next() {
+		rules = append(rules, rulesLstEl.Value.(models.RateRule))
+	}
+
+	return rules, nil
+}
+
+func (service *ReservationService) ReadRateRules(hotelUid string) (rules []models.RateRule, err error) {
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadRateRules. ReadHotelByUid returned error:", err)
+		return rules, err
+	}
+
+	return service.readRateRules(hotel.Id)
+}
+
+func (service *ReservationService) ReplaceRateRules(
+	hotelUid string,
+	rules []models.RateRule,
+) (newRules []models.RateRule, err error) {
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReplaceRateRules. ReadHotelByUid returned error:", err)
+		return newRules, err
+	}
+
+	rulesLst, err := service.rateRulesDAO.ReplaceByHotel(hotel.Id, rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReplaceRateRules. rateRulesDAO.ReplaceByHotel returned error:", err)
+		return newRules, err
+	}
+
+	newRules = make([]models.RateRule, 0)
+
+	for rulesLstEl := rulesLst.Front(); rulesLstEl != nil; rulesLstEl = rulesLstEl.Next() {
+		newRules = append(newRules, rulesLstEl.Value.(models.RateRule))
+	}
+
+	return newRules, nil
+}
+
+func ratesLstToSlice(ratesLst *list.List) []models.ExchangeRate {
+	ratesSlice := make([]models.ExchangeRate, 0)
+
+	for ratesLstEl := ratesLst.Front(); ratesLstEl != nil; ratesLstEl = ratesLstEl.Next() {
+		ratesSlice = append(ratesSlice, ratesLstEl.Value.(models.ExchangeRate))
+	}
+
+	return ratesSlice
+}
+
+func (service *ReservationService) ReadExchangeRates() (rates []models.ExchangeRate, err error) {
+	ratesLst, err := service.ratesDAO.Get()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadExchangeRates. ratesDAO.Get returned error:", err)
+		return rates, err
+	}
+
+	return ratesLstToSlice(&ratesLst), nil
+}
+
+func (service *ReservationService) ReplaceExchangeRates(
+	rates []models.ExchangeRate,
+) (newRates []models.ExchangeRate, err error) {
+	ratesLst, err := service.ratesDAO.ReplaceAll(rates)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReplaceExchangeRates. ratesDAO.ReplaceAll returned error:", err)
+		return newRates, err
+	}
+
+	return ratesLstToSlice(&ratesLst), nil
+}
+
+func taxRulesLstToSlice(rulesLst *list.List) []models.TaxRule {
+	rulesSlice := make([]models.TaxRule, 0)
+
+	for rulesLstEl := rulesLst.Front(); rulesLstEl != nil; rulesLstEl = rulesLstEl.Next() {
+		rulesSlice = append(rulesSlice, rulesLstEl.Value.(models.TaxRule))
+	}
+
+	return rulesSlice
+}
+
+func (service *ReservationService) ReadTaxRules() (rules []models.TaxRule, err error) {
+	rulesLst, err := service.taxRulesDAO.Get()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadTaxRules. taxRulesDAO.Get returned error:", err)
+		return rules, err
+	}
+
+	return taxRulesLstToSlice(&rulesLst), nil
+}
+
+func (service *ReservationService) ReplaceTaxRules(rules []models.TaxRule) (newRules []models.TaxRule, err error) {
+	rulesLst, err := service.taxRulesDAO.ReplaceAll(rules)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReplaceTaxRules. taxRulesDAO.ReplaceAll returned error:", err)
+		return newRules, err
+	}
+
+	return taxRulesLstToSlice(&rulesLst), nil
+}
+
+func (service *ReservationService) ReadHotelTaxRules(hotelUid string) (rules []models.TaxRule, err error) {
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelTaxRules. ReadHotelByUid returned error:", err)
+		return rules, err
+	}
+
+	rules, err = service.ReadTaxRules()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadHotelTaxRules. ReadTaxRules returned error:", err)
+		return rules, err
+	}
+
+	return models.SelectTaxRules(rules, hotel.Country, hotel.City), nil
+}
+
+func (service *ReservationService) ReadNightlyPrices(
+	hotelUid string,
+	from string,
+	to string,
+) (priceRes models.NightlyPriceResponse, err error) {
+	_, err = models.ValidateAvailabilityRange(from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadNightlyPrices. Invalid dates range:", err)
+		return priceRes, err
+	}
+
+	hotel, err := service.ReadHotelByUid(hotelUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadNightlyPrices. ReadHotelByUid returned error:", err)
+		return priceRes, err
+	}
+
+	rules, err := service.readRateRules(hotel.Id)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadNightlyPrices. readRateRules returned error:", err)
+		return priceRes, err
+	}
+
+	priceRes, err = models.ComputeNightlyPrices(&hotel, rules, from, to)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadNightlyPrices. ComputeNightlyPrices returned error:", err)
+	}
+
+	return priceRes, err
+}
+
+func (service *ReservationService) CreateHotel(hotel *models.Hotel) (newHotel models.Hotel, err error) {
+	_, err = models.ValidateHotel(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.CreateHotel. Invalid hotel data:", err)
+		return newHotel, err
+	}
+
+	hotel.Uid = uuid.New().String()
+	newHotel, err = service.hotelsDAO.Create(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.CreateHotel. hotelsDAO.Create returned error:", err)
+	}
+
+	return newHotel, err
+}
+
+func (service *ReservationService) UpdateHotel(hotel *models.Hotel) (updatedHotel models.Hotel, err error) {
+	_, err = models.ValidateHotel(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.UpdateHotel. Invalid hotel data:", err)
+		return updatedHotel, err
+	}
+
+	updatedHotel, err = service.hotelsDAO.Update(hotel)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.UpdateHotel. hotelsDAO.Update returned error:", err)
+	}
+
+	return updatedHotel, err
+}
+
+func (service *ReservationService) RetireHotel(hotelUid string) (err error) {
+	err = service.hotelsDAO.Delete(&models.Hotel{Uid: hotelUid})
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.RetireHotel. hotelsDAO.Delete returned error:", err)
+	}
+
+	return err
 }
 
 func (service *ReservationService) ReadReservsByUsername(username string) (reservsLst list.List, err error) {
@@ -82,6 +579,16 @@ func (service *ReservationService) ReadReservsByUsername(username string) (reser
 	return reservsLst, nil
 }
 
+func (service *ReservationService) ReadAllReservs() (reservsLst list.List, err error) {
+	reservsLst, err = service.reservsDAO.Get()
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadAllReservs. reservsDAO.Get returned error:", err)
+	}
+
+	return reservsLst, err
+}
+
 func (service *ReservationService) ReadReservByUid(reservUid string) (reservation models.Reservation, err error) {
 	reservsLst, err := service.reservsDAO.GetByAttribute(`reservation_uid`, reservUid)
 
@@ -103,12 +610,28 @@ func (service *ReservationService) UpdateReservByUid(reservation *models.Reserva
 
 	if err != nil {
 		log.Println("[ERROR] ReservationService.UpdateReservByUid. reservsDAO.Update returned error:", err)
+		return updatedReservation, err
 	}
 
-	return updatedReservation, err
+	if updatedReservation.Status == models.ReservStatusCanceled {
+		service.ReleasePromoCode(updatedReservation.Uid)
+	}
+
+	return updatedReservation, nil
 }
 
 func (service *ReservationService) CreateReserv(reservation *models.Reservation) (newReservation models.Reservation, err error) {
+	if reservation.Status != models.ReservStatusPending && reservation.Status != models.ReservStatusPaid {
+		log.Println("[ERROR] ReservationService.CreateReserv. Invalid initial status:", reservation.Status)
+		return newReservation, serverrors.ErrInvalidReservStatus
+	}
+
+	reservation.HoldExpiresAt = ``
+
+	if reservation.Status == models.ReservStatusPending {
+		reservation.HoldExpiresAt = time.Now().Add(service.holdTtl).UTC().Format(time.RFC3339)
+	}
+
 	newReservation, err = service.reservsDAO.Create(reservation)
 
 	if err != nil {
@@ -117,3 +640,151 @@ func (service *ReservationService) CreateReserv(reservation *models.Reservation)
 
 	return newReservation, err
 }
+
+func (service *ReservationService) CreatePromoCode(promoCode *models.PromoCode) (newPromoCode models.PromoCode, err error) {
+	newPromoCode, err = service.promoCodesDAO.Create(promoCode)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.CreatePromoCode. promoCodesDAO.Create returned error:", err)
+	}
+
+	return newPromoCode, err
+}
+
+// A usage recorded for reservUid does not count against the caps, so the
+// reservation keeps its promo code when it is repriced.
+func (service *ReservationService) ReadApplicablePromoCode(
+	code string,
+	username string,
+	hotelUid string,
+	reservUid string,
+) (promoCode models.PromoCode, err error) {
+	promoCodesLst, err := service.promoCodesDAO.GetByAttribute(`code`, code)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. promoCodesDAO.GetByAttribute returned error:", err)
+		return promoCode, err
+	}
+
+	if promoCodesLst.Len() == 0 {
+		log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. Entity not found")
+		return promoCode, serverrors.ErrEntityNotFound
+	}
+
+	promoCode = promoCodesLst.Front().Value.(models.PromoCode)
+
+	today := time.Now().Format(time.DateOnly)
+
+	if today < promoCode.ValidFrom || today > promoCode.ValidTo {
+		log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. Promo code is out of its validity period")
+		return promoCode, serverrors.ErrPromoCodeExpired
+	}
+
+	ownUsedCount := 0
+
+	if reservUid != `` {
+		ownUsagesLst, err := service.promoUsageDAO.GetByAttribute(`reservation_uid`, reservUid)
+
+		if err != nil {
+			log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. promoUsageDAO.GetByAttribute returned error:", err)
+			return promoCode, err
+		}
+
+		for ownUsagesLstEl := ownUsagesLst.Front(); ownUsagesLstEl != nil; ownUsagesLstEl = ownUsagesLstEl.Next() {
+			if ownUsagesLstEl.Value.(models.PromoCodeUsage).PromoCodeId == promoCode.Id {
+				ownUsedCount++
+			}
+		}
+	}
+
+	if promoCode.MaxUses > 0 && promoCode.UsedCount-ownUsedCount >= promoCode.MaxUses {
+		log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. Promo code usage limit is reached")
+		return promoCode, serverrors.ErrPromoCodeExhausted
+	}
+
+	if promoCode.MaxUsesPerUser > 0 {
+		usagesLst, err := service.promoUsageDAO.GetByAttribute(`username`, username)
+
+		if err != nil {
+			log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. promoUsageDAO.GetByAttribute returned error:", err)
+			return promoCode, err
+		}
+
+		userUsedCount := 0
+
+		for usagesLstEl := usagesLst.Front(); usagesLstEl != nil; usagesLstEl = usagesLstEl.Next() {
+			if usagesLstEl.Value.(models.PromoCodeUsage).PromoCodeId == promoCode.Id {
+				userUsedCount++
+			}
+		}
+
+		if userUsedCount-ownUsedCount >= promoCode.MaxUsesPerUser {
+			log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. Promo code usage limit per user is reached")
+			return promoCode, serverrors.ErrPromoCodeExhausted
+		}
+	}
+
+	if promoCode.HotelId != 0 || promoCode.City != `` {
+		hotel, err := service.ReadHotelByUid(hotelUid)
+
+		if err != nil {
+			log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. ReadHotelByUid returned error:", err)
+			return promoCode, err
+		}
+
+		if (promoCode.HotelId != 0 && promoCode.HotelId != hotel.Id) ||
+			(promoCode.City != `` && promoCode.City != hotel.City) {
+			log.Println("[ERROR] ReservationService.ReadApplicablePromoCode. Promo code is not applicable to the hotel")
+			return promoCode, serverrors.ErrPromoCodeNotApplicable
+		}
+	}
+
+	return promoCode, nil
+}
+
+func (service *ReservationService) RedeemPromoCode(usage *models.PromoCodeUsage) (newUsage models.PromoCodeUsage, err error) {
+	newUsage, err = service.promoUsageDAO.Create(usage)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.RedeemPromoCode. promoUsageDAO.Create returned error:", err)
+	}
+
+	return newUsage, err
+}
+
+// Canceled reservations do not count against promo code caps.
+func (service *ReservationService) ReleasePromoCode(reservUid string) (err error) {
+	err = service.promoUsageDAO.DeleteByAttr(`reservation_uid`, reservUid)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReleasePromoCode. promoUsageDAO.DeleteByAttr returned error:", err)
+	}
+
+	return err
+}
+
+// Users who never reset their feed have no row and are on version 0.
+func (service *ReservationService) ReadCalendarFeed(username string) (feed models.CalendarFeed, err error) {
+	feedsLst, err := service.feedsDAO.GetByAttribute(`username`, username)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.ReadCalendarFeed. feedsDAO.GetByAttribute returned error:", err)
+		return feed, err
+	}
+
+	if feedsLst.Len() == 0 {
+		return models.CalendarFeed{Username: username}, nil
+	}
+
+	return feedsLst.Front().Value.(models.CalendarFeed), nil
+}
+
+func (service *ReservationService) RotateCalendarFeed(username string) (feed models.CalendarFeed, err error) {
+	feed, err = service.feedsDAO.Rotate(username)
+
+	if err != nil {
+		log.Println("[ERROR] ReservationService.RotateCalendarFeed. feedsDAO.Rotate returned error:", err)
+	}
+
+	return feed, err
+}
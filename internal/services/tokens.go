@@ -0,0 +1,46 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/agarmirus/ds-lab02/internal/serverrors"
+)
+
+func signToken(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + `.` + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func verifyToken(secret string, token string) (payload []byte, err error) {
+	encodedPayload, encodedSignature, found := strings.Cut(token, `.`)
+
+	if !found {
+		return nil, serverrors.ErrInvalidToken
+	}
+
+	payload, err = base64.RawURLEncoding.DecodeString(encodedPayload)
+
+	if err != nil {
+		return nil, serverrors.ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+
+	if err != nil {
+		return nil, serverrors.ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, serverrors.ErrInvalidToken
+	}
+
+	return payload, nil
+}
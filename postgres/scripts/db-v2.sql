@@ -7,19 +7,110 @@ GRANT ALL PRIVILEGES ON DATABASE reservations TO program;
 CREATE DATABASE loyalties;
 GRANT ALL PRIVILEGES ON DATABASE loyalties TO program;
 
+\c reservations
+
+-- Serves the substring search on hotel names
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 \c payments program
 
+-- Amounts are minor units of the payment currency.
 CREATE TABLE payment
 (
-    id          SERIAL PRIMARY KEY,
-    payment_uid uuid        NOT NULL,
-    status      VARCHAR(20) NOT NULL
-        CHECK (status IN ('PAID', 'CANCELED')),
-    price       INT         NOT NULL
+    id              SERIAL PRIMARY KEY,
+    payment_uid     uuid        NOT NULL,
+    status          VARCHAR(20) NOT NULL
+        CHECK (status IN ('AUTHORIZED', 'CAPTURED', 'VOIDED', 'REFUNDED', 'PARTIALLY_REFUNDED')),
+    price           INT         NOT NULL,
+    refunded_amount INT         NOT NULL DEFAULT 0
+        CHECK (refunded_amount >= 0 AND refunded_amount <= price),
+    currency        CHAR(3)     NOT NULL DEFAULT 'RUB'
+        CHECK (currency ~ '^[A-Z]{3}$'),
+    reservation_uid uuid,
+    idempotency_key VARCHAR(255) UNIQUE,
+    metadata        JSONB       NOT NULL DEFAULT '{}',
+    line_items      JSONB       NOT NULL DEFAULT '[]',
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION touch_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER payment_touch_updated_at
+    BEFORE UPDATE ON payment
+    FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+-- An operation repeated with the same idempotency key is applied once.
+-- Operations stay PENDING while the payment provider is called.
+CREATE TABLE payment_transaction
+(
+    id              SERIAL PRIMARY KEY,
+    payment_id      INT         NOT NULL REFERENCES payment (id),
+    kind            VARCHAR(20) NOT NULL
+        CHECK (kind IN ('AUTHORIZE', 'CAPTURE', 'VOID', 'REFUND')),
+    amount          INT         NOT NULL CHECK (amount >= 0),
+    status          VARCHAR(20) NOT NULL DEFAULT 'COMPLETED'
+        CHECK (status IN ('PENDING', 'COMPLETED')),
+    idempotency_key VARCHAR(255) UNIQUE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX payment_transaction_payment_id_idx ON payment_transaction (payment_id);
+CREATE UNIQUE INDEX payment_transaction_pending_idx ON payment_transaction (payment_id) WHERE status = 'PENDING';
+
+CREATE TABLE ledger_entry
+(
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT         NOT NULL REFERENCES payment_transaction (id),
+    payment_id     INT         NOT NULL REFERENCES payment (id),
+    account        VARCHAR(20) NOT NULL
+        CHECK (account IN ('CUSTOMER', 'HOTEL', 'PLATFORM_REVENUE', 'REFUNDS')),
+    debit          INT         NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit         INT         NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK ((debit = 0) <> (credit = 0))
+);
+
+CREATE INDEX ledger_entry_payment_id_idx ON ledger_entry (payment_id);
+CREATE INDEX ledger_entry_transaction_id_idx ON ledger_entry (transaction_id);
+
+CREATE FUNCTION ledger_entry_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'ledger entries are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entry_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entry
+    FOR EACH ROW EXECUTE FUNCTION ledger_entry_append_only();
+
+CREATE TABLE invoice
+(
+    id             SERIAL PRIMARY KEY,
+    payment_id     INT         NOT NULL UNIQUE REFERENCES payment (id),
+    invoice_number INT         NOT NULL UNIQUE,
+    issued_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
 );
 
+-- A single row counter instead of a sequence: the update is rolled back with
+-- the invoice, so invoice numbers have no gaps.
+CREATE TABLE invoice_counter
+(
+    id          BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_number INT     NOT NULL
+);
+
+INSERT INTO invoice_counter (last_number)
+VALUES (0);
+
 \c reservations program
 
+-- Prices are minor units of the hotel currency, e.g. kopecks for RUB.
 CREATE TABLE hotels
 (
     id        SERIAL PRIMARY KEY,
@@ -29,23 +120,158 @@ CREATE TABLE hotels
     city      VARCHAR(80)  NOT NULL,
     address   VARCHAR(255) NOT NULL,
     stars     INT,
-    price     INT          NOT NULL
+    price     INT          NOT NULL,
+    currency  CHAR(3)      NOT NULL DEFAULT 'RUB'
+        CHECK (currency ~ '^[A-Z]{3}$'),
+    retired   BOOLEAN      NOT NULL DEFAULT FALSE
 );
 
+CREATE INDEX hotels_city_idx ON hotels (city);
+CREATE INDEX hotels_country_idx ON hotels (country);
+CREATE INDEX hotels_stars_idx ON hotels (stars);
+CREATE INDEX hotels_price_idx ON hotels (price);
+CREATE INDEX hotels_name_idx ON hotels USING gin (name gin_trgm_ops);
+
 INSERT INTO hotels
-VALUES (1, '049161bb-badd-4fa8-9d90-87c9a82b0668', 'Ararat Park Hyatt Moscow', 'Россия', 'Москва', 'Неглинная ул., 4', 5, 10000);
+VALUES (1, '049161bb-badd-4fa8-9d90-87c9a82b0668', 'Ararat Park Hyatt Moscow', 'Россия', 'Москва', 'Неглинная ул., 4', 5, 1000000);
+
+SELECT setval('hotels_id_seq', (SELECT max(id) FROM hotels));
+
+CREATE TABLE room_type
+(
+    id            SERIAL PRIMARY KEY,
+    room_type_uid uuid         NOT NULL UNIQUE,
+    hotel_id      INT          NOT NULL REFERENCES hotels (id),
+    name          VARCHAR(80)  NOT NULL,
+    rooms_count   INT          NOT NULL CHECK (rooms_count >= 0),
+    UNIQUE (hotel_id, name)
+);
+
+INSERT INTO room_type (room_type_uid, hotel_id, name, rooms_count)
+VALUES ('5a3e0f1c-7d2b-4f6e-9c1a-3b8d2e4f6a70', 1, 'Standard', 20);
+
+CREATE TABLE cancellation_policy
+(
+    id                     SERIAL PRIMARY KEY,
+    hotel_id               INT     NOT NULL UNIQUE REFERENCES hotels (id),
+    free_cancellation_days INT     NOT NULL DEFAULT 0 CHECK (free_cancellation_days >= 0),
+    penalty_percent        INT     NOT NULL DEFAULT 0 CHECK (penalty_percent BETWEEN 0 AND 100),
+    non_refundable         BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE rate_rule
+(
+    id         SERIAL PRIMARY KEY,
+    hotel_id   INT         NOT NULL REFERENCES hotels (id),
+    kind       VARCHAR(20) NOT NULL
+        CHECK (kind IN ('SEASON', 'WEEKDAY', 'MIN_STAY', 'LONG_STAY')),
+    valid_from DATE,
+    valid_to   DATE,
+    weekdays   INT[]       NOT NULL DEFAULT '{}',
+    percent    INT         NOT NULL DEFAULT 0 CHECK (percent >= -100),
+    min_nights INT         NOT NULL DEFAULT 0 CHECK (min_nights >= 0),
+    CHECK (valid_from IS NULL OR valid_to IS NULL OR valid_from <= valid_to)
+);
+
+CREATE INDEX rate_rule_hotel_id_idx ON rate_rule (hotel_id);
+
+CREATE TABLE tax_rule
+(
+    id      SERIAL PRIMARY KEY,
+    kind    VARCHAR(20) NOT NULL
+        CHECK (kind IN ('VAT', 'TOURIST_TAX', 'SERVICE_FEE')),
+    name    VARCHAR(80) NOT NULL,
+    country VARCHAR(80) NOT NULL,
+    city    VARCHAR(80),
+    percent INT         NOT NULL DEFAULT 0 CHECK (percent BETWEEN 0 AND 100),
+    amount  INT         NOT NULL DEFAULT 0 CHECK (amount >= 0)
+);
+
+CREATE INDEX tax_rule_country_idx ON tax_rule (country);
+
+CREATE TABLE exchange_rate
+(
+    id            SERIAL PRIMARY KEY,
+    from_currency CHAR(3)        NOT NULL CHECK (from_currency ~ '^[A-Z]{3}$'),
+    to_currency   CHAR(3)        NOT NULL CHECK (to_currency ~ '^[A-Z]{3}$'),
+    rate          NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    updated_at    TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    UNIQUE (from_currency, to_currency),
+    CHECK (from_currency <> to_currency)
+);
 
 CREATE TABLE reservation
+(
+    id               SERIAL PRIMARY KEY,
+    reservation_uid  uuid UNIQUE NOT NULL,
+    username         VARCHAR(80) NOT NULL,
+    payment_uid      uuid        NOT NULL,
+    hotel_id         INT REFERENCES hotels (id),
+    status           VARCHAR(20) NOT NULL
+        CHECK (status IN ('PENDING', 'PAID', 'CHECKED_IN', 'COMPLETED', 'CANCELED', 'NO_SHOW')),
+    start_date       TIMESTAMP WITH TIME ZONE,
+    end_date         TIMESTAMP WITH TIME ZONE,
+    nightly_rate     INT         NOT NULL DEFAULT 0,
+    nights           INT         NOT NULL DEFAULT 0,
+    discount_percent INT         NOT NULL DEFAULT 0,
+    discount_source  VARCHAR(40) NOT NULL DEFAULT 'NONE',
+    total_price      INT         NOT NULL DEFAULT 0,
+    tax_amount       INT         NOT NULL DEFAULT 0,
+    guests           INT         NOT NULL DEFAULT 1 CHECK (guests > 0),
+    room_type_id     INT REFERENCES room_type (id),
+    promo_code       VARCHAR(40) NOT NULL DEFAULT '',
+    hold_expires_at  TIMESTAMP WITH TIME ZONE,
+    updated_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION touch_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reservation_touch_updated_at
+    BEFORE UPDATE ON reservation
+    FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+CREATE INDEX reservation_room_type_dates_idx ON reservation (room_type_id, start_date, end_date);
+CREATE INDEX reservation_pending_holds_idx ON reservation (hold_expires_at) WHERE status = 'PENDING';
+
+CREATE TABLE promo_code
+(
+    id                SERIAL PRIMARY KEY,
+    code              VARCHAR(40) NOT NULL UNIQUE,
+    kind              VARCHAR(20) NOT NULL
+        CHECK (kind IN ('PERCENT', 'FIXED')),
+    value             INT         NOT NULL CHECK (value > 0),
+    valid_from        DATE        NOT NULL,
+    valid_to          DATE        NOT NULL,
+    max_uses          INT         NOT NULL DEFAULT 0,
+    max_uses_per_user INT         NOT NULL DEFAULT 0,
+    used_count        INT         NOT NULL DEFAULT 0,
+    hotel_id          INT REFERENCES hotels (id),
+    city              VARCHAR(80) NOT NULL DEFAULT '',
+    stackable         BOOLEAN     NOT NULL DEFAULT FALSE,
+    CHECK (valid_from <= valid_to)
+);
+
+CREATE TABLE promo_code_usage
 (
     id              SERIAL PRIMARY KEY,
-    reservation_uid uuid UNIQUE NOT NULL,
+    promo_code_id   INT         NOT NULL REFERENCES promo_code (id),
     username        VARCHAR(80) NOT NULL,
-    payment_uid     uuid        NOT NULL,
-    hotel_id        INT REFERENCES hotels (id),
-    status          VARCHAR(20) NOT NULL
-        CHECK (status IN ('PAID', 'CANCELED')),
-    start_date      TIMESTAMP WITH TIME ZONE,
-    end_date        TIMESTAMP WITH TIME ZONE
+    reservation_uid uuid        NOT NULL REFERENCES reservation (reservation_uid),
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX promo_code_usage_username_idx ON promo_code_usage (username);
+
+CREATE TABLE calendar_feed
+(
+    id       SERIAL PRIMARY KEY,
+    username VARCHAR(80) NOT NULL UNIQUE,
+    version  INT         NOT NULL DEFAULT 0
 );
 
 \c loyalties program
@@ -62,3 +288,18 @@ CREATE TABLE loyalty
 
 INSERT INTO loyalty
 VALUES (1, 'Test Max', 25, 'GOLD', 10);
+
+-- Events recorded before reservations were linked have no reservation uid.
+CREATE TABLE loyalty_event
+(
+    id              SERIAL PRIMARY KEY,
+    username        VARCHAR(80)              NOT NULL REFERENCES loyalty (username),
+    reservation_uid uuid UNIQUE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX loyalty_event_username_created_at_idx ON loyalty_event (username, created_at);
+
+INSERT INTO loyalty_event (username)
+SELECT 'Test Max'
+FROM generate_series(1, 25);